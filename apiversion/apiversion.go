@@ -0,0 +1,141 @@
+package apiversion
+
+import (
+	"mime"
+	"net/http"
+	"public-api/content"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Version identifies a major version of the public API
+type Version int
+
+// Supported API versions
+const (
+	V1 Version = 1
+	V2 Version = 2
+
+	// Latest is served when a request does not ask for a version
+	Latest = V2
+)
+
+// Supported lists every version the API can serve
+var Supported = []Version{V1, V2}
+
+// MediaTypePrefix is the vendor media type prefix used to request a version
+// through the Accept header, e.g. application/vnd.public-api.v2+json
+const MediaTypePrefix = "application/vnd.public-api."
+
+const contextKey = "api_version"
+
+// String returns the path segment for v, e.g. "v1"
+func (v Version) String() string {
+	return "v" + strconv.Itoa(int(v))
+}
+
+// IsSupported reports whether v is served by this API
+func (v Version) IsSupported() bool {
+	for _, s := range Supported {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// FromContext returns the version negotiated for the request, defaulting to V1
+// for routes registered outside any versioned group
+func FromContext(c *gin.Context) Version {
+	if v, ok := c.Get(contextKey); ok {
+		if version, ok := v.(Version); ok {
+			return version
+		}
+	}
+	return V1
+}
+
+// Fixed pins every request in a route group to v, for path-versioned groups
+func Fixed(v Version) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(contextKey, v)
+		c.Next()
+	}
+}
+
+// Negotiate picks the version from the Accept header, falling back to Latest.
+// Requests for an unknown version are rejected with 406.
+func Negotiate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		content.AddVary(c.Writer.Header(), "Accept")
+
+		v, ok := FromAccept(c.GetHeader("Accept"))
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusNotAcceptable, gin.H{"error": "Unsupported API version"})
+			return
+		}
+		c.Set(contextKey, v)
+		c.Next()
+	}
+}
+
+// FromAccept extracts the requested version from an Accept header. It
+// understands vendor media types with any format suffix
+// (application/vnd.public-api.v2+json, +msgpack) and a version parameter
+// (application/json; version=2). ok is false only when a version was
+// requested that is not supported.
+func FromAccept(accept string) (v Version, ok bool) {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		var raw string
+		if strings.HasPrefix(mediaType, MediaTypePrefix) {
+			version, _, _ := strings.Cut(strings.TrimPrefix(mediaType, MediaTypePrefix), "+")
+			raw = strings.TrimPrefix(version, "v")
+		} else if p, found := params["version"]; found {
+			raw = strings.TrimPrefix(p, "v")
+		} else {
+			continue
+		}
+
+		n, err := strconv.Atoi(raw)
+		if err != nil || !Version(n).IsSupported() {
+			return 0, false
+		}
+		return Version(n), true
+	}
+	return Latest, true
+}
+
+// Deprecate marks responses served as version v with Deprecation (RFC 9745)
+// and Sunset (RFC 8594) headers, linking to the successor version's docs.
+// Each header is only sent when its time is set.
+func Deprecate(v Version, deprecatedAt, sunsetAt time.Time, successor string) gin.HandlerFunc {
+	var deprecation, sunset string
+	if !deprecatedAt.IsZero() {
+		deprecation = "@" + strconv.FormatInt(deprecatedAt.Unix(), 10)
+	}
+	if !sunsetAt.IsZero() {
+		sunset = sunsetAt.UTC().Format(http.TimeFormat)
+	}
+	link := `<` + successor + `>; rel="successor-version"`
+
+	return func(c *gin.Context) {
+		if FromContext(c) == v {
+			if deprecation != "" {
+				c.Header("Deprecation", deprecation)
+			}
+			if sunset != "" {
+				c.Header("Sunset", sunset)
+			}
+			c.Header("Link", link)
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,120 @@
+package apiversion_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"public-api/apiversion"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromAccept(t *testing.T) {
+	tests := []struct {
+		name   string
+		accept string
+		want   apiversion.Version
+		wantOK bool
+	}{
+		{name: "no header", accept: "", want: apiversion.Latest, wantOK: true},
+		{name: "plain json", accept: "application/json", want: apiversion.Latest, wantOK: true},
+		{name: "vendor v1", accept: "application/vnd.public-api.v1+json", want: apiversion.V1, wantOK: true},
+		{name: "vendor v2 among others", accept: "text/html, application/vnd.public-api.v2+json;q=0.9", want: apiversion.V2, wantOK: true},
+		{name: "vendor v1 msgpack", accept: "application/vnd.public-api.v1+msgpack", want: apiversion.V1, wantOK: true},
+		{name: "version parameter", accept: "application/json; version=1", want: apiversion.V1, wantOK: true},
+		{name: "unsupported version", accept: "application/vnd.public-api.v9+json", wantOK: false},
+		{name: "garbage version", accept: "application/json; version=abc", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := apiversion.FromAccept(tt.accept)
+			assert.Equal(t, tt.wantOK, ok)
+			if tt.wantOK {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNegotiateAndDeprecate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	deprecatedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	sunsetAt := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	r := gin.New()
+	r.GET("/thing",
+		apiversion.Negotiate(),
+		apiversion.Deprecate(apiversion.V1, deprecatedAt, sunsetAt, "/api/v2"),
+		func(c *gin.Context) {
+			c.String(http.StatusOK, apiversion.FromContext(c).String())
+		})
+
+	tests := []struct {
+		name        string
+		accept      string
+		wantCode    int
+		wantBody    string
+		wantHeaders bool
+	}{
+		{name: "default latest", wantCode: http.StatusOK, wantBody: "v2"},
+		{name: "v1 is deprecated", accept: "application/vnd.public-api.v1+json", wantCode: http.StatusOK, wantBody: "v1", wantHeaders: true},
+		{name: "unsupported", accept: "application/vnd.public-api.v3+json", wantCode: http.StatusNotAcceptable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/thing", nil)
+			req.Header.Set("Accept", tt.accept)
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.wantCode, resp.Code)
+			assert.Equal(t, "Accept", resp.Header().Get("Vary"))
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, resp.Body.String())
+			}
+			if tt.wantHeaders {
+				assert.Equal(t, "@1767225600", resp.Header().Get("Deprecation"))
+				assert.Equal(t, "Wed, 01 Jul 2026 00:00:00 GMT", resp.Header().Get("Sunset"))
+				assert.Equal(t, `</api/v2>; rel="successor-version"`, resp.Header().Get("Link"))
+			} else {
+				assert.Empty(t, resp.Header().Get("Deprecation"))
+			}
+		})
+	}
+}
+
+func TestDeprecateUnsetTimes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	sunsetAt := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	r := gin.New()
+	r.GET("/thing", apiversion.Fixed(apiversion.V1), apiversion.Deprecate(apiversion.V1, time.Time{}, sunsetAt, "/api/v2"))
+
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/thing", nil))
+
+	assert.Empty(t, resp.Header().Values("Deprecation"))
+	assert.Equal(t, "Wed, 01 Jul 2026 00:00:00 GMT", resp.Header().Get("Sunset"))
+	assert.Equal(t, `</api/v2>; rel="successor-version"`, resp.Header().Get("Link"))
+}
+
+func TestFixed(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/v2/thing", apiversion.Fixed(apiversion.V2), func(c *gin.Context) {
+		c.String(http.StatusOK, apiversion.FromContext(c).String())
+	})
+	r.GET("/thing", func(c *gin.Context) {
+		c.String(http.StatusOK, apiversion.FromContext(c).String())
+	})
+
+	for path, want := range map[string]string{"/v2/thing": "v2", "/thing": "v1"} {
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, want, resp.Body.String())
+	}
+}
@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenAuthenticator(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	a := NewTokenAuthenticator("secret")
+	a.now = func() time.Time { return now }
+
+	valid := a.Issue(42, time.Hour)
+	other := NewTokenAuthenticator("other")
+	other.now = a.now
+
+	tests := []struct {
+		name    string
+		header  string
+		query   string
+		wantID  int64
+		wantErr bool
+	}{
+		{name: "bearer header", header: "Bearer " + valid, wantID: 42},
+		{name: "lowercase scheme", header: "bearer " + valid, wantID: 42},
+		{name: "query parameter", query: "?access_token=" + valid, wantID: 42},
+		{name: "header wins over query", header: "Bearer garbage", query: "?access_token=" + valid, wantErr: true},
+		{name: "missing", wantErr: true},
+		{name: "basic scheme", header: "Basic " + valid, wantErr: true},
+		{name: "signed with another secret", header: "Bearer " + other.Issue(42, time.Hour), wantErr: true},
+		{name: "expired", header: "Bearer " + a.Issue(42, -time.Second), wantErr: true},
+		{name: "tampered user", header: "Bearer 43" + valid[2:], wantErr: true},
+		{name: "no signature", header: "Bearer 42", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/ws"+tt.query, nil)
+			if tt.header != "" {
+				r.Header.Set("Authorization", tt.header)
+			}
+
+			id, err := a.Authenticate(r)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrUnauthenticated)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantID, id)
+		})
+	}
+}
+
+func TestTokenAuthenticatorWithoutSecret(t *testing.T) {
+	a := NewTokenAuthenticator("")
+	r := httptest.NewRequest(http.MethodGet, "/ws?access_token="+a.Issue(1, time.Hour), nil)
+
+	_, err := a.Authenticate(r)
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+}
@@ -0,0 +1,35 @@
+package client
+
+import (
+	"fmt"
+	"public-api/model"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// defaultGRPCTimeout bounds each call, since the client interfaces carry no
+// context of their own
+const defaultGRPCTimeout = 10 * time.Second
+
+// DialGRPC opens a plaintext connection to a downstream service. Connections
+// are established lazily on the first call.
+func DialGRPC(addr string) (*grpc.ClientConn, error) {
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial %s: %w", addr, err)
+	}
+	return conn, nil
+}
+
+// grpcError maps a NotFound status for the given resource onto
+// model.ErrNotFound and wraps any other error with msg
+func grpcError(resource string, id int64, msg string, err error) error {
+	if status.Code(err) == codes.NotFound {
+		return fmt.Errorf("%s %d: %w", resource, id, model.ErrNotFound)
+	}
+	return fmt.Errorf("%s: %w", msg, err)
+}
@@ -0,0 +1,100 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"public-api/model"
+	"public-api/pb/listingservice"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// grpcListingClient talks to the Listing Service over gRPC
+type grpcListingClient struct {
+	client  listingservice.ListingServiceClient
+	timeout time.Duration
+}
+
+// NewGRPCListingClient creates a ListingClient that calls the listing-service
+// over conn, with the same error semantics as the HTTP client
+func NewGRPCListingClient(conn grpc.ClientConnInterface) ListingClient {
+	return &grpcListingClient{
+		client:  listingservice.NewListingServiceClient(conn),
+		timeout: defaultGRPCTimeout,
+	}
+}
+
+// FetchListings fetches a page of listings, forwarding every filter and sort
+// option in q
+func (lc *grpcListingClient) FetchListings(q model.ListingQuery) ([]model.Listing, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), lc.timeout)
+	defer cancel()
+
+	resp, err := lc.client.ListListings(ctx, &listingservice.ListListingsRequest{
+		PageNum:     int32(q.Page),
+		PageSize:    int32(q.Size),
+		UserId:      q.UserID,
+		ListingType: q.ListingType,
+		MinPrice:    q.MinPrice,
+		MaxPrice:    q.MaxPrice,
+		CreatedFrom: q.CreatedFrom,
+		CreatedTo:   q.CreatedTo,
+		SortBy:      q.SortBy,
+		SortOrder:   q.SortOrder,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to call listing service: %w", err)
+	}
+
+	listings := make([]model.Listing, 0, len(resp.GetListings()))
+	for _, l := range resp.GetListings() {
+		listings = append(listings, *fromPBListing(l))
+	}
+	return listings, nil
+}
+
+// FetchListingByID gets a single listing by ID from the Listing Service
+func (lc *grpcListingClient) FetchListingByID(id int64) (*model.Listing, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), lc.timeout)
+	defer cancel()
+
+	resp, err := lc.client.GetListing(ctx, &listingservice.GetListingRequest{Id: id})
+	if err != nil {
+		return nil, grpcError("listing", id, "failed to call listing service", err)
+	}
+	if resp.GetListing() == nil {
+		return nil, fmt.Errorf("listing %d: %w", id, model.ErrNotFound)
+	}
+	return fromPBListing(resp.GetListing()), nil
+}
+
+// CreateListing creates a listing
+func (lc *grpcListingClient) CreateListing(l model.Listing) (*model.Listing, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), lc.timeout)
+	defer cancel()
+
+	resp, err := lc.client.CreateListing(ctx, &listingservice.CreateListingRequest{
+		UserId:      l.UserID,
+		ListingType: l.ListingType,
+		Price:       l.Price,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating listing: %w", err)
+	}
+	if resp.GetListing() == nil {
+		return nil, nil
+	}
+	return fromPBListing(resp.GetListing()), nil
+}
+
+func fromPBListing(l *listingservice.Listing) *model.Listing {
+	return &model.Listing{
+		ID:          l.GetId(),
+		UserID:      l.GetUserId(),
+		ListingType: l.GetListingType(),
+		Price:       l.GetPrice(),
+		CreatedAt:   l.GetCreatedAt(),
+		UpdatedAt:   l.GetUpdatedAt(),
+	}
+}
@@ -0,0 +1,105 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"public-api/client"
+	"public-api/model"
+	"public-api/pb/listingservice"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// fakeListingService is an in-memory listing-service that records the last
+// list request
+type fakeListingService struct {
+	listingservice.UnimplementedListingServiceServer
+	listings    []*listingservice.Listing
+	lastRequest *listingservice.ListListingsRequest
+}
+
+func (f *fakeListingService) ListListings(_ context.Context, req *listingservice.ListListingsRequest) (*listingservice.ListListingsResponse, error) {
+	f.lastRequest = req
+	return &listingservice.ListListingsResponse{Listings: f.listings}, nil
+}
+
+func (f *fakeListingService) GetListing(_ context.Context, req *listingservice.GetListingRequest) (*listingservice.GetListingResponse, error) {
+	for _, l := range f.listings {
+		if l.GetId() == req.GetId() {
+			return &listingservice.GetListingResponse{Listing: l}, nil
+		}
+	}
+	return nil, status.Error(codes.NotFound, "no such listing")
+}
+
+func (f *fakeListingService) CreateListing(_ context.Context, req *listingservice.CreateListingRequest) (*listingservice.CreateListingResponse, error) {
+	if req.GetPrice() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "price must be positive")
+	}
+	l := &listingservice.Listing{
+		Id:          int64(len(f.listings) + 1),
+		UserId:      req.GetUserId(),
+		ListingType: req.GetListingType(),
+		Price:       req.GetPrice(),
+	}
+	f.listings = append(f.listings, l)
+	return &listingservice.CreateListingResponse{Listing: l}, nil
+}
+
+func newGRPCListingClient(t *testing.T) (client.ListingClient, *fakeListingService) {
+	fake := &fakeListingService{listings: []*listingservice.Listing{
+		{Id: 1, UserId: 1, ListingType: "rent", Price: 1000, CreatedAt: 5, UpdatedAt: 6},
+		{Id: 2, UserId: 2, ListingType: "sale", Price: 250000.75},
+	}}
+	conn := serveGRPC(t, func(s *grpc.Server) { listingservice.RegisterListingServiceServer(s, fake) })
+	return client.NewGRPCListingClient(conn), fake
+}
+
+func TestGRPCListingClient_FetchListings(t *testing.T) {
+	lc, fake := newGRPCListingClient(t)
+
+	userID := int64(2)
+	minPrice := 10.5
+	listings, err := lc.FetchListings(model.ListingQuery{
+		Page: 2, Size: 5, UserID: &userID, ListingType: "sale", MinPrice: &minPrice,
+		SortBy: model.ListingSortPrice, SortOrder: model.SortDesc,
+	})
+	require.NoError(t, err)
+	assert.Len(t, listings, 2)
+	assert.Equal(t, model.Listing{ID: 1, UserID: 1, ListingType: "rent", Price: 1000, CreatedAt: 5, UpdatedAt: 6}, listings[0])
+
+	// Every option is forwarded and unset ones stay unset
+	assert.True(t, proto.Equal(&listingservice.ListListingsRequest{
+		PageNum: 2, PageSize: 5, UserId: proto.Int64(2), ListingType: "sale", MinPrice: proto.Float64(10.5),
+		SortBy: "price", SortOrder: "desc",
+	}, fake.lastRequest))
+}
+
+func TestGRPCListingClient_FetchListingByID(t *testing.T) {
+	lc, _ := newGRPCListingClient(t)
+
+	listing, err := lc.FetchListingByID(2)
+	require.NoError(t, err)
+	assert.Equal(t, 250000.75, listing.Price)
+
+	_, err = lc.FetchListingByID(99)
+	assert.True(t, errors.Is(err, model.ErrNotFound))
+}
+
+func TestGRPCListingClient_CreateListing(t *testing.T) {
+	lc, _ := newGRPCListingClient(t)
+
+	created, err := lc.CreateListing(model.Listing{UserID: 3, ListingType: "rent", Price: 1234.56})
+	require.NoError(t, err)
+	assert.Equal(t, &model.Listing{ID: 3, UserID: 3, ListingType: "rent", Price: 1234.56}, created)
+
+	_, err = lc.CreateListing(model.Listing{UserID: 3, ListingType: "rent"})
+	assert.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(errors.Unwrap(err)))
+}
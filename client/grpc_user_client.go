@@ -0,0 +1,92 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"public-api/model"
+	"public-api/pb/userservice"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// grpcUserClient talks to the User Service over gRPC
+type grpcUserClient struct {
+	client  userservice.UserServiceClient
+	timeout time.Duration
+}
+
+// NewGRPCUserClient creates a UserClient that calls the user-service over
+// conn. It reports errors exactly like the HTTP client: NotFound becomes
+// model.ErrNotFound and every other failure is wrapped as is.
+func NewGRPCUserClient(conn grpc.ClientConnInterface) UserClient {
+	return &grpcUserClient{
+		client:  userservice.NewUserServiceClient(conn),
+		timeout: defaultGRPCTimeout,
+	}
+}
+
+// FetchUserByID gets a user by ID from the User Service
+func (uc *grpcUserClient) FetchUserByID(id int64) (*model.User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), uc.timeout)
+	defer cancel()
+
+	resp, err := uc.client.GetUser(ctx, &userservice.GetUserRequest{Id: id})
+	if err != nil {
+		return nil, grpcError("user", id, "failed to call user service", err)
+	}
+	return fromPBUser(resp.GetUser()), nil
+}
+
+// CreateUser creates a user
+func (uc *grpcUserClient) CreateUser(name string) (*model.User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), uc.timeout)
+	defer cancel()
+
+	resp, err := uc.client.CreateUser(ctx, &userservice.CreateUserRequest{Name: name})
+	if err != nil {
+		return nil, fmt.Errorf("error creating user: %w", err)
+	}
+	return fromPBUser(resp.GetUser()), nil
+}
+
+// UpdateUser renames a user
+func (uc *grpcUserClient) UpdateUser(id int64, name string) (*model.User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), uc.timeout)
+	defer cancel()
+
+	resp, err := uc.client.UpdateUser(ctx, &userservice.UpdateUserRequest{Id: id, Name: name})
+	if err != nil {
+		return nil, grpcError("user", id, "error updating user", err)
+	}
+	return fromPBUser(resp.GetUser()), nil
+}
+
+// FetchUsersByIDs fetches several users in one call, keyed by ID
+func (uc *grpcUserClient) FetchUsersByIDs(ids []int64) (map[int64]*model.User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), uc.timeout)
+	defer cancel()
+
+	resp, err := uc.client.BatchGetUsers(ctx, &userservice.BatchGetUsersRequest{Ids: ids})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch users: %w", err)
+	}
+
+	userMap := make(map[int64]*model.User, len(resp.GetUsers()))
+	for _, u := range resp.GetUsers() {
+		userMap[u.GetId()] = fromPBUser(u)
+	}
+	return userMap, nil
+}
+
+func fromPBUser(u *userservice.User) *model.User {
+	if u == nil {
+		return nil
+	}
+	return &model.User{
+		ID:        u.GetId(),
+		Name:      u.GetName(),
+		CreatedAt: u.GetCreatedAt(),
+		UpdatedAt: u.GetUpdatedAt(),
+	}
+}
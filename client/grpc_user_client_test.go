@@ -0,0 +1,157 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"public-api/client"
+	"public-api/model"
+	"public-api/pb/userservice"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// serveGRPC runs register's services on an in-process listener and returns
+// a connection to it
+func serveGRPC(t *testing.T, register func(s *grpc.Server)) *grpc.ClientConn {
+	lis := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer()
+	register(srv)
+	go func() {
+		_ = srv.Serve(lis)
+	}()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// fakeUserService is an in-memory user-service. Names starting with "!"
+// make CreateUser and UpdateUser fail.
+type fakeUserService struct {
+	userservice.UnimplementedUserServiceServer
+	users map[int64]*userservice.User
+}
+
+func (f *fakeUserService) GetUser(_ context.Context, req *userservice.GetUserRequest) (*userservice.GetUserResponse, error) {
+	u, ok := f.users[req.GetId()]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "no such user")
+	}
+	return &userservice.GetUserResponse{User: u}, nil
+}
+
+func (f *fakeUserService) BatchGetUsers(_ context.Context, req *userservice.BatchGetUsersRequest) (*userservice.BatchGetUsersResponse, error) {
+	resp := &userservice.BatchGetUsersResponse{}
+	for _, id := range req.GetIds() {
+		if u, ok := f.users[id]; ok {
+			resp.Users = append(resp.Users, u)
+		}
+	}
+	return resp, nil
+}
+
+func (f *fakeUserService) CreateUser(_ context.Context, req *userservice.CreateUserRequest) (*userservice.CreateUserResponse, error) {
+	if req.GetName()[0] == '!' {
+		return nil, status.Error(codes.Unavailable, "down")
+	}
+	u := &userservice.User{Id: int64(len(f.users) + 1), Name: req.GetName()}
+	f.users[u.Id] = u
+	return &userservice.CreateUserResponse{User: u}, nil
+}
+
+func (f *fakeUserService) UpdateUser(_ context.Context, req *userservice.UpdateUserRequest) (*userservice.UpdateUserResponse, error) {
+	u, ok := f.users[req.GetId()]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "no such user")
+	}
+	if req.GetName()[0] == '!' {
+		return nil, status.Error(codes.Internal, "boom")
+	}
+	u.Name = req.GetName()
+	return &userservice.UpdateUserResponse{User: u}, nil
+}
+
+func newGRPCUserClient(t *testing.T) client.UserClient {
+	fake := &fakeUserService{users: map[int64]*userservice.User{
+		1: {Id: 1, Name: "John", CreatedAt: 10, UpdatedAt: 20},
+		2: {Id: 2, Name: "Jane"},
+	}}
+	conn := serveGRPC(t, func(s *grpc.Server) { userservice.RegisterUserServiceServer(s, fake) })
+	return client.NewGRPCUserClient(conn)
+}
+
+func TestGRPCUserClient_FetchUserByID(t *testing.T) {
+	uc := newGRPCUserClient(t)
+
+	user, err := uc.FetchUserByID(1)
+	require.NoError(t, err)
+	assert.Equal(t, &model.User{ID: 1, Name: "John", CreatedAt: 10, UpdatedAt: 20}, user)
+
+	_, err = uc.FetchUserByID(99)
+	assert.True(t, errors.Is(err, model.ErrNotFound))
+}
+
+func TestGRPCUserClient_FetchUsersByIDs(t *testing.T) {
+	uc := newGRPCUserClient(t)
+
+	users, err := uc.FetchUsersByIDs([]int64{1, 2, 99})
+	require.NoError(t, err)
+	assert.Len(t, users, 2)
+	assert.Equal(t, "Jane", users[2].Name)
+	assert.NotContains(t, users, int64(99))
+}
+
+func TestGRPCUserClient_CreateUser(t *testing.T) {
+	uc := newGRPCUserClient(t)
+
+	user, err := uc.CreateUser("Bob")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), user.ID)
+
+	_, err = uc.CreateUser("!Bob")
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, model.ErrNotFound))
+	assert.Equal(t, codes.Unavailable, status.Code(errors.Unwrap(err)))
+}
+
+func TestGRPCUserClient_UpdateUser(t *testing.T) {
+	tests := []struct {
+		name        string
+		id          int64
+		newName     string
+		wantErr     bool
+		wantNotFind bool
+	}{
+		{name: "success", id: 1, newName: "Johnny"},
+		{name: "not found", id: 99, newName: "Johnny", wantErr: true, wantNotFind: true},
+		{name: "downstream failure", id: 1, newName: "!Johnny", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := newGRPCUserClient(t)
+
+			user, err := uc.UpdateUser(tt.id, tt.newName)
+
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.wantNotFind, errors.Is(err, model.ErrNotFound))
+			if !tt.wantErr {
+				assert.Equal(t, tt.newName, user.Name)
+			}
+		})
+	}
+}
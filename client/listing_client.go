@@ -12,7 +12,8 @@ import (
 
 //go:generate mockgen -destination=../mocks/mock_listing_client.go -package=mocks public-api/client ListingClient
 type ListingClient interface {
-	FetchListings(page, size int, userID *int64) ([]model.Listing, error)
+	FetchListings(q model.ListingQuery) ([]model.Listing, error)
+	FetchListingByID(id int64) (*model.Listing, error)
 	CreateListing(l model.Listing) (*model.Listing, error)
 }
 
@@ -30,16 +31,10 @@ func NewListingClient(baseURL string) ListingClient {
 	}
 }
 
-// FetchListings fetches listings, optionally filtered by user_id
-func (lc *listingClientImpl) FetchListings(page, size int, userID *int64) ([]model.Listing, error) {
-	q := url.Values{}
-	q.Set("page_num", strconv.Itoa(page))
-	q.Set("page_size", strconv.Itoa(size))
-	if userID != nil {
-		q.Set("user_id", strconv.FormatInt(*userID, 10))
-	}
-
-	url := fmt.Sprintf("%s/listings?%s", lc.baseURL, q.Encode())
+// FetchListings fetches a page of listings, forwarding every filter and sort
+// option in q
+func (lc *listingClientImpl) FetchListings(q model.ListingQuery) ([]model.Listing, error) {
+	url := fmt.Sprintf("%s/listings?%s", lc.baseURL, encodeListingQuery(q).Encode())
 	resp, err := lc.client.Get(url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to call listing service: %w", err)
@@ -62,12 +57,43 @@ func (lc *listingClientImpl) FetchListings(page, size int, userID *int64) ([]mod
 	return result.Listings, nil
 }
 
+// FetchListingByID gets a single listing by ID from the Listing Service
+func (lc *listingClientImpl) FetchListingByID(id int64) (*model.Listing, error) {
+	url := fmt.Sprintf("%s/listings/%d", lc.baseURL, id)
+	resp, err := lc.client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call listing service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("listing %d: %w", id, model.ErrNotFound)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("listing service returned status: %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Result  bool           `json:"result"`
+		Listing *model.Listing `json:"listing"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode listing failed: %w", err)
+	}
+	if result.Listing == nil {
+		return nil, fmt.Errorf("listing %d: %w", id, model.ErrNotFound)
+	}
+
+	return result.Listing, nil
+}
+
 // CreateListing creates a listing
 func (lc *listingClientImpl) CreateListing(l model.Listing) (*model.Listing, error) {
 	form := url.Values{}
 	form.Set("user_id", strconv.FormatInt(l.UserID, 10))
 	form.Set("listing_type", l.ListingType)
-	form.Set("price", strconv.FormatInt(int64(l.Price), 10))
+	form.Set("price", model.FormatPrice(l.Price))
 
 	url := lc.baseURL + "/listings"
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewBufferString(form.Encode()))
@@ -98,3 +124,35 @@ func (lc *listingClientImpl) CreateListing(l model.Listing) (*model.Listing, err
 
 	return result.Listing, nil
 }
+
+// encodeListingQuery converts q into listing-service query parameters
+func encodeListingQuery(q model.ListingQuery) url.Values {
+	v := url.Values{}
+	v.Set("page_num", strconv.Itoa(q.Page))
+	v.Set("page_size", strconv.Itoa(q.Size))
+	if q.UserID != nil {
+		v.Set("user_id", strconv.FormatInt(*q.UserID, 10))
+	}
+	if q.ListingType != "" {
+		v.Set("listing_type", q.ListingType)
+	}
+	if q.MinPrice != nil {
+		v.Set("min_price", strconv.FormatFloat(*q.MinPrice, 'f', -1, 64))
+	}
+	if q.MaxPrice != nil {
+		v.Set("max_price", strconv.FormatFloat(*q.MaxPrice, 'f', -1, 64))
+	}
+	if q.CreatedFrom != nil {
+		v.Set("created_from", strconv.FormatInt(*q.CreatedFrom, 10))
+	}
+	if q.CreatedTo != nil {
+		v.Set("created_to", strconv.FormatInt(*q.CreatedTo, 10))
+	}
+	if q.SortBy != "" {
+		v.Set("sort_by", q.SortBy)
+	}
+	if q.SortOrder != "" {
+		v.Set("sort_order", q.SortOrder)
+	}
+	return v
+}
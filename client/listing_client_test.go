@@ -1,6 +1,7 @@
 package client_test
 
 import (
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -55,7 +56,7 @@ func TestFetchListings(t *testing.T) {
 			defer srv.Close()
 
 			c := client.NewListingClient(srv.URL)
-			_, err := c.FetchListings(1, 10, tt.userID)
+			_, err := c.FetchListings(model.ListingQuery{Page: 1, Size: 10, UserID: tt.userID})
 
 			if tt.expectErr && err == nil {
 				t.Errorf("expected error, got nil")
@@ -128,6 +129,132 @@ func TestCreateListing(t *testing.T) {
 	}
 }
 
+func TestFetchListingsForwardsQuery(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		want := map[string]string{
+			"page_num":     "2",
+			"page_size":    "5",
+			"user_id":      "3",
+			"listing_type": "rent",
+			"min_price":    "100.5",
+			"max_price":    "2000",
+			"created_from": "10",
+			"created_to":   "20",
+			"sort_by":      "price",
+			"sort_order":   "desc",
+		}
+		for k, v := range want {
+			if got := q.Get(k); got != v {
+				t.Errorf("query %s = %q, want %q", k, got, v)
+			}
+		}
+		io.WriteString(w, `{"result": true, "listings": []}`)
+	}))
+	defer srv.Close()
+
+	minPrice, maxPrice := 100.5, 2000.0
+	c := client.NewListingClient(srv.URL)
+	_, err := c.FetchListings(model.ListingQuery{
+		Page:        2,
+		Size:        5,
+		UserID:      ptrInt64(3),
+		ListingType: "rent",
+		MinPrice:    &minPrice,
+		MaxPrice:    &maxPrice,
+		CreatedFrom: ptrInt64(10),
+		CreatedTo:   ptrInt64(20),
+		SortBy:      model.ListingSortPrice,
+		SortOrder:   model.SortDesc,
+	})
+	if err != nil {
+		t.Errorf("did not expect error, got %v", err)
+	}
+}
+
+func TestCreateListingKeepsDecimalPrice(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("failed to parse form: %v", err)
+		}
+		if got := r.PostForm.Get("price"); got != "1500.75" {
+			t.Errorf("price = %q, want %q", got, "1500.75")
+		}
+		io.WriteString(w, `{"result": true, "listing": {"id": 1}}`)
+	}))
+	defer srv.Close()
+
+	c := client.NewListingClient(srv.URL)
+	if _, err := c.CreateListing(model.Listing{UserID: 1, ListingType: "rent", Price: 1500.75}); err != nil {
+		t.Errorf("did not expect error, got %v", err)
+	}
+}
+
+func TestFetchListingByID(t *testing.T) {
+	tests := []struct {
+		name           string
+		responseCode   int
+		responseBody   string
+		expectErr      bool
+		expectNotFound bool
+	}{
+		{
+			name:         "success",
+			responseCode: http.StatusOK,
+			responseBody: `{"result": true, "listing": {"id": 7, "user_id": 10, "listing_type": "rent", "price": 1000}}`,
+			expectErr:    false,
+		},
+		{
+			name:           "not found",
+			responseCode:   http.StatusNotFound,
+			responseBody:   `{"result": false}`,
+			expectErr:      true,
+			expectNotFound: true,
+		},
+		{
+			name:         "non-200 response",
+			responseCode: http.StatusInternalServerError,
+			responseBody: `{"result": false}`,
+			expectErr:    true,
+		},
+		{
+			name:         "invalid JSON",
+			responseCode: http.StatusOK,
+			responseBody: `{invalid json}`,
+			expectErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/listings/7" {
+					t.Errorf("unexpected path: %s", r.URL.Path)
+				}
+				w.WriteHeader(tt.responseCode)
+				io.WriteString(w, tt.responseBody)
+			}))
+			defer srv.Close()
+
+			c := client.NewListingClient(srv.URL)
+			listing, err := c.FetchListingByID(7)
+
+			if tt.expectErr && err == nil {
+				t.Errorf("expected error, got nil")
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("did not expect error, got %v", err)
+			}
+			if tt.expectNotFound && !errors.Is(err, model.ErrNotFound) {
+				t.Errorf("expected ErrNotFound, got %v", err)
+			}
+			if !tt.expectErr && (listing == nil || listing.ID != 7) {
+				t.Errorf("unexpected listing: %v", listing)
+			}
+		})
+	}
+}
+
 func ptrInt64(v int64) *int64 {
 	return &v
 }
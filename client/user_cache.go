@@ -0,0 +1,184 @@
+package client
+
+import (
+	"public-api/model"
+	"sync"
+	"time"
+)
+
+// cachedUser is a user snapshot together with its expiry time. Invalidated
+// users are kept as tombstones until they expire so fetches that started
+// before the invalidation cannot cache what they read.
+type cachedUser struct {
+	user      model.User
+	expiresAt time.Time
+	gen       uint64
+	invalid   bool
+}
+
+// cachedUserClient decorates a UserClient with an in-memory TTL cache so
+// listing enrichment does not hit the User Service for every request
+type cachedUserClient struct {
+	UserClient
+
+	ttl     time.Duration
+	now     func() time.Time
+	mu      sync.RWMutex
+	entries map[int64]cachedUser
+
+	// gen counts invalidations. A fill is skipped when its user was
+	// invalidated after the fetch started, or when a sweep since then may
+	// have dropped such a tombstone.
+	gen       uint64
+	sweptGen  uint64
+	nextSweep time.Time
+}
+
+// NewCachedUserClient wraps next with a user cache. A non-positive ttl
+// disables caching and returns next unchanged.
+func NewCachedUserClient(next UserClient, ttl time.Duration) UserClient {
+	if ttl <= 0 {
+		return next
+	}
+	return &cachedUserClient{
+		UserClient: next,
+		ttl:        ttl,
+		now:        time.Now,
+		entries:    make(map[int64]cachedUser),
+		nextSweep:  time.Now().Add(ttl),
+	}
+}
+
+// FetchUserByID returns the cached user when fresh, otherwise loads it
+func (c *cachedUserClient) FetchUserByID(id int64) (*model.User, error) {
+	if u, ok := c.get(id); ok {
+		return u, nil
+	}
+
+	gen := c.generation()
+	u, err := c.UserClient.FetchUserByID(id)
+	if err != nil {
+		return nil, err
+	}
+	c.set(u, gen)
+	return u, nil
+}
+
+// FetchUsersByIDs serves cached users and only asks the User Service for misses
+func (c *cachedUserClient) FetchUsersByIDs(ids []int64) (map[int64]*model.User, error) {
+	users := make(map[int64]*model.User, len(ids))
+	var missing []int64
+	for _, id := range ids {
+		if u, ok := c.get(id); ok {
+			users[id] = u
+		} else {
+			missing = append(missing, id)
+		}
+	}
+
+	if len(missing) == 0 {
+		return users, nil
+	}
+
+	gen := c.generation()
+	fetched, err := c.UserClient.FetchUsersByIDs(missing)
+	if err != nil {
+		return nil, err
+	}
+	for id, u := range fetched {
+		c.set(u, gen)
+		users[id] = u
+	}
+	return users, nil
+}
+
+// CreateUser creates the user and primes the cache with the result
+func (c *cachedUserClient) CreateUser(name string) (*model.User, error) {
+	gen := c.generation()
+	u, err := c.UserClient.CreateUser(name)
+	if err != nil {
+		return nil, err
+	}
+	c.set(u, gen)
+	return u, nil
+}
+
+// UpdateUser updates the user and caches the result. The old copy is
+// dropped first so it is neither served nor written back by a concurrent
+// fetch while the update is in flight, and again on failure, as the update
+// may still have been applied.
+func (c *cachedUserClient) UpdateUser(id int64, name string) (*model.User, error) {
+	gen := c.invalidate(id)
+	u, err := c.UserClient.UpdateUser(id, name)
+	if err != nil || u == nil {
+		c.invalidate(id)
+		return u, err
+	}
+	c.set(u, gen)
+	return u, nil
+}
+
+func (c *cachedUserClient) get(id int64) (*model.User, bool) {
+	c.mu.RLock()
+	entry, ok := c.entries[id]
+	c.mu.RUnlock()
+
+	if !ok || entry.invalid || !c.now().Before(entry.expiresAt) {
+		return nil, false
+	}
+	u := entry.user
+	return &u, true
+}
+
+// generation is taken before loading users and passed to set with them
+func (c *cachedUserClient) generation() uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.gen
+}
+
+// set caches u unless it was invalidated since gen was taken
+func (c *cachedUserClient) set(u *model.User, gen uint64) {
+	if u == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	c.sweep(now)
+	if gen < c.sweptGen {
+		return
+	}
+	if entry, ok := c.entries[u.ID]; ok && entry.gen > gen {
+		return
+	}
+	c.entries[u.ID] = cachedUser{user: *u, expiresAt: now.Add(c.ttl), gen: gen}
+}
+
+// invalidate drops the cached user and returns the new generation
+func (c *cachedUserClient) invalidate(id int64) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	c.sweep(now)
+	c.gen++
+	c.entries[id] = cachedUser{expiresAt: now.Add(c.ttl), gen: c.gen, invalid: true}
+	return c.gen
+}
+
+// sweep evicts expired entries at most once per ttl, so the cache only
+// holds users seen recently. The caller must hold c.mu.
+func (c *cachedUserClient) sweep(now time.Time) {
+	if now.Before(c.nextSweep) {
+		return
+	}
+	for id, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(c.entries, id)
+		}
+	}
+	c.sweptGen = c.gen
+	c.nextSweep = now.Add(c.ttl)
+}
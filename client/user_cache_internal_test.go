@@ -0,0 +1,25 @@
+package client
+
+import (
+	"public-api/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCachedUserClientSweepsExpiredEntries(t *testing.T) {
+	now := time.Unix(0, 0)
+	c := NewCachedUserClient(nil, time.Minute).(*cachedUserClient)
+	c.now = func() time.Time { return now }
+	c.nextSweep = now.Add(time.Minute)
+
+	c.set(&model.User{ID: 1}, c.generation())
+	c.invalidate(2)
+	assert.Len(t, c.entries, 2)
+
+	now = now.Add(time.Minute)
+	c.set(&model.User{ID: 3}, c.generation())
+	assert.Len(t, c.entries, 1)
+	assert.Contains(t, c.entries, int64(3))
+}
@@ -0,0 +1,116 @@
+package client_test
+
+import (
+	"errors"
+	"public-api/client"
+	"public-api/mocks"
+	"public-api/model"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCachedUserClient(t *testing.T) {
+	t.Run("disabled returns inner client", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		inner := mocks.NewMockUserClient(ctrl)
+
+		assert.Equal(t, client.UserClient(inner), client.NewCachedUserClient(inner, 0))
+	})
+
+	t.Run("fetch by id is cached", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		inner := mocks.NewMockUserClient(ctrl)
+		inner.EXPECT().FetchUserByID(int64(1)).Return(&model.User{ID: 1, Name: "John"}, nil).Times(1)
+
+		c := client.NewCachedUserClient(inner, time.Minute)
+		for i := 0; i < 3; i++ {
+			u, err := c.FetchUserByID(1)
+			assert.NoError(t, err)
+			assert.Equal(t, "John", u.Name)
+		}
+	})
+
+	t.Run("batch fetch only requests misses", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		inner := mocks.NewMockUserClient(ctrl)
+		inner.EXPECT().FetchUserByID(int64(1)).Return(&model.User{ID: 1, Name: "John"}, nil)
+		inner.EXPECT().FetchUsersByIDs([]int64{2}).Return(map[int64]*model.User{2: {ID: 2, Name: "Doe"}}, nil)
+
+		c := client.NewCachedUserClient(inner, time.Minute)
+		_, err := c.FetchUserByID(1)
+		assert.NoError(t, err)
+
+		users, err := c.FetchUsersByIDs([]int64{1, 2})
+		assert.NoError(t, err)
+		assert.Len(t, users, 2)
+		assert.Equal(t, "Doe", users[2].Name)
+	})
+
+	t.Run("update caches the renamed user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		inner := mocks.NewMockUserClient(ctrl)
+		gomock.InOrder(
+			inner.EXPECT().FetchUserByID(int64(1)).Return(&model.User{ID: 1, Name: "John"}, nil),
+			inner.EXPECT().UpdateUser(int64(1), "Jane").Return(&model.User{ID: 1, Name: "Jane"}, nil),
+		)
+
+		c := client.NewCachedUserClient(inner, time.Minute)
+		_, err := c.FetchUserByID(1)
+		assert.NoError(t, err)
+
+		_, err = c.UpdateUser(1, "Jane")
+		assert.NoError(t, err)
+
+		u, err := c.FetchUserByID(1)
+		assert.NoError(t, err)
+		assert.Equal(t, "Jane", u.Name)
+	})
+
+	t.Run("failed update invalidates cached user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		inner := mocks.NewMockUserClient(ctrl)
+		gomock.InOrder(
+			inner.EXPECT().FetchUserByID(int64(1)).Return(&model.User{ID: 1, Name: "John"}, nil),
+			inner.EXPECT().UpdateUser(int64(1), "Jane").Return(nil, errors.New("timeout")),
+			inner.EXPECT().FetchUserByID(int64(1)).Return(&model.User{ID: 1, Name: "Jane"}, nil),
+		)
+
+		c := client.NewCachedUserClient(inner, time.Minute)
+		_, err := c.FetchUserByID(1)
+		assert.NoError(t, err)
+
+		_, err = c.UpdateUser(1, "Jane")
+		assert.Error(t, err)
+
+		u, err := c.FetchUserByID(1)
+		assert.NoError(t, err)
+		assert.Equal(t, "Jane", u.Name)
+	})
+
+	t.Run("fetch racing an update does not cache the old user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		inner := mocks.NewMockUserClient(ctrl)
+		var c client.UserClient
+		gomock.InOrder(
+			// The update lands while the old name is on its way back
+			inner.EXPECT().FetchUsersByIDs([]int64{1}).DoAndReturn(func([]int64) (map[int64]*model.User, error) {
+				_, err := c.UpdateUser(1, "Jane")
+				assert.NoError(t, err)
+				return map[int64]*model.User{1: {ID: 1, Name: "John"}}, nil
+			}),
+			inner.EXPECT().UpdateUser(int64(1), "Jane").Return(&model.User{ID: 1, Name: "Jane"}, nil),
+		)
+
+		c = client.NewCachedUserClient(inner, time.Minute)
+		users, err := c.FetchUsersByIDs([]int64{1})
+		assert.NoError(t, err)
+		assert.Equal(t, "John", users[1].Name)
+
+		u, err := c.FetchUserByID(1)
+		assert.NoError(t, err)
+		assert.Equal(t, "Jane", u.Name)
+	})
+}
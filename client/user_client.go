@@ -12,6 +12,7 @@ import (
 type UserClient interface {
 	FetchUserByID(id int64) (*model.User, error)
 	CreateUser(name string) (*model.User, error)
+	UpdateUser(id int64, name string) (*model.User, error)
 	FetchUsersByIDs(ids []int64) (map[int64]*model.User, error)
 }
 
@@ -38,6 +39,9 @@ func (uc *userClientImpl) FetchUserByID(id int64) (*model.User, error) {
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("user %d: %w", id, model.ErrNotFound)
+	}
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("user service returned status: %d", resp.StatusCode)
 	}
@@ -94,6 +98,49 @@ func (uc *userClientImpl) CreateUser(name string) (*model.User, error) {
 	return result.User, nil
 }
 
+// UpdateUser renames a user via PATCH using application/json
+func (uc *userClientImpl) UpdateUser(id int64, name string) (*model.User, error) {
+	payload := map[string]string{
+		"name": name,
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal user payload: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/users/%d", uc.baseURL, id)
+	req, err := http.NewRequest(http.MethodPatch, url, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := uc.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error updating user: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("user %d: %w", id, model.ErrNotFound)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("user service returned status: %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Result bool        `json:"result"`
+		User   *model.User `json:"user"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode user update: %w", err)
+	}
+
+	return result.User, nil
+}
+
 // FetchUsersByIDs fetch users via POST by IDs using application/json
 func (c *userClientImpl) FetchUsersByIDs(ids []int64) (map[int64]*model.User, error) {
 	payload := map[string]interface{}{"user_ids": ids}
@@ -118,6 +118,63 @@ func TestCreateUser(t *testing.T) {
 	}
 }
 
+func TestUpdateUser(t *testing.T) {
+	tests := []struct {
+		name       string
+		inputName  string
+		mockStatus int
+		mockBody   string
+		wantErr    bool
+		wantUser   *model.User
+	}{
+		{
+			name:       "success",
+			inputName:  "Alice",
+			mockStatus: http.StatusOK,
+			mockBody:   `{"result": true, "user": {"id": 2, "name": "Alice"}}`,
+			wantUser:   &model.User{ID: 2, Name: "Alice"},
+		},
+		{
+			name:       "not found",
+			inputName:  "Bob",
+			mockStatus: http.StatusNotFound,
+			mockBody:   `not found`,
+			wantErr:    true,
+		},
+		{
+			name:       "malformed json",
+			inputName:  "Charlie",
+			mockStatus: http.StatusOK,
+			mockBody:   `{invalid}`,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPatch || r.URL.Path != "/users/2" {
+					t.Errorf("unexpected request: %v %v", r.Method, r.URL.Path)
+				}
+				w.WriteHeader(tt.mockStatus)
+				fmt.Fprintln(w, tt.mockBody)
+			}))
+			defer server.Close()
+
+			uc := client.NewUserClient(server.URL)
+			user, err := uc.UpdateUser(2, tt.inputName)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("UpdateUser() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantUser != nil && (user == nil || user.ID != tt.wantUser.ID || user.Name != tt.wantUser.Name) {
+				t.Errorf("UpdateUser() got = %v, want = %v", user, tt.wantUser)
+			}
+		})
+	}
+}
+
 func TestFetchUsersByIDs(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -1,18 +1,116 @@
 package config
 
-import "os"
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// Transports for reaching a downstream service
+const (
+	TransportHTTP = "http"
+	TransportGRPC = "grpc"
+)
 
 // Config holds all configurable environment variables
 type Config struct {
-	ListingServiceURL string
-	UserServiceURL    string
+	ListingServiceURL         string
+	UserServiceURL            string
+	ListingServiceTransport   string
+	UserServiceTransport      string
+	ListingServiceGRPCAddr    string
+	UserServiceGRPCAddr       string
+	UserCacheTTL              time.Duration
+	VerifyListingUser         bool
+	ListingBatchConcurrency   int
+	ListingExportPageSize     int
+	ImportMaxFileSize         int
+	ImportMaxJobs             int
+	ImportMaxActive           int
+	ValidateResponses         bool
+	CompressionMinSize        int
+	ResponseCacheSize         int
+	ListingsCacheTTL          time.Duration
+	ListingCacheTTL           time.Duration
+	CacheStaleWhileRevalidate time.Duration
+	V1DeprecatedAt            time.Time
+	V1SunsetAt                time.Time
+	GraphQLMaxDepth           int
+	GraphQLMaxCost            int
+	GRPCPort                  string
+	StreamBacklog             int
+	StreamBuffer              int
+	StreamHeartbeat           time.Duration
+	WSAuthSecret              string
+	WSMaxConnections          int
+	WSMaxConnectionsPerUser   int
+	WSMaxSubscriptions        int
+	WSSendBuffer              int
+	WSPingInterval            time.Duration
+	AdminToken                string
+	WebhookWorkers            int
+	WebhookQueueSize          int
+	WebhookMaxAttempts        int
+	WebhookInitialBackoff     time.Duration
+	WebhookMaxBackoff         time.Duration
+	WebhookTimeout            time.Duration
+	WebhookLogSize            int
+	WebhookDeadLetterSize     int
+	WebhookAllowPrivate       bool
+	OutboxPath                string
+	OutboxPollInterval        time.Duration
+	EventDedupSize            int
 }
 
 // Load reads env vars and returns a Config struct
 func Load() Config {
 	return Config{
-		ListingServiceURL: getEnv("LISTING_SERVICE_URL", "http://localhost:6000"),
-		UserServiceURL:    getEnv("USER_SERVICE_URL", "http://localhost:6001"),
+		ListingServiceURL:         getEnv("LISTING_SERVICE_URL", "http://localhost:6000"),
+		UserServiceURL:            getEnv("USER_SERVICE_URL", "http://localhost:6001"),
+		ListingServiceTransport:   getEnv("LISTING_SERVICE_TRANSPORT", TransportHTTP),
+		UserServiceTransport:      getEnv("USER_SERVICE_TRANSPORT", TransportHTTP),
+		ListingServiceGRPCAddr:    getEnv("LISTING_SERVICE_GRPC_ADDR", "localhost:7000"),
+		UserServiceGRPCAddr:       getEnv("USER_SERVICE_GRPC_ADDR", "localhost:7001"),
+		UserCacheTTL:              getEnvDuration("USER_CACHE_TTL", 30*time.Second),
+		VerifyListingUser:         getEnvBool("VERIFY_LISTING_USER", true),
+		ListingBatchConcurrency:   getEnvInt("LISTING_BATCH_CONCURRENCY", 4),
+		ListingExportPageSize:     getEnvInt("LISTING_EXPORT_PAGE_SIZE", 100),
+		ImportMaxFileSize:         getEnvInt("IMPORT_MAX_FILE_SIZE", 5<<20),
+		ImportMaxJobs:             getEnvInt("IMPORT_MAX_JOBS", 100),
+		ImportMaxActive:           getEnvInt("IMPORT_MAX_ACTIVE", 4),
+		ValidateResponses:         getEnvBool("OPENAPI_VALIDATE_RESPONSES", false),
+		CompressionMinSize:        getEnvInt("COMPRESSION_MIN_SIZE", 1024),
+		ResponseCacheSize:         getEnvInt("RESPONSE_CACHE_SIZE", 1000),
+		ListingsCacheTTL:          getEnvDuration("LISTINGS_CACHE_TTL", 5*time.Second),
+		ListingCacheTTL:           getEnvDuration("LISTING_CACHE_TTL", 30*time.Second),
+		CacheStaleWhileRevalidate: getEnvDuration("CACHE_STALE_WHILE_REVALIDATE", 30*time.Second),
+		V1DeprecatedAt:            getEnvTime("API_V1_DEPRECATED_AT", time.Time{}),
+		V1SunsetAt:                getEnvTime("API_V1_SUNSET_AT", time.Time{}),
+		GraphQLMaxDepth:           getEnvInt("GRAPHQL_MAX_DEPTH", 5),
+		GraphQLMaxCost:            getEnvInt("GRAPHQL_MAX_COMPLEXITY", 500),
+		GRPCPort:                  getEnv("GRPC_PORT", "9090"),
+		StreamBacklog:             getEnvInt("STREAM_BACKLOG_SIZE", 100),
+		StreamBuffer:              getEnvInt("STREAM_BUFFER_SIZE", 16),
+		StreamHeartbeat:           getEnvDuration("STREAM_HEARTBEAT", 15*time.Second),
+		WSAuthSecret:              getEnv("WS_AUTH_SECRET", ""),
+		WSMaxConnections:          getEnvInt("WS_MAX_CONNECTIONS", 1000),
+		WSMaxConnectionsPerUser:   getEnvInt("WS_MAX_CONNECTIONS_PER_USER", 5),
+		WSMaxSubscriptions:        getEnvInt("WS_MAX_SUBSCRIPTIONS", 20),
+		WSSendBuffer:              getEnvInt("WS_SEND_BUFFER_SIZE", 32),
+		WSPingInterval:            getEnvDuration("WS_PING_INTERVAL", 30*time.Second),
+		AdminToken:                getEnv("ADMIN_API_TOKEN", ""),
+		WebhookWorkers:            getEnvInt("WEBHOOK_WORKERS", 4),
+		WebhookQueueSize:          getEnvInt("WEBHOOK_QUEUE_SIZE", 1000),
+		WebhookMaxAttempts:        getEnvInt("WEBHOOK_MAX_ATTEMPTS", 5),
+		WebhookInitialBackoff:     getEnvDuration("WEBHOOK_INITIAL_BACKOFF", time.Second),
+		WebhookMaxBackoff:         getEnvDuration("WEBHOOK_MAX_BACKOFF", 5*time.Minute),
+		WebhookTimeout:            getEnvDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+		WebhookLogSize:            getEnvInt("WEBHOOK_LOG_SIZE", 100),
+		WebhookDeadLetterSize:     getEnvInt("WEBHOOK_DEAD_LETTER_SIZE", 1000),
+		WebhookAllowPrivate:       getEnvBool("WEBHOOK_ALLOW_PRIVATE_TARGETS", false),
+		OutboxPath:                getEnv("OUTBOX_PATH", "outbox.db"),
+		OutboxPollInterval:        getEnvDuration("OUTBOX_POLL_INTERVAL", time.Second),
+		EventDedupSize:            getEnvNonNegativeInt("EVENT_DEDUP_SIZE", 10000),
 	}
 }
 
@@ -22,3 +120,48 @@ func getEnv(key string, defaultVal string) string {
 	}
 	return defaultVal
 }
+
+func getEnvInt(key string, defaultVal int) int {
+	if val := os.Getenv(key); val != "" {
+		if n, err := strconv.Atoi(val); err == nil {
+			return n
+		}
+	}
+	return defaultVal
+}
+
+// getEnvNonNegativeInt is getEnvInt for sizes, falling back to defaultVal
+// for negative values as well as malformed ones
+func getEnvNonNegativeInt(key string, defaultVal int) int {
+	if n := getEnvInt(key, defaultVal); n >= 0 {
+		return n
+	}
+	return defaultVal
+}
+
+func getEnvDuration(key string, defaultVal time.Duration) time.Duration {
+	if val := os.Getenv(key); val != "" {
+		if d, err := time.ParseDuration(val); err == nil {
+			return d
+		}
+	}
+	return defaultVal
+}
+
+func getEnvBool(key string, defaultVal bool) bool {
+	if val := os.Getenv(key); val != "" {
+		if b, err := strconv.ParseBool(val); err == nil {
+			return b
+		}
+	}
+	return defaultVal
+}
+
+func getEnvTime(key string, defaultVal time.Time) time.Time {
+	if val := os.Getenv(key); val != "" {
+		if t, err := time.Parse(time.RFC3339, val); err == nil {
+			return t
+		}
+	}
+	return defaultVal
+}
@@ -0,0 +1,183 @@
+// Package content negotiates the media type of request and response bodies
+package content
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Media types a body can be encoded in
+const (
+	JSON     = "application/json"
+	MsgPack  = "application/msgpack"
+	Protobuf = "application/x-protobuf"
+)
+
+// aliases maps other names in use for a media type to the one served
+var aliases = map[string]string{
+	"application/x-msgpack":           MsgPack,
+	"application/vnd.msgpack":         MsgPack,
+	"application/protobuf":            Protobuf,
+	"application/x-google-protobuf":   Protobuf,
+	"application/vnd.google.protobuf": Protobuf,
+}
+
+// suffixes maps structured syntax suffixes (RFC 6839) to media types, so
+// vendor types like application/vnd.public-api.v2+msgpack are understood
+var suffixes = map[string]string{
+	"+json":     JSON,
+	"+msgpack":  MsgPack,
+	"+protobuf": Protobuf,
+}
+
+const (
+	responseKey = "content_response"
+	requestKey  = "content_request"
+)
+
+// Negotiate picks the response media type from the Accept header among
+// offered, preferring earlier ones when the client has no preference, and
+// rejects requests accepting none of them with 406. Request bodies whose
+// Content-Type is one of offered are decoded as such; any other is read as
+// JSON, as before negotiation existed.
+func Negotiate(offered ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		AddVary(c.Writer.Header(), "Accept")
+
+		resp, ok := FromAccept(c.GetHeader("Accept"), offered)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusNotAcceptable, gin.H{"error": "Unsupported media type, accepted are " + strings.Join(offered, ", ")})
+			return
+		}
+		c.Set(responseKey, resp)
+
+		if req := Normalize(c.ContentType()); contains(offered, req) {
+			c.Set(requestKey, req)
+		}
+		c.Next()
+	}
+}
+
+// Response returns the media type negotiated for the response, JSON for
+// routes without negotiation
+func Response(c *gin.Context) string {
+	if t := c.GetString(responseKey); t != "" {
+		return t
+	}
+	return JSON
+}
+
+// Request returns the media type to decode the request body as, JSON unless
+// negotiated otherwise
+func Request(c *gin.Context) string {
+	if t := c.GetString(requestKey); t != "" {
+		return t
+	}
+	return JSON
+}
+
+// FromAccept picks the media type of offered the Accept header prefers.
+// Each offered type takes the quality of the most specific range matching
+// it, so wildcards do not select a type refused with q=0. No header and
+// wildcards select the first offered type; ties go to the type listed first
+// in the header. ok is false when none is acceptable.
+func FromAccept(accept string, offered []string) (string, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return offered[0], true
+	}
+
+	// Specificity of the ranges a type can match, least specific first
+	const (
+		anyType = iota + 1
+		anySubtype
+		exact
+	)
+	type quality struct {
+		specificity int
+		q           float64
+		index       int
+	}
+	qualities := make([]quality, len(offered))
+	for index, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if raw, found := params["q"]; found {
+			if q, err = strconv.ParseFloat(raw, 64); err != nil {
+				continue
+			}
+		}
+
+		for i, o := range offered {
+			var specificity int
+			switch {
+			case Normalize(mediaType) == o:
+				specificity = exact
+			case mediaType == "application/*":
+				specificity = anySubtype
+			case mediaType == "*/*":
+				specificity = anyType
+			default:
+				continue
+			}
+			if cur := qualities[i]; specificity > cur.specificity || (specificity == cur.specificity && q > cur.q) {
+				qualities[i] = quality{specificity: specificity, q: q, index: index}
+			}
+		}
+	}
+
+	best := -1
+	for i, qi := range qualities {
+		if qi.q <= 0 {
+			continue
+		}
+		if best < 0 || qi.q > qualities[best].q || (qi.q == qualities[best].q && qi.index < qualities[best].index) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return "", false
+	}
+	return offered[best], true
+}
+
+// Normalize maps a media type to the name it is served under
+func Normalize(mediaType string) string {
+	mediaType = strings.ToLower(mediaType)
+	if t, ok := aliases[mediaType]; ok {
+		return t
+	}
+	if i := strings.LastIndexByte(mediaType, '+'); i >= 0 {
+		if t, ok := suffixes[mediaType[i:]]; ok {
+			return t
+		}
+	}
+	return mediaType
+}
+
+// AddVary adds token to the Vary header unless it is listed already
+func AddVary(h http.Header, token string) {
+	for _, v := range h.Values("Vary") {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return
+			}
+		}
+	}
+	h.Add("Vary", token)
+}
+
+func contains(types []string, t string) bool {
+	for _, s := range types {
+		if s == t {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package content_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"public-api/content"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+var offered = []string{content.JSON, content.MsgPack, content.Protobuf}
+
+func TestFromAccept(t *testing.T) {
+	tests := []struct {
+		name   string
+		accept string
+		want   string
+		wantOK bool
+	}{
+		{name: "no header", accept: "", want: content.JSON, wantOK: true},
+		{name: "wildcard", accept: "*/*", want: content.JSON, wantOK: true},
+		{name: "browser", accept: "text/html,application/xhtml+xml,*/*;q=0.8", want: content.JSON, wantOK: true},
+		{name: "msgpack", accept: "application/msgpack", want: content.MsgPack, wantOK: true},
+		{name: "msgpack alias", accept: "application/x-msgpack", want: content.MsgPack, wantOK: true},
+		{name: "protobuf alias", accept: "application/protobuf", want: content.Protobuf, wantOK: true},
+		{name: "vendor json", accept: "application/vnd.public-api.v1+json", want: content.JSON, wantOK: true},
+		{name: "vendor msgpack", accept: "application/vnd.public-api.v1+msgpack", want: content.MsgPack, wantOK: true},
+		{name: "highest quality wins", accept: "application/json;q=0.5, application/x-protobuf", want: content.Protobuf, wantOK: true},
+		{name: "ties go to the first listed", accept: "application/msgpack, application/json", want: content.MsgPack, wantOK: true},
+		{name: "refused with q=0", accept: "application/msgpack;q=0", wantOK: false},
+		{name: "wildcard skips refused type", accept: "application/json;q=0, */*", want: content.MsgPack, wantOK: true},
+		{name: "wildcard skips less preferred type", accept: "application/json;q=0.5, application/*", want: content.MsgPack, wantOK: true},
+		{name: "specific range beats wildcard", accept: "*/*;q=0.1, application/x-protobuf;q=0.5", want: content.Protobuf, wantOK: true},
+		{name: "every type refused", accept: "application/json;q=0, application/msgpack;q=0, application/x-protobuf;q=0, */*", wantOK: false},
+		{name: "unsupported", accept: "application/xml", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := content.FromAccept(tt.accept, offered)
+			assert.Equal(t, tt.wantOK, ok)
+			if tt.wantOK {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.POST("/thing", content.Negotiate(offered...), func(c *gin.Context) {
+		c.String(http.StatusOK, content.Request(c)+" -> "+content.Response(c))
+	})
+
+	tests := []struct {
+		name        string
+		accept      string
+		contentType string
+		wantCode    int
+		wantBody    string
+	}{
+		{name: "defaults to json", wantCode: http.StatusOK, wantBody: "application/json -> application/json"},
+		{name: "msgpack both ways", accept: "application/msgpack", contentType: "application/x-msgpack", wantCode: http.StatusOK, wantBody: "application/msgpack -> application/msgpack"},
+		{name: "protobuf body", contentType: "application/x-protobuf", wantCode: http.StatusOK, wantBody: "application/x-protobuf -> application/json"},
+		{name: "unknown body is read as json", contentType: "text/plain", wantCode: http.StatusOK, wantBody: "application/json -> application/json"},
+		{name: "not acceptable", accept: "application/xml", wantCode: http.StatusNotAcceptable, wantBody: `"error":"Unsupported media type, accepted are application/json, application/msgpack, application/x-protobuf"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/thing", strings.NewReader("x"))
+			req.Header.Set("Accept", tt.accept)
+			req.Header.Set("Content-Type", tt.contentType)
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.wantCode, resp.Code)
+			assert.Equal(t, "Accept", resp.Header().Get("Vary"))
+			assert.Contains(t, resp.Body.String(), tt.wantBody)
+		})
+	}
+}
+
+func TestNegotiateKeepsOtherVaryTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.GET("/thing", func(c *gin.Context) {
+		c.Header("Vary", "Accept-Encoding")
+	}, content.Negotiate(offered...), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/thing", nil))
+
+	assert.Equal(t, []string{"Accept-Encoding", "Accept"}, resp.Header().Values("Vary"))
+}
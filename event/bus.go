@@ -0,0 +1,171 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+)
+
+// All subscribes to every event regardless of its name
+const All = "*"
+
+// Handler reacts to an event. Returned errors are logged; they never reach
+// the publisher.
+type Handler func(ctx context.Context, e Event) error
+
+// Typed adapts a handler of one concrete event type. Events of other types
+// are ignored.
+func Typed[T Event](fn func(ctx context.Context, e T) error) Handler {
+	return func(ctx context.Context, e Event) error {
+		typed, ok := e.(T)
+		if !ok {
+			return nil
+		}
+		return fn(ctx, typed)
+	}
+}
+
+// Deduplicate wraps h so an event ID seen among the last size events is
+// not handled again. Delivery is at least once, so subscribers with side
+// effects should be wrapped. A non-positive size disables deduplication and
+// returns h unchanged.
+func Deduplicate(size int, h Handler) Handler {
+	if size <= 0 {
+		return h
+	}
+	var (
+		mu    sync.Mutex
+		seen  = make(map[string]struct{}, size)
+		order []string
+	)
+	return func(ctx context.Context, e Event) error {
+		mu.Lock()
+		if _, dup := seen[e.EventID()]; dup {
+			mu.Unlock()
+			return nil
+		}
+		seen[e.EventID()] = struct{}{}
+		order = append(order, e.EventID())
+		if len(order) > size {
+			delete(seen, order[0])
+			order = order[1:]
+		}
+		mu.Unlock()
+		return h(ctx, e)
+	}
+}
+
+// Publisher publishes domain events
+type Publisher interface {
+	Publish(ctx context.Context, e Event)
+}
+
+// Broker is a message broker events can be forwarded to. Implementations
+// receive the event name and its JSON encoding.
+type Broker interface {
+	Send(ctx context.Context, name string, payload []byte) error
+}
+
+// BrokerFunc adapts a function to the Broker interface
+type BrokerFunc func(ctx context.Context, name string, payload []byte) error
+
+// Send implements Broker
+func (f BrokerFunc) Send(ctx context.Context, name string, payload []byte) error {
+	return f(ctx, name, payload)
+}
+
+// subscription is one registered handler
+type subscription struct {
+	subscriber string
+	event      string
+	handler    Handler
+	async      bool
+}
+
+// Bus dispatches events to subscribers in-process. Synchronous subscribers
+// run in Publish in the order they subscribed, asynchronous ones each on
+// their own goroutine. A subscriber that fails or panics is logged and does
+// not affect the publisher or the other subscribers.
+type Bus struct {
+	mu       sync.RWMutex
+	subs     []subscription
+	inFlight sync.WaitGroup
+}
+
+// NewBus creates an empty bus
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe runs h inside Publish for every event named name, or every
+// event for All. subscriber names the subscriber in logs.
+func (b *Bus) Subscribe(subscriber, name string, h Handler) {
+	b.add(subscription{subscriber: subscriber, event: name, handler: h})
+}
+
+// SubscribeAsync runs h on a new goroutine for every event named name, or
+// every event for All, so slow subscribers do not hold up the publisher.
+// The handler's context carries the publisher's values but is not canceled
+// with it.
+func (b *Bus) SubscribeAsync(subscriber, name string, h Handler) {
+	b.add(subscription{subscriber: subscriber, event: name, handler: h, async: true})
+}
+
+// Forward sends every event to br asynchronously
+func (b *Bus) Forward(subscriber string, br Broker) {
+	b.SubscribeAsync(subscriber, All, func(ctx context.Context, e Event) error {
+		payload, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("failed to encode event: %w", err)
+		}
+		return br.Send(ctx, e.EventName(), payload)
+	})
+}
+
+// Publish delivers e to its subscribers
+func (b *Bus) Publish(ctx context.Context, e Event) {
+	b.mu.RLock()
+	subs := b.subs
+	b.mu.RUnlock()
+
+	for _, s := range subs {
+		if s.event != All && s.event != e.EventName() {
+			continue
+		}
+		if !s.async {
+			s.call(ctx, e)
+			continue
+		}
+		b.inFlight.Add(1)
+		go func(s subscription) {
+			defer b.inFlight.Done()
+			s.call(context.WithoutCancel(ctx), e)
+		}(s)
+	}
+}
+
+// Wait blocks until every asynchronous handler started so far has returned
+func (b *Bus) Wait() {
+	b.inFlight.Wait()
+}
+
+func (b *Bus) add(s subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	// Copy on write so Publish can iterate without holding the lock
+	b.subs = append(append([]subscription{}, b.subs...), s)
+}
+
+// call runs the handler, containing any failure
+func (s subscription) call(ctx context.Context, e Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("event: subscriber %s panicked handling %s: %v", s.subscriber, e.EventName(), r)
+		}
+	}()
+	if err := s.handler(ctx, e); err != nil {
+		log.Printf("event: subscriber %s failed handling %s: %v", s.subscriber, e.EventName(), err)
+	}
+}
@@ -0,0 +1,194 @@
+package event_test
+
+import (
+	"context"
+	"errors"
+	"public-api/event"
+	"public-api/model"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type ctxKey struct{}
+
+func TestBusDispatchesByName(t *testing.T) {
+	bus := event.NewBus()
+
+	var got []string
+	record := func(label string) event.Handler {
+		return func(_ context.Context, e event.Event) error {
+			got = append(got, label+":"+e.EventName())
+			return nil
+		}
+	}
+	bus.Subscribe("users", event.NameUserCreated, record("users"))
+	bus.Subscribe("listings", event.NameListingCreated, record("listings"))
+	bus.Subscribe("all", event.All, record("all"))
+
+	bus.Publish(context.Background(), event.UserCreated{User: model.User{ID: 1}})
+	bus.Publish(context.Background(), event.ListingCreated{Listing: model.Listing{ID: 2}})
+	bus.Publish(context.Background(), event.UserUpdated{User: model.User{ID: 1}})
+
+	assert.Equal(t, []string{
+		"users:user.created", "all:user.created",
+		"listings:listing.created", "all:listing.created",
+		"all:user.updated",
+	}, got)
+}
+
+func TestTyped(t *testing.T) {
+	bus := event.NewBus()
+
+	var listings []model.Listing
+	bus.Subscribe("typed", event.All, event.Typed(func(_ context.Context, e event.ListingCreated) error {
+		listings = append(listings, e.Listing)
+		return nil
+	}))
+
+	bus.Publish(context.Background(), event.UserCreated{User: model.User{ID: 1}})
+	bus.Publish(context.Background(), event.ListingCreated{Listing: model.Listing{ID: 2}})
+
+	assert.Equal(t, []model.Listing{{ID: 2}}, listings)
+}
+
+func TestBusIsolatesFailingSubscribers(t *testing.T) {
+	bus := event.NewBus()
+
+	calls := 0
+	bus.Subscribe("panics", event.All, func(context.Context, event.Event) error { panic("boom") })
+	bus.Subscribe("fails", event.All, func(context.Context, event.Event) error { return errors.New("boom") })
+	bus.SubscribeAsync("panics async", event.All, func(context.Context, event.Event) error { panic("boom") })
+	bus.Subscribe("works", event.All, func(context.Context, event.Event) error {
+		calls++
+		return nil
+	})
+
+	assert.NotPanics(t, func() {
+		bus.Publish(context.Background(), event.UserCreated{})
+		bus.Wait()
+	})
+	assert.Equal(t, 1, calls)
+}
+
+func TestBusAsyncSubscribers(t *testing.T) {
+	bus := event.NewBus()
+
+	release := make(chan struct{})
+	var (
+		mu       sync.Mutex
+		received []event.Event
+		ctxErr   error
+		ctxValue interface{}
+	)
+	bus.SubscribeAsync("slow", event.NameUserCreated, func(ctx context.Context, e event.Event) error {
+		<-release
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, e)
+		ctxErr, ctxValue = ctx.Err(), ctx.Value(ctxKey{})
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "request"))
+	done := make(chan struct{})
+	go func() {
+		bus.Publish(ctx, event.UserCreated{User: model.User{ID: 1}})
+		close(done)
+	}()
+
+	// Publish returns while the subscriber is still blocked
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Publish waited for an async subscriber")
+	}
+	cancel()
+	close(release)
+	bus.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, received, 1)
+	assert.NoError(t, ctxErr, "async handlers outlive the publisher's context")
+	assert.Equal(t, "request", ctxValue)
+}
+
+func TestBusForwardsToBroker(t *testing.T) {
+	bus := event.NewBus()
+
+	type message struct {
+		name    string
+		payload []byte
+	}
+	sent := make(chan message, 1)
+	bus.Forward("broker", event.BrokerFunc(func(_ context.Context, name string, payload []byte) error {
+		sent <- message{name, payload}
+		return nil
+	}))
+
+	published := event.ListingCreated{
+		Meta:    event.Meta{ID: "evt_1", OccurredAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+		Listing: model.Listing{ID: 2, ListingType: "rent"},
+	}
+	bus.Publish(context.Background(), published)
+	bus.Wait()
+
+	msg := <-sent
+	assert.Equal(t, event.NameListingCreated, msg.name)
+	assert.Contains(t, string(msg.payload), `"id":"evt_1"`)
+
+	decoded, err := event.Decode(msg.name, msg.payload)
+	require.NoError(t, err)
+	assert.Equal(t, published, decoded)
+}
+
+func TestDecode(t *testing.T) {
+	_, err := event.Decode("listing.deleted", []byte(`{}`))
+	assert.Error(t, err)
+	_, err = event.Decode(event.NameUserCreated, []byte(`not json`))
+	assert.Error(t, err)
+
+	e, err := event.Decode(event.NameUserUpdated, []byte(`{"id":"evt_1","user":{"id":3,"name":"Jane"}}`))
+	require.NoError(t, err)
+	assert.Equal(t, event.UserUpdated{Meta: event.Meta{ID: "evt_1"}, User: model.User{ID: 3, Name: "Jane"}}, e)
+}
+
+func TestDeduplicate(t *testing.T) {
+	var handled []string
+	h := event.Deduplicate(2, func(_ context.Context, e event.Event) error {
+		handled = append(handled, e.EventID())
+		return nil
+	})
+
+	for _, id := range []string{"a", "b", "a", "c", "b", "a"} {
+		require.NoError(t, h(context.Background(), event.UserCreated{Meta: event.Meta{ID: id}}))
+	}
+
+	// "a" is forgotten once two newer IDs have been seen
+	assert.Equal(t, []string{"a", "b", "c", "a"}, handled)
+}
+
+func TestDeduplicateDisabled(t *testing.T) {
+	for _, size := range []int{0, -1} {
+		var handled int
+		h := event.Deduplicate(size, func(context.Context, event.Event) error {
+			handled++
+			return nil
+		})
+
+		for i := 0; i < 2; i++ {
+			require.NoError(t, h(context.Background(), event.UserCreated{Meta: event.Meta{ID: "a"}}))
+		}
+		assert.Equal(t, 2, handled, "size %d", size)
+	}
+}
+
+func TestNewMeta(t *testing.T) {
+	a, b := event.NewMeta(), event.NewMeta()
+	assert.NotEqual(t, a.ID, b.ID)
+	assert.WithinDuration(t, time.Now(), a.OccurredAt, time.Second)
+}
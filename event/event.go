@@ -0,0 +1,94 @@
+package event
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"public-api/model"
+	"time"
+)
+
+// Names of the domain events
+const (
+	NameUserCreated    = "user.created"
+	NameUserUpdated    = "user.updated"
+	NameListingCreated = "listing.created"
+)
+
+// Event is something that happened in the domain. Events are published by
+// the service layer once the downstream service has confirmed the change.
+type Event interface {
+	EventName() string
+	EventID() string
+}
+
+// Meta identifies an event occurrence. The ID is unique per event and kept
+// when an event is delivered more than once, so consumers can drop
+// duplicates.
+type Meta struct {
+	ID         string    `json:"id"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// NewMeta returns the metadata of an event occurring now
+func NewMeta() Meta {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic("event: failed to read random bytes: " + err.Error())
+	}
+	return Meta{ID: "evt_" + hex.EncodeToString(b), OccurredAt: time.Now()}
+}
+
+// EventID implements Event
+func (m Meta) EventID() string { return m.ID }
+
+// UserCreated is published after a user was created
+type UserCreated struct {
+	Meta
+	User model.User `json:"user"`
+}
+
+// EventName implements Event
+func (UserCreated) EventName() string { return NameUserCreated }
+
+// UserUpdated is published after a user was renamed
+type UserUpdated struct {
+	Meta
+	User model.User `json:"user"`
+}
+
+// EventName implements Event
+func (UserUpdated) EventName() string { return NameUserUpdated }
+
+// ListingCreated is published after a listing was created. The listing
+// carries its owner unless the owner could not be fetched.
+type ListingCreated struct {
+	Meta
+	Listing model.Listing `json:"listing"`
+}
+
+// EventName implements Event
+func (ListingCreated) EventName() string { return NameListingCreated }
+
+// Decode rebuilds an event from its name and JSON encoding
+func Decode(name string, payload []byte) (Event, error) {
+	switch name {
+	case NameUserCreated:
+		return decode[UserCreated](payload)
+	case NameUserUpdated:
+		return decode[UserUpdated](payload)
+	case NameListingCreated:
+		return decode[ListingCreated](payload)
+	default:
+		return nil, fmt.Errorf("unknown event %q", name)
+	}
+}
+
+func decode[T Event](payload []byte) (Event, error) {
+	var e T
+	if err := json.Unmarshal(payload, &e); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", e.EventName(), err)
+	}
+	return e, nil
+}
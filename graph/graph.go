@@ -0,0 +1,148 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"public-api/service"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// Default query limits
+const (
+	DefaultMaxDepth      = 5
+	DefaultMaxComplexity = 500
+)
+
+// QueryRequest is a GraphQL request as sent over HTTP
+type QueryRequest struct {
+	Query         string                 `json:"query" binding:"required"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// ErrorLocation points at the part of the query an error refers to
+type ErrorLocation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// QueryError is a GraphQL error as returned to clients
+type QueryError struct {
+	Message    string                 `json:"message"`
+	Locations  []ErrorLocation        `json:"locations,omitempty"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// QueryResponse is the result of executing a QueryRequest
+type QueryResponse struct {
+	Data   interface{}  `json:"data"`
+	Errors []QueryError `json:"errors,omitempty"`
+}
+
+// Executor runs GraphQL requests against the schema built by NewSchema,
+// enforcing depth and complexity limits before anything is resolved
+type Executor struct {
+	schema        graphql.Schema
+	users         service.UserService
+	maxDepth      int
+	maxComplexity int
+}
+
+// Option customizes an Executor
+type Option func(*Executor)
+
+// WithMaxDepth rejects queries nesting fields deeper than n. Zero or less
+// disables the check.
+func WithMaxDepth(n int) Option {
+	return func(e *Executor) {
+		e.maxDepth = n
+	}
+}
+
+// WithMaxComplexity rejects queries whose estimated cost exceeds n. Zero or
+// less disables the check.
+func WithMaxComplexity(n int) Option {
+	return func(e *Executor) {
+		e.maxComplexity = n
+	}
+}
+
+// NewExecutor builds the schema and returns an Executor serving it
+func NewExecutor(users service.UserService, listings service.ListingService, opts ...Option) (*Executor, error) {
+	schema, err := NewSchema(users, listings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build graphql schema: %w", err)
+	}
+
+	e := &Executor{
+		schema:        schema,
+		users:         users,
+		maxDepth:      DefaultMaxDepth,
+		maxComplexity: DefaultMaxComplexity,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e, nil
+}
+
+// Execute parses, validates, checks the limits of and runs req
+func (e *Executor) Execute(ctx context.Context, req QueryRequest) *QueryResponse {
+	doc, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		return &QueryResponse{Errors: formatErrors(gqlerrors.FormatErrors(err))}
+	}
+
+	if result := graphql.ValidateDocument(&e.schema, doc, nil); !result.IsValid {
+		return &QueryResponse{Errors: formatErrors(result.Errors)}
+	}
+
+	depth, complexity := measure(doc, req.OperationName, req.Variables)
+	if e.maxDepth > 0 && depth > e.maxDepth {
+		return limitExceeded(fmt.Sprintf("query depth %d exceeds the maximum of %d", depth, e.maxDepth))
+	}
+	if e.maxComplexity > 0 && complexity > e.maxComplexity {
+		return limitExceeded(fmt.Sprintf("query complexity %d exceeds the maximum of %d", complexity, e.maxComplexity))
+	}
+
+	// Each request gets its own loader so batches and memoized users never
+	// leak between requests
+	ctx = context.WithValue(ctx, loaderKey{}, newUserLoader(ctx, e.users.GetUsersByIDs))
+
+	result := graphql.Execute(graphql.ExecuteParams{
+		Schema:        e.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       ctx,
+	})
+	return &QueryResponse{Data: result.Data, Errors: formatErrors(result.Errors)}
+}
+
+func limitExceeded(msg string) *QueryResponse {
+	return &QueryResponse{Errors: []QueryError{{
+		Message:    msg,
+		Extensions: map[string]interface{}{"code": CodeQueryTooComplex},
+	}}}
+}
+
+func formatErrors(errs []gqlerrors.FormattedError) []QueryError {
+	if len(errs) == 0 {
+		return nil
+	}
+	out := make([]QueryError, len(errs))
+	for i, err := range errs {
+		out[i] = QueryError{Message: err.Message, Path: err.Path, Extensions: err.Extensions}
+		for _, loc := range err.Locations {
+			out[i].Locations = append(out[i].Locations, ErrorLocation{Line: loc.Line, Column: loc.Column})
+		}
+	}
+	return out
+}
@@ -0,0 +1,279 @@
+package graph_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"public-api/graph"
+	"public-api/mocks"
+	"public-api/model"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newExecutor(t *testing.T, opts ...graph.Option) (*graph.Executor, *mocks.MockUserService, *mocks.MockListingService) {
+	ctrl := gomock.NewController(t)
+	us := mocks.NewMockUserService(ctrl)
+	ls := mocks.NewMockListingService(ctrl)
+	e, err := graph.NewExecutor(us, ls, opts...)
+	require.NoError(t, err)
+	return e, us, ls
+}
+
+// toJSON renders a response the way the HTTP handler would
+func toJSON(t *testing.T, resp *graph.QueryResponse) string {
+	b, err := json.Marshal(resp)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestListingsBatchUserLookups(t *testing.T) {
+	e, us, ls := newExecutor(t)
+
+	ls.EXPECT().
+		FindListings(gomock.Any(), model.ListingQuery{Page: 2, Size: 3, ListingType: "rent", SortBy: "price", SortOrder: "desc"}).
+		Return([]model.Listing{
+			{ID: 1, UserID: 7, ListingType: "rent", Price: 300},
+			{ID: 2, UserID: 8, ListingType: "rent", Price: 200},
+			{ID: 3, UserID: 7, ListingType: "rent", Price: 100},
+		}, nil)
+	// One call for every owner, with each ID once; user 8 does not exist
+	us.EXPECT().
+		GetUsersByIDs(gomock.Any(), []int64{7, 8}).
+		Return(map[int64]*model.User{7: {ID: 7, Name: "John"}}, nil).
+		Times(1)
+
+	resp := e.Execute(context.Background(), graph.QueryRequest{
+		Query: `{ listings(pageNum: 2, pageSize: 3, listingType: RENT, sortBy: PRICE, sortOrder: DESC) {
+			id listingType price user { id name }
+		} }`,
+	})
+
+	assert.JSONEq(t, `{"data":{"listings":[
+		{"id":"1","listingType":"RENT","price":300,"user":{"id":"7","name":"John"}},
+		{"id":"2","listingType":"RENT","price":200,"user":null},
+		{"id":"3","listingType":"RENT","price":100,"user":{"id":"7","name":"John"}}
+	]}}`, toJSON(t, resp))
+}
+
+func TestListingsWithoutUserSkipLookups(t *testing.T) {
+	e, _, ls := newExecutor(t)
+
+	ls.EXPECT().
+		FindListings(gomock.Any(), model.ListingQuery{Page: 1, Size: 10}).
+		Return([]model.Listing{{ID: 1, UserID: 7, CreatedAt: 1700000000000000}}, nil)
+
+	resp := e.Execute(context.Background(), graph.QueryRequest{Query: `{ listings { id userId createdAt } }`})
+
+	assert.JSONEq(t, `{"data":{"listings":[{"id":"1","userId":"7","createdAt":1700000000000000}]}}`, toJSON(t, resp))
+}
+
+func TestListingsInvalidFilter(t *testing.T) {
+	e, _, _ := newExecutor(t)
+
+	resp := e.Execute(context.Background(), graph.QueryRequest{
+		Query:     `query($min: Float) { listings(minPrice: $min, maxPrice: 1) { id } }`,
+		Variables: map[string]interface{}{"min": 5.0},
+	})
+
+	require.Len(t, resp.Errors, 1)
+	assert.Contains(t, resp.Errors[0].Message, "min_price must not exceed max_price")
+	assert.Equal(t, graph.CodeBadUserInput, resp.Errors[0].Extensions["code"])
+}
+
+func TestUserQuery(t *testing.T) {
+	tests := []struct {
+		name         string
+		mock         func(us *mocks.MockUserService)
+		expectedJSON string
+		expectedCode string
+	}{
+		{
+			name: "found",
+			mock: func(us *mocks.MockUserService) {
+				us.EXPECT().GetUserByID(gomock.Any(), int64(5)).Return(&model.User{ID: 5, Name: "Jane"}, nil)
+			},
+			expectedJSON: `{"data":{"user":{"id":"5","name":"Jane"}}}`,
+		},
+		{
+			name: "not found is null",
+			mock: func(us *mocks.MockUserService) {
+				us.EXPECT().GetUserByID(gomock.Any(), int64(5)).Return(nil, fmt.Errorf("user 5: %w", model.ErrNotFound))
+			},
+			expectedJSON: `{"data":{"user":null}}`,
+		},
+		{
+			name: "downstream failure",
+			mock: func(us *mocks.MockUserService) {
+				us.EXPECT().GetUserByID(gomock.Any(), int64(5)).Return(nil, errors.New("boom"))
+			},
+			expectedCode: graph.CodeInternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, us, _ := newExecutor(t)
+			tt.mock(us)
+
+			resp := e.Execute(context.Background(), graph.QueryRequest{Query: `{ user(id: 5) { id name } }`})
+
+			if tt.expectedCode != "" {
+				require.Len(t, resp.Errors, 1)
+				assert.Equal(t, tt.expectedCode, resp.Errors[0].Extensions["code"])
+				return
+			}
+			assert.JSONEq(t, tt.expectedJSON, toJSON(t, resp))
+		})
+	}
+}
+
+func TestMutations(t *testing.T) {
+	t.Run("create user", func(t *testing.T) {
+		e, us, _ := newExecutor(t)
+		us.EXPECT().CreateUser(gomock.Any(), "Jane").Return(&model.User{ID: 9, Name: "Jane"}, nil)
+
+		resp := e.Execute(context.Background(), graph.QueryRequest{
+			Query:     `mutation($name: String!) { createUser(name: $name) { id name } }`,
+			Variables: map[string]interface{}{"name": "Jane"},
+		})
+
+		assert.JSONEq(t, `{"data":{"createUser":{"id":"9","name":"Jane"}}}`, toJSON(t, resp))
+	})
+
+	t.Run("create listing uses embedded owner", func(t *testing.T) {
+		e, _, ls := newExecutor(t)
+		ls.EXPECT().
+			CreateListing(gomock.Any(), model.Listing{UserID: 7, ListingType: "sale", Price: 1500.5}).
+			Return(&model.Listing{ID: 3, UserID: 7, ListingType: "sale", Price: 1500.5, User: &model.User{ID: 7, Name: "John"}}, nil)
+
+		resp := e.Execute(context.Background(), graph.QueryRequest{
+			Query: `mutation { createListing(userId: 7, listingType: SALE, price: 1500.5) { id price user { name } } }`,
+		})
+
+		assert.JSONEq(t, `{"data":{"createListing":{"id":"3","price":1500.5,"user":{"name":"John"}}}}`, toJSON(t, resp))
+	})
+
+	t.Run("create listing for unknown owner", func(t *testing.T) {
+		e, _, ls := newExecutor(t)
+		ls.EXPECT().
+			CreateListing(gomock.Any(), gomock.Any()).
+			Return(nil, fmt.Errorf("%w: user 7 does not exist", model.ErrUnprocessable))
+
+		resp := e.Execute(context.Background(), graph.QueryRequest{
+			Query: `mutation { createListing(userId: 7, listingType: RENT, price: 10) { id } }`,
+		})
+
+		assert.Nil(t, resp.Data)
+		require.Len(t, resp.Errors, 1)
+		assert.Equal(t, graph.CodeUnprocessable, resp.Errors[0].Extensions["code"])
+	})
+}
+
+func TestQueryErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		opts          []graph.Option
+		req           graph.QueryRequest
+		expectedError string
+		expectedCode  string
+	}{
+		{
+			name:          "syntax error",
+			req:           graph.QueryRequest{Query: `{ listings { id `},
+			expectedError: "Syntax Error",
+		},
+		{
+			name:          "unknown field",
+			req:           graph.QueryRequest{Query: `{ listings { nope } }`},
+			expectedError: `Cannot query field "nope"`,
+		},
+		{
+			name:          "too deep",
+			opts:          []graph.Option{graph.WithMaxDepth(2)},
+			req:           graph.QueryRequest{Query: `{ listings { user { name } } }`},
+			expectedError: "query depth 3 exceeds the maximum of 2",
+			expectedCode:  graph.CodeQueryTooComplex,
+		},
+		{
+			name:          "too deep through fragments",
+			opts:          []graph.Option{graph.WithMaxDepth(2)},
+			req:           graph.QueryRequest{Query: `{ listings { ...L } } fragment L on Listing { user { name } }`},
+			expectedError: "query depth 3 exceeds the maximum of 2",
+			expectedCode:  graph.CodeQueryTooComplex,
+		},
+		{
+			name: "too complex",
+			opts: []graph.Option{graph.WithMaxComplexity(100)},
+			// 1 + 50 * (id + user{name})
+			req:           graph.QueryRequest{Query: `{ listings(pageSize: 50) { id user { name } } }`},
+			expectedError: "query complexity 151 exceeds the maximum of 100",
+			expectedCode:  graph.CodeQueryTooComplex,
+		},
+		{
+			name: "too complex through variables",
+			opts: []graph.Option{graph.WithMaxComplexity(100)},
+			req: graph.QueryRequest{
+				Query:     `query($n: Int) { listings(pageSize: $n) { id } }`,
+				Variables: map[string]interface{}{"n": float64(500)},
+			},
+			expectedError: "query complexity 501 exceeds the maximum of 100",
+			expectedCode:  graph.CodeQueryTooComplex,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// No service expectations: nothing may be resolved
+			e, _, _ := newExecutor(t, tt.opts...)
+
+			resp := e.Execute(context.Background(), tt.req)
+
+			assert.Nil(t, resp.Data)
+			require.NotEmpty(t, resp.Errors)
+			assert.Contains(t, resp.Errors[0].Message, tt.expectedError)
+			if tt.expectedCode != "" {
+				assert.Equal(t, tt.expectedCode, resp.Errors[0].Extensions["code"])
+			}
+		})
+	}
+}
+
+func TestIntrospectionCountsAgainstLimits(t *testing.T) {
+	t.Run("shallow query within the defaults", func(t *testing.T) {
+		e, _, _ := newExecutor(t)
+
+		resp := e.Execute(context.Background(), graph.QueryRequest{
+			Query: `{ __schema { queryType { fields { name type { kind } } } } }`,
+		})
+
+		assert.Empty(t, resp.Errors)
+		assert.Contains(t, toJSON(t, resp), `"name":"listings"`)
+	})
+
+	t.Run("deep query rejected", func(t *testing.T) {
+		e, _, _ := newExecutor(t)
+
+		resp := e.Execute(context.Background(), graph.QueryRequest{
+			Query: `{ __schema { types { fields { type { fields { type { fields { name } } } } } } } }`,
+		})
+
+		assert.Nil(t, resp.Data)
+		require.NotEmpty(t, resp.Errors)
+		assert.Contains(t, resp.Errors[0].Message, "query depth 8 exceeds the maximum of 5")
+		assert.Equal(t, graph.CodeQueryTooComplex, resp.Errors[0].Extensions["code"])
+	})
+
+	t.Run("typename is free", func(t *testing.T) {
+		e, _, _ := newExecutor(t, graph.WithMaxDepth(1), graph.WithMaxComplexity(1))
+
+		resp := e.Execute(context.Background(), graph.QueryRequest{Query: `{ __typename }`})
+
+		assert.Empty(t, resp.Errors)
+		assert.Contains(t, toJSON(t, resp), `"__typename":"Query"`)
+	})
+}
@@ -0,0 +1,131 @@
+package graph
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// pageSizeArg is the argument that sets how many items a list field returns
+const pageSizeArg = "pageSize"
+
+// typenameField is the meta field any selection may ask for at no cost
+const typenameField = "__typename"
+
+// paginatedFields return defaultPageSize items when pageSize is omitted
+var paginatedFields = map[string]bool{"listings": true}
+
+// queryCost measures the selected operation of a parsed query. depth is the
+// deepest field nesting; complexity counts one per field, with the fields
+// below a paginated list counted once per requested item. Introspection
+// fields count like any other, as nested __schema selections are as costly
+// to resolve; only __typename is free.
+type queryCost struct {
+	variables map[string]interface{}
+	fragments map[string]*ast.FragmentDefinition
+	visiting  map[string]bool
+}
+
+func measure(doc *ast.Document, operationName string, variables map[string]interface{}) (depth, complexity int) {
+	qc := &queryCost{
+		variables: variables,
+		fragments: make(map[string]*ast.FragmentDefinition),
+		visiting:  make(map[string]bool),
+	}
+
+	var op *ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		switch d := def.(type) {
+		case *ast.FragmentDefinition:
+			qc.fragments[d.Name.Value] = d
+		case *ast.OperationDefinition:
+			if operationName == "" || (d.Name != nil && d.Name.Value == operationName) {
+				if op == nil {
+					op = d
+				}
+			}
+		}
+	}
+	if op == nil {
+		// Let execution report the missing operation
+		return 0, 0
+	}
+	return qc.selectionSet(op.SelectionSet)
+}
+
+func (qc *queryCost) selectionSet(set *ast.SelectionSet) (depth, complexity int) {
+	if set == nil {
+		return 0, 0
+	}
+	for _, sel := range set.Selections {
+		var d, c int
+		switch s := sel.(type) {
+		case *ast.Field:
+			d, c = qc.field(s)
+		case *ast.InlineFragment:
+			d, c = qc.selectionSet(s.SelectionSet)
+		case *ast.FragmentSpread:
+			name := s.Name.Value
+			frag, ok := qc.fragments[name]
+			if !ok || qc.visiting[name] {
+				continue
+			}
+			qc.visiting[name] = true
+			d, c = qc.selectionSet(frag.SelectionSet)
+			qc.visiting[name] = false
+		}
+		if d > depth {
+			depth = d
+		}
+		complexity += c
+	}
+	return depth, complexity
+}
+
+func (qc *queryCost) field(f *ast.Field) (depth, complexity int) {
+	if f.Name.Value == typenameField {
+		return 0, 0
+	}
+	childDepth, childComplexity := qc.selectionSet(f.SelectionSet)
+	return childDepth + 1, 1 + qc.multiplier(f)*childComplexity
+}
+
+// multiplier returns the page size requested by a paginated field, or 1
+func (qc *queryCost) multiplier(f *ast.Field) int {
+	for _, arg := range f.Arguments {
+		if arg.Name.Value != pageSizeArg {
+			continue
+		}
+		switch v := arg.Value.(type) {
+		case *ast.IntValue:
+			if n, err := strconv.Atoi(v.Value); err == nil && n > 0 {
+				return n
+			}
+		case *ast.Variable:
+			if n, ok := intVariable(qc.variables[v.Name.Value]); ok && n > 0 {
+				return n
+			}
+		}
+		return 1
+	}
+	if paginatedFields[f.Name.Value] {
+		return defaultPageSize
+	}
+	return 1
+}
+
+// intVariable reads an integer variable decoded from JSON
+func intVariable(v interface{}) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case float64:
+		return int(n), n == float64(int(n))
+	case fmt.Stringer:
+		i, err := strconv.Atoi(n.String())
+		return i, err == nil
+	default:
+		return 0, false
+	}
+}
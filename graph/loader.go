@@ -0,0 +1,87 @@
+package graph
+
+import (
+	"context"
+	"public-api/model"
+	"sync"
+)
+
+// userBatchFunc fetches several users at once, keyed by ID
+type userBatchFunc func(ctx context.Context, ids []int64) (map[int64]*model.User, error)
+
+// userLoader collects the user IDs requested while resolving one level of a
+// query and fetches them in a single batch the first time any of them is
+// needed. Results are memoized for the rest of the request.
+type userLoader struct {
+	ctx   context.Context
+	fetch userBatchFunc
+
+	mu      sync.Mutex
+	pending []int64
+	users   map[int64]*model.User
+	errs    map[int64]error
+}
+
+func newUserLoader(ctx context.Context, fetch userBatchFunc) *userLoader {
+	return &userLoader{
+		ctx:   ctx,
+		fetch: fetch,
+		users: make(map[int64]*model.User),
+		errs:  make(map[int64]error),
+	}
+}
+
+// Load queues id and returns a thunk that yields its user. A missing user
+// yields nil without an error.
+func (l *userLoader) Load(id int64) func() (*model.User, error) {
+	l.mu.Lock()
+	if !l.known(id) && !l.queued(id) {
+		l.pending = append(l.pending, id)
+	}
+	l.mu.Unlock()
+
+	return func() (*model.User, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if !l.known(id) {
+			l.dispatch()
+		}
+		return l.users[id], l.errs[id]
+	}
+}
+
+// dispatch fetches every pending ID. The caller must hold l.mu.
+func (l *userLoader) dispatch() {
+	ids := l.pending
+	l.pending = nil
+	if len(ids) == 0 {
+		return
+	}
+
+	users, err := l.fetch(l.ctx, ids)
+	for _, id := range ids {
+		if err != nil {
+			l.errs[id] = err
+			continue
+		}
+		l.users[id] = users[id]
+	}
+}
+
+func (l *userLoader) known(id int64) bool {
+	_, found := l.users[id]
+	if !found {
+		_, found = l.errs[id]
+	}
+	return found
+}
+
+func (l *userLoader) queued(id int64) bool {
+	for _, p := range l.pending {
+		if p == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,330 @@
+package graph
+
+import (
+	"errors"
+	"fmt"
+	"public-api/model"
+	"public-api/service"
+	"strconv"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// defaultPageSize matches the REST default for GET /listings
+const defaultPageSize = 10
+
+// Error codes reported in the extensions of resolver errors
+const (
+	CodeBadUserInput  = "BAD_USER_INPUT"
+	CodeNotFound      = "NOT_FOUND"
+	CodeUnprocessable = "UNPROCESSABLE"
+	CodeInternal      = "INTERNAL_SERVER_ERROR"
+
+	// CodeQueryTooComplex rejects a query over the depth or complexity limit
+	CodeQueryTooComplex = "QUERY_TOO_COMPLEX"
+)
+
+type loaderKey struct{}
+
+// resolverError carries the error code of a failed resolver to the client
+type resolverError struct {
+	err  error
+	code string
+}
+
+func (e resolverError) Error() string { return e.err.Error() }
+
+func (e resolverError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": e.code}
+}
+
+// toGraphQLError maps service sentinel errors onto error codes
+func toGraphQLError(err error) error {
+	switch {
+	case errors.Is(err, model.ErrInvalidInput):
+		return resolverError{err, CodeBadUserInput}
+	case errors.Is(err, model.ErrNotFound):
+		return resolverError{err, CodeNotFound}
+	case errors.Is(err, model.ErrUnprocessable):
+		return resolverError{err, CodeUnprocessable}
+	default:
+		return resolverError{err, CodeInternal}
+	}
+}
+
+// Timestamp exposes the int64 timestamps of the downstream services, which do
+// not fit GraphQL's 32-bit Int
+var Timestamp = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        "Timestamp",
+	Description: "A timestamp as returned by the downstream services, encoded as an integer.",
+	Serialize: func(value interface{}) interface{} {
+		return value
+	},
+	ParseValue: func(value interface{}) interface{} {
+		n, ok := intVariable(value)
+		if !ok {
+			return nil
+		}
+		return int64(n)
+	},
+	ParseLiteral: func(valueAST ast.Value) interface{} {
+		if v, ok := valueAST.(*ast.IntValue); ok {
+			if n, err := strconv.ParseInt(v.Value, 10, 64); err == nil {
+				return n
+			}
+		}
+		return nil
+	},
+})
+
+// NewSchema builds the GraphQL schema over the user and listing services
+func NewSchema(users service.UserService, listings service.ListingService) (graphql.Schema, error) {
+	listingTypeEnum := graphql.NewEnum(graphql.EnumConfig{
+		Name: "ListingType",
+		Values: graphql.EnumValueConfigMap{
+			"SALE": {Value: string(model.ListingTypeSale)},
+			"RENT": {Value: string(model.ListingTypeRent)},
+		},
+	})
+	sortFieldEnum := graphql.NewEnum(graphql.EnumConfig{
+		Name: "ListingSortField",
+		Values: graphql.EnumValueConfigMap{
+			"PRICE":      {Value: model.ListingSortPrice},
+			"CREATED_AT": {Value: model.ListingSortCreatedAt},
+		},
+	})
+	sortOrderEnum := graphql.NewEnum(graphql.EnumConfig{
+		Name: "SortOrder",
+		Values: graphql.EnumValueConfigMap{
+			"ASC":  {Value: model.SortAsc},
+			"DESC": {Value: model.SortDesc},
+		},
+	})
+
+	userType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "User",
+		Fields: graphql.Fields{
+			"id":        userField(graphql.ID, func(u *model.User) interface{} { return u.ID }),
+			"name":      userField(graphql.String, func(u *model.User) interface{} { return u.Name }),
+			"createdAt": userField(Timestamp, func(u *model.User) interface{} { return u.CreatedAt }),
+			"updatedAt": userField(Timestamp, func(u *model.User) interface{} { return u.UpdatedAt }),
+		},
+	})
+
+	listingType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Listing",
+		Fields: graphql.Fields{
+			"id":          listingField(graphql.ID, func(l *model.Listing) interface{} { return l.ID }),
+			"userId":      listingField(graphql.ID, func(l *model.Listing) interface{} { return l.UserID }),
+			"listingType": listingField(listingTypeEnum, func(l *model.Listing) interface{} { return l.ListingType }),
+			"price":       listingField(graphql.Float, func(l *model.Listing) interface{} { return l.Price }),
+			"createdAt":   listingField(Timestamp, func(l *model.Listing) interface{} { return l.CreatedAt }),
+			"updatedAt":   listingField(Timestamp, func(l *model.Listing) interface{} { return l.UpdatedAt }),
+			"user": &graphql.Field{
+				Type:    userType,
+				Resolve: resolveListingUser,
+			},
+		},
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"user": &graphql.Field{
+				Type: userType,
+				Args: graphql.FieldConfigArgument{
+					"id": {Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id, err := parseID(p.Args["id"])
+					if err != nil {
+						return nil, toGraphQLError(err)
+					}
+					user, err := users.GetUserByID(p.Context, id)
+					if err != nil {
+						if errors.Is(err, model.ErrNotFound) {
+							return nil, nil
+						}
+						return nil, toGraphQLError(err)
+					}
+					return user, nil
+				},
+			},
+			"listings": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(listingType))),
+				Args: graphql.FieldConfigArgument{
+					"pageNum":     {Type: graphql.Int, DefaultValue: 1},
+					pageSizeArg:   {Type: graphql.Int, DefaultValue: defaultPageSize},
+					"userId":      {Type: graphql.ID},
+					"listingType": {Type: listingTypeEnum},
+					"minPrice":    {Type: graphql.Float},
+					"maxPrice":    {Type: graphql.Float},
+					"createdFrom": {Type: Timestamp},
+					"createdTo":   {Type: Timestamp},
+					"sortBy":      {Type: sortFieldEnum},
+					"sortOrder":   {Type: sortOrderEnum},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					q, err := listingQuery(p.Args)
+					if err != nil {
+						return nil, toGraphQLError(err)
+					}
+					found, err := listings.FindListings(p.Context, q)
+					if err != nil {
+						return nil, toGraphQLError(err)
+					}
+					out := make([]*model.Listing, len(found))
+					for i := range found {
+						out[i] = &found[i]
+					}
+					return out, nil
+				},
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createUser": &graphql.Field{
+				Type: graphql.NewNonNull(userType),
+				Args: graphql.FieldConfigArgument{
+					"name": {Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					name, _ := p.Args["name"].(string)
+					user, err := users.CreateUser(p.Context, name)
+					if err != nil {
+						return nil, toGraphQLError(err)
+					}
+					return user, nil
+				},
+			},
+			"createListing": &graphql.Field{
+				Type: graphql.NewNonNull(listingType),
+				Args: graphql.FieldConfigArgument{
+					"userId":      {Type: graphql.NewNonNull(graphql.ID)},
+					"listingType": {Type: graphql.NewNonNull(listingTypeEnum)},
+					"price":       {Type: graphql.NewNonNull(graphql.Float)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					userID, err := parseID(p.Args["userId"])
+					if err != nil {
+						return nil, toGraphQLError(err)
+					}
+					l := model.Listing{UserID: userID}
+					l.ListingType, _ = p.Args["listingType"].(string)
+					l.Price, _ = p.Args["price"].(float64)
+
+					created, err := listings.CreateListing(p.Context, l)
+					if err != nil {
+						return nil, toGraphQLError(err)
+					}
+					return created, nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query, Mutation: mutation})
+}
+
+// resolveListingUser returns the embedded owner when the service already
+// attached it and otherwise batches the lookup through the request's loader
+func resolveListingUser(p graphql.ResolveParams) (interface{}, error) {
+	l, ok := p.Source.(*model.Listing)
+	if !ok {
+		return nil, nil
+	}
+	if l.User != nil {
+		return l.User, nil
+	}
+
+	loader, ok := p.Context.Value(loaderKey{}).(*userLoader)
+	if !ok {
+		return nil, fmt.Errorf("graph: no user loader in context")
+	}
+	thunk := loader.Load(l.UserID)
+	return func() (interface{}, error) {
+		user, err := thunk()
+		if err != nil {
+			return nil, toGraphQLError(err)
+		}
+		if user == nil {
+			// A nil *model.User inside interface{} would not read as null
+			return nil, nil
+		}
+		return user, nil
+	}, nil
+}
+
+func userField(t graphql.Output, get func(u *model.User) interface{}) *graphql.Field {
+	return &graphql.Field{
+		Type: graphql.NewNonNull(t),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			u, ok := p.Source.(*model.User)
+			if !ok {
+				return nil, nil
+			}
+			return get(u), nil
+		},
+	}
+}
+
+func listingField(t graphql.Output, get func(l *model.Listing) interface{}) *graphql.Field {
+	return &graphql.Field{
+		Type: graphql.NewNonNull(t),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			l, ok := p.Source.(*model.Listing)
+			if !ok {
+				return nil, nil
+			}
+			return get(l), nil
+		},
+	}
+}
+
+// listingQuery converts the listings field arguments into a ListingQuery
+func listingQuery(args map[string]interface{}) (model.ListingQuery, error) {
+	q := model.ListingQuery{Page: 1, Size: defaultPageSize}
+	if v, ok := args["pageNum"].(int); ok {
+		q.Page = v
+	}
+	if v, ok := args[pageSizeArg].(int); ok {
+		q.Size = v
+	}
+	if raw, ok := args["userId"]; ok && raw != nil {
+		id, err := parseID(raw)
+		if err != nil {
+			return q, err
+		}
+		q.UserID = &id
+	}
+	q.ListingType, _ = args["listingType"].(string)
+	if v, ok := args["minPrice"].(float64); ok {
+		q.MinPrice = &v
+	}
+	if v, ok := args["maxPrice"].(float64); ok {
+		q.MaxPrice = &v
+	}
+	if v, ok := args["createdFrom"].(int64); ok {
+		q.CreatedFrom = &v
+	}
+	if v, ok := args["createdTo"].(int64); ok {
+		q.CreatedTo = &v
+	}
+	q.SortBy, _ = args["sortBy"].(string)
+	q.SortOrder, _ = args["sortOrder"].(string)
+	return q, q.Validate()
+}
+
+// parseID reads a positive int64 from an ID argument
+func parseID(v interface{}) (int64, error) {
+	s, _ := v.(string)
+	id, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("%w: id must be a positive integer", model.ErrInvalidInput)
+	}
+	return id, nil
+}
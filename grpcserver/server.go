@@ -0,0 +1,127 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"public-api/model"
+	"public-api/pb"
+	"public-api/service"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// NewServer returns a gRPC server exposing the user and listing services.
+// Reflection is enabled so tools like grpcurl can discover the API.
+func NewServer(us service.UserService, ls service.ListingService, opts ...grpc.ServerOption) *grpc.Server {
+	s := grpc.NewServer(opts...)
+	pb.RegisterUserServiceServer(s, &userServer{service: us})
+	pb.RegisterListingServiceServer(s, &listingServer{service: ls})
+	reflection.Register(s)
+	return s
+}
+
+// userServer implements pb.UserServiceServer on top of UserService
+type userServer struct {
+	pb.UnimplementedUserServiceServer
+	service service.UserService
+}
+
+// CreateUser creates a user via the user-service
+func (s *userServer) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.CreateUserResponse, error) {
+	user, err := s.service.CreateUser(ctx, req.GetName())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.CreateUserResponse{User: pb.FromUser(user)}, nil
+}
+
+// GetUserByID fetches a single user
+func (s *userServer) GetUserByID(ctx context.Context, req *pb.GetUserByIDRequest) (*pb.GetUserByIDResponse, error) {
+	if req.GetId() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "id must be positive")
+	}
+	user, err := s.service.GetUserByID(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.GetUserByIDResponse{User: pb.FromUser(user)}, nil
+}
+
+// listingServer implements pb.ListingServiceServer on top of ListingService
+type listingServer struct {
+	pb.UnimplementedListingServiceServer
+	service service.ListingService
+}
+
+// CreateListing creates a listing after the service has checked its owner
+func (s *listingServer) CreateListing(ctx context.Context, req *pb.CreateListingRequest) (*pb.CreateListingResponse, error) {
+	created, err := s.service.CreateListing(ctx, model.Listing{
+		UserID:      req.GetUserId(),
+		ListingType: req.GetListingType(),
+		Price:       req.GetPrice(),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.CreateListingResponse{Listing: pb.FromListing(created)}, nil
+}
+
+// GetListings returns a page of listings with their owners
+func (s *listingServer) GetListings(ctx context.Context, req *pb.GetListingsRequest) (*pb.GetListingsResponse, error) {
+	q := listingQuery(req)
+	if err := q.Validate(); err != nil {
+		return nil, toStatus(err)
+	}
+
+	listings, err := s.service.GetListings(ctx, q)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return pb.FromListings(listings), nil
+}
+
+// listingQuery converts a request into a ListingQuery, applying the same
+// page defaults as the REST API
+func listingQuery(req *pb.GetListingsRequest) model.ListingQuery {
+	q := model.ListingQuery{
+		Page:        int(req.GetPageNum()),
+		Size:        int(req.GetPageSize()),
+		UserID:      req.UserId,
+		ListingType: req.GetListingType(),
+		MinPrice:    req.MinPrice,
+		MaxPrice:    req.MaxPrice,
+		CreatedFrom: req.CreatedFrom,
+		CreatedTo:   req.CreatedTo,
+		SortBy:      req.GetSortBy(),
+		SortOrder:   req.GetSortOrder(),
+	}
+	if q.Page == 0 {
+		q.Page = 1
+	}
+	if q.Size == 0 {
+		q.Size = 10
+	}
+	return q
+}
+
+// toStatus maps service errors onto gRPC status codes
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, model.ErrInvalidInput):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, model.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, model.ErrUnprocessable):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
@@ -0,0 +1,178 @@
+package grpcserver_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"public-api/grpcserver"
+	"public-api/mocks"
+	"public-api/model"
+	"public-api/pb"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+// dial starts the server on an in-process listener and returns a connection to it
+func dial(t *testing.T, us *mocks.MockUserService, ls *mocks.MockListingService) *grpc.ClientConn {
+	lis := bufconn.Listen(1024 * 1024)
+	srv := grpcserver.NewServer(us, ls)
+	go func() {
+		_ = srv.Serve(lis)
+	}()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestUserService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	us := mocks.NewMockUserService(ctrl)
+	client := pb.NewUserServiceClient(dial(t, us, mocks.NewMockListingService(ctrl)))
+
+	t.Run("create user", func(t *testing.T) {
+		us.EXPECT().CreateUser(gomock.Any(), "John").Return(&model.User{ID: 1, Name: "John", CreatedAt: 10, UpdatedAt: 10}, nil)
+
+		resp, err := client.CreateUser(context.Background(), &pb.CreateUserRequest{Name: "John"})
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(&pb.User{Id: 1, Name: "John", CreatedAt: 10, UpdatedAt: 10}, resp.GetUser()))
+	})
+
+	tests := []struct {
+		name         string
+		id           int64
+		mock         func()
+		expectedCode codes.Code
+	}{
+		{
+			name: "success",
+			id:   1,
+			mock: func() {
+				us.EXPECT().GetUserByID(gomock.Any(), int64(1)).Return(&model.User{ID: 1, Name: "John"}, nil)
+			},
+			expectedCode: codes.OK,
+		},
+		{
+			name:         "invalid id",
+			id:           0,
+			mock:         func() {},
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name: "not found",
+			id:   2,
+			mock: func() {
+				us.EXPECT().GetUserByID(gomock.Any(), int64(2)).Return(nil, fmt.Errorf("user 2: %w", model.ErrNotFound))
+			},
+			expectedCode: codes.NotFound,
+		},
+		{
+			name: "downstream failure",
+			id:   3,
+			mock: func() {
+				us.EXPECT().GetUserByID(gomock.Any(), int64(3)).Return(nil, errors.New("boom"))
+			},
+			expectedCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run("get user/"+tt.name, func(t *testing.T) {
+			tt.mock()
+
+			resp, err := client.GetUserByID(context.Background(), &pb.GetUserByIDRequest{Id: tt.id})
+
+			assert.Equal(t, tt.expectedCode, status.Code(err))
+			if tt.expectedCode == codes.OK {
+				assert.Equal(t, tt.id, resp.GetUser().GetId())
+			}
+		})
+	}
+}
+
+func TestListingService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ls := mocks.NewMockListingService(ctrl)
+	client := pb.NewListingServiceClient(dial(t, mocks.NewMockUserService(ctrl), ls))
+
+	createTests := []struct {
+		name         string
+		mock         func()
+		expectedCode codes.Code
+	}{
+		{
+			name: "success",
+			mock: func() {
+				ls.EXPECT().
+					CreateListing(gomock.Any(), model.Listing{UserID: 1, ListingType: "rent", Price: 99.5}).
+					Return(&model.Listing{ID: 5, UserID: 1, ListingType: "rent", Price: 99.5, User: &model.User{ID: 1, Name: "John"}}, nil)
+			},
+			expectedCode: codes.OK,
+		},
+		{
+			name: "invalid input",
+			mock: func() {
+				ls.EXPECT().CreateListing(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("%w: bad price", model.ErrInvalidInput))
+			},
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name: "unknown owner",
+			mock: func() {
+				ls.EXPECT().CreateListing(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("%w: user 1 does not exist", model.ErrUnprocessable))
+			},
+			expectedCode: codes.FailedPrecondition,
+		},
+	}
+
+	for _, tt := range createTests {
+		t.Run("create listing/"+tt.name, func(t *testing.T) {
+			tt.mock()
+
+			resp, err := client.CreateListing(context.Background(), &pb.CreateListingRequest{UserId: 1, ListingType: "rent", Price: 99.5})
+
+			assert.Equal(t, tt.expectedCode, status.Code(err))
+			if tt.expectedCode == codes.OK {
+				assert.Equal(t, int64(5), resp.GetListing().GetId())
+				assert.Equal(t, "John", resp.GetListing().GetUser().GetName())
+			}
+		})
+	}
+
+	t.Run("get listings applies defaults and filters", func(t *testing.T) {
+		minPrice := 50.0
+		ls.EXPECT().
+			GetListings(gomock.Any(), model.ListingQuery{Page: 1, Size: 10, MinPrice: &minPrice, SortBy: "price"}).
+			Return([]model.Listing{{ID: 1, Price: 60}, {ID: 2, Price: 70}}, nil)
+
+		resp, err := client.GetListings(context.Background(), &pb.GetListingsRequest{MinPrice: proto.Float64(50), SortBy: "price"})
+		require.NoError(t, err)
+		require.Len(t, resp.GetListings(), 2)
+		assert.Equal(t, 70.0, resp.GetListings()[1].GetPrice())
+	})
+
+	t.Run("get listings rejects invalid query", func(t *testing.T) {
+		_, err := client.GetListings(context.Background(), &pb.GetListingsRequest{SortOrder: "sideways"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
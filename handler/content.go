@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"public-api/content"
+	"public-api/model"
+	"public-api/pb"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/ugorji/go/codec"
+	"google.golang.org/protobuf/proto"
+)
+
+// msgpackHandle writes strings with the str types of the current
+// MessagePack spec rather than the raw type of the old one
+var msgpackHandle = &codec.MsgpackHandle{WriteExt: true}
+
+// respond writes a successful response in the media type negotiated by
+// content.Negotiate. JSON and MessagePack carry the versioned body from the
+// mapper; Protobuf carries msg, which is the same in every version. Errors
+// are always JSON.
+func respond(c *gin.Context, code int, body interface{}, msg proto.Message) {
+	contentType, data, err := encodeBody(c, body, msg)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Data(code, contentType, data)
+}
+
+// encodeBody encodes a response like respond without writing it
+func encodeBody(c *gin.Context, body interface{}, msg proto.Message) (string, []byte, error) {
+	switch content.Response(c) {
+	case content.MsgPack:
+		var data []byte
+		err := codec.NewEncoderBytes(&data, msgpackHandle).Encode(body)
+		return content.MsgPack, data, err
+	case content.Protobuf:
+		data, err := proto.Marshal(msg)
+		return content.Protobuf, data, err
+	default:
+		data, err := json.Marshal(body)
+		return "application/json; charset=utf-8", data, err
+	}
+}
+
+// bindBody decodes the request body in the media type negotiated by
+// content.Negotiate and validates it like ShouldBindJSON
+func bindBody(c *gin.Context, req interface{}) error {
+	switch content.Request(c) {
+	case content.MsgPack:
+		if err := codec.NewDecoder(c.Request.Body, msgpackHandle).Decode(req); err != nil {
+			return err
+		}
+	case content.Protobuf:
+		if err := decodeProto(c.Request.Body, req); err != nil {
+			return err
+		}
+	default:
+		return c.ShouldBindJSON(req)
+	}
+	return binding.Validator.ValidateStruct(req)
+}
+
+// decodeProto reads the message of package pb matching req and copies it
+// into req
+func decodeProto(r io.Reader, req interface{}) error {
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	switch req := req.(type) {
+	case *model.CreateUserRequest:
+		var m pb.CreateUserRequest
+		if err := proto.Unmarshal(body, &m); err != nil {
+			return err
+		}
+		req.Name = m.GetName()
+	case *model.UpdateUserRequest:
+		var m pb.UpdateUserRequest
+		if err := proto.Unmarshal(body, &m); err != nil {
+			return err
+		}
+		req.Name = m.GetName()
+	case *model.CreateListingRequest:
+		var m pb.CreateListingRequest
+		if err := proto.Unmarshal(body, &m); err != nil {
+			return err
+		}
+		req.UserID = m.GetUserId()
+		req.ListingType = model.ListingType(m.GetListingType())
+		req.Price = m.GetPrice()
+	default:
+		return fmt.Errorf("no Protobuf message for %T", req)
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+package handler_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"public-api/apiversion"
+	"public-api/content"
+	"public-api/handler"
+	"public-api/middleware"
+	"public-api/mocks"
+	"public-api/model"
+	"public-api/pb"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ugorji/go/codec"
+	"google.golang.org/protobuf/proto"
+)
+
+func encodingRouter(us *mocks.MockUserService, ls *mocks.MockListingService) *gin.Engine {
+	formats := content.Negotiate(content.JSON, content.MsgPack, content.Protobuf)
+	uh, lh := handler.NewUserHandler(us), handler.NewListingHandler(ls)
+
+	router := gin.Default()
+	router.Use(middleware.ETag())
+	api := router.Group("/public-api", apiversion.Fixed(apiversion.V2))
+	api.POST("/users", formats, uh.CreateUser)
+	api.POST("/listings", formats, lh.CreateListing)
+	api.GET("/listings", formats, lh.GetListings)
+	api.GET("/listings/:id", formats, lh.GetListingByID)
+	return router
+}
+
+func msgpack(t *testing.T, v interface{}) []byte {
+	var b []byte
+	require.NoError(t, codec.NewEncoderBytes(&b, new(codec.MsgpackHandle)).Encode(v))
+	return b
+}
+
+func TestEncodings_MsgPack(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	us := mocks.NewMockUserService(ctrl)
+	router := encodingRouter(us, mocks.NewMockListingService(ctrl))
+
+	us.EXPECT().CreateUser(gomock.Any(), "Jane").Return(&model.User{ID: 7, Name: "Jane"}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/public-api/users", bytes.NewReader(msgpack(t, map[string]string{"name": "Jane"})))
+	req.Header.Set("Content-Type", "application/x-msgpack")
+	req.Header.Set("Accept", "application/msgpack")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	require.Equal(t, http.StatusCreated, resp.Code, resp.Body.String())
+	assert.Equal(t, "application/msgpack", resp.Header().Get("Content-Type"))
+	var got model.UserEnvelope
+	require.NoError(t, codec.NewDecoderBytes(resp.Body.Bytes(), new(codec.MsgpackHandle)).Decode(&got))
+	assert.Equal(t, &model.User{ID: 7, Name: "Jane"}, got.Data)
+}
+
+func TestEncodings_Protobuf(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ls := mocks.NewMockListingService(ctrl)
+	router := encodingRouter(mocks.NewMockUserService(ctrl), ls)
+
+	ls.EXPECT().
+		CreateListing(gomock.Any(), model.Listing{UserID: 5, ListingType: "rent", Price: 1500}).
+		Return(&model.Listing{ID: 1, UserID: 5, ListingType: "rent", Price: 1500}, nil)
+	ls.EXPECT().
+		GetListings(gomock.Any(), gomock.Any()).
+		Return([]model.Listing{{ID: 1, UserID: 5, User: &model.User{ID: 5, Name: "Jane"}}}, nil)
+
+	body, err := proto.Marshal(&pb.CreateListingRequest{UserId: 5, ListingType: "rent", Price: 1500})
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/public-api/listings", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Accept", "application/x-protobuf")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	require.Equal(t, http.StatusCreated, resp.Code, resp.Body.String())
+	assert.Equal(t, "application/x-protobuf", resp.Header().Get("Content-Type"))
+	var created pb.Listing
+	require.NoError(t, proto.Unmarshal(resp.Body.Bytes(), &created))
+	assert.True(t, proto.Equal(&pb.Listing{Id: 1, UserId: 5, ListingType: "rent", Price: 1500}, &created))
+
+	req = httptest.NewRequest(http.MethodGet, "/public-api/listings", nil)
+	req.Header.Set("Accept", "application/protobuf")
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	var page pb.GetListingsResponse
+	require.NoError(t, proto.Unmarshal(resp.Body.Bytes(), &page))
+	require.Len(t, page.GetListings(), 1)
+	assert.Equal(t, "Jane", page.GetListings()[0].GetUser().GetName())
+}
+
+func TestEncodings_ProtobufFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ls := mocks.NewMockListingService(ctrl)
+	router := encodingRouter(mocks.NewMockUserService(ctrl), ls)
+
+	ls.EXPECT().
+		GetListings(gomock.Any(), gomock.Any()).
+		Return([]model.Listing{{ID: 1, UserID: 5, Price: 1500, User: &model.User{ID: 5, Name: "Jane", CreatedAt: 3}}}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/public-api/listings?fields=price,user.name", nil)
+	req.Header.Set("Accept", "application/x-protobuf")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	var page pb.GetListingsResponse
+	require.NoError(t, proto.Unmarshal(resp.Body.Bytes(), &page))
+	require.Len(t, page.GetListings(), 1)
+	assert.True(t, proto.Equal(&pb.Listing{Id: 1, Price: 1500, User: &pb.User{Id: 5, Name: "Jane"}}, page.GetListings()[0]))
+}
+
+func TestEncodings_Errors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name         string
+		req          func(t *testing.T) *http.Request
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name: "invalid msgpack body is validated",
+			req: func(t *testing.T) *http.Request {
+				req := httptest.NewRequest(http.MethodPost, "/public-api/listings", bytes.NewReader(msgpack(t, map[string]interface{}{"user_id": 5, "listing_type": "castle", "price": 10})))
+				req.Header.Set("Content-Type", "application/msgpack")
+				return req
+			},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"field":"listing_type","code":"listing_type"`,
+		},
+		{
+			name: "malformed protobuf body",
+			req: func(t *testing.T) *http.Request {
+				req := httptest.NewRequest(http.MethodPost, "/public-api/users", bytes.NewReader([]byte{0xff, 0xff}))
+				req.Header.Set("Content-Type", "application/x-protobuf")
+				return req
+			},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `"error":"Invalid request"`,
+		},
+		{
+			name: "unsupported accept",
+			req: func(t *testing.T) *http.Request {
+				req := httptest.NewRequest(http.MethodGet, "/public-api/listings", nil)
+				req.Header.Set("Accept", "application/xml")
+				return req
+			},
+			expectedCode: http.StatusNotAcceptable,
+			expectedBody: `Unsupported media type`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			router := encodingRouter(mocks.NewMockUserService(ctrl), mocks.NewMockListingService(ctrl))
+
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, tt.req(t))
+
+			assert.Equal(t, tt.expectedCode, resp.Code)
+			assert.Equal(t, "application/json; charset=utf-8", resp.Header().Get("Content-Type"))
+			assert.Contains(t, resp.Body.String(), tt.expectedBody)
+		})
+	}
+}
+
+func TestEncodings_ETagPerFormat(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ls := mocks.NewMockListingService(ctrl)
+	router := encodingRouter(mocks.NewMockUserService(ctrl), ls)
+
+	ls.EXPECT().GetListingByID(gomock.Any(), int64(1)).Return(&model.Listing{ID: 1, ListingType: "rent"}, nil).Times(3)
+
+	get := func(accept, match string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/public-api/listings/1", nil)
+		req.Header.Set("Accept", accept)
+		req.Header.Set("If-None-Match", match)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	jsonTag := get("application/json", "").Header().Get("ETag")
+	resp := get("application/msgpack", jsonTag)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.NotEqual(t, jsonTag, resp.Header().Get("ETag"))
+	assert.Equal(t, http.StatusNotModified, get("application/msgpack", resp.Header().Get("ETag")).Code)
+}
@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"net/http"
+	"public-api/graph"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GraphQLHandler serves the GraphQL endpoint
+type GraphQLHandler struct {
+	executor *graph.Executor
+}
+
+// NewGraphQLHandler constructs a new GraphQLHandler
+func NewGraphQLHandler(e *graph.Executor) *GraphQLHandler {
+	return &GraphQLHandler{executor: e}
+}
+
+// Query handles POST /graphql. Query errors are reported in the response
+// body with a 200, as GraphQL clients expect; only unreadable requests get
+// a 400.
+func (h *GraphQLHandler) Query(c *gin.Context) {
+	var req graph.QueryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, invalidRequest(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, h.executor.Execute(c.Request.Context(), req))
+}
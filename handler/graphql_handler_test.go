@@ -0,0 +1,85 @@
+package handler_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"public-api/graph"
+	"public-api/handler"
+	"public-api/mocks"
+	"public-api/model"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraphQLHandler_Query(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockUserService(ctrl)
+	executor, err := graph.NewExecutor(mockUserService, mocks.NewMockListingService(ctrl))
+	require.NoError(t, err)
+	handler := handler.NewGraphQLHandler(executor)
+
+	tests := []struct {
+		name           string
+		requestBody    string
+		mockSetup      func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:        "success",
+			requestBody: `{"query":"query($id: ID!) { user(id: $id) { name } }","variables":{"id":"3"}}`,
+			mockSetup: func() {
+				mockUserService.EXPECT().
+					GetUserByID(gomock.Any(), int64(3)).
+					Return(&model.User{ID: 3, Name: "John Doe"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"data":{"user":{"name":"John Doe"}}}`,
+		},
+		{
+			name:           "query error is reported with 200",
+			requestBody:    `{"query":"{ user { name } }"}`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"errors":[{"message":"Field \"user\" argument \"id\" of type \"ID!\" is required but not provided."`,
+		},
+		{
+			name:           "missing query",
+			requestBody:    `{"variables":{}}`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"field":"query","code":"required","message":"is required"}`,
+		},
+		{
+			name:           "invalid JSON",
+			requestBody:    `{invalid}`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `Invalid request`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest(http.MethodPost, "/graphql", bytes.NewBufferString(tt.requestBody))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			handler.Query(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"public-api/importer"
+	"public-api/model"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// Columns a listing CSV must have. Other columns are ignored.
+var importColumns = []string{"user_id", "listing_type", "price"}
+
+// ImportHandler handles bulk listing imports from CSV files
+type ImportHandler struct {
+	importer *importer.Importer
+	maxBytes int64
+}
+
+// NewImportHandler constructs a new ImportHandler accepting uploads of up
+// to maxBytes
+func NewImportHandler(im *importer.Importer, maxBytes int64) *ImportHandler {
+	return &ImportHandler{importer: im, maxBytes: maxBytes}
+}
+
+// ImportListings handles POST /public-api/listings/import
+func (h *ImportHandler) ImportListings(c *gin.Context) {
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "dry_run must be true or false"})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxBytes)
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("File must not exceed %d bytes", h.maxBytes)})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A CSV file is required in the file field"})
+		return
+	}
+	f, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer f.Close()
+
+	rows, invalid, err := parseListingCSV(f)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	job, err := h.importer.Submit(c.Request.Context(), rows, invalid, dryRun)
+	if err != nil {
+		if errors.Is(err, importer.ErrTooManyJobs) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Too many imports in progress, retry later"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if dryRun {
+		c.JSON(http.StatusOK, mapperFor(c).ImportJob(&job))
+		return
+	}
+	c.Header("Location", fmt.Sprintf("%s/%d", c.Request.URL.Path, job.ID))
+	c.JSON(http.StatusAccepted, mapperFor(c).ImportJob(&job))
+}
+
+// GetImport handles GET /public-api/listings/import/:id
+func (h *ImportHandler) GetImport(c *gin.Context) {
+	id, ok := pathID(c, "Invalid import id")
+	if !ok {
+		return
+	}
+	job, err := h.importer.Get(id)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Import not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, mapperFor(c).ImportJob(job))
+}
+
+// parseListingCSV reads listings from CSV with a header row. Rows are
+// validated like CreateListingRequest; invalid rows are reported by line
+// rather than failing the file. Blank rows are skipped.
+func parseListingCSV(r io.Reader) ([]importer.Row, []model.ImportRowError, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	head, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil, fmt.Errorf("%w: file is empty", model.ErrInvalidInput)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", model.ErrInvalidInput, err)
+	}
+	cols := make(map[string]int, len(head))
+	for i, name := range head {
+		if i == 0 {
+			// Spreadsheet exports often start with a byte order mark
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		cols[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range importColumns {
+		if _, ok := cols[name]; !ok {
+			return nil, nil, fmt.Errorf("%w: missing column %s", model.ErrInvalidInput, name)
+		}
+	}
+
+	var (
+		rows    []importer.Row
+		invalid []model.ImportRowError
+		count   int
+	)
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", model.ErrInvalidInput, err)
+		}
+		if blank(record) {
+			continue
+		}
+		if count++; count > model.MaxImportRows {
+			return nil, nil, fmt.Errorf("%w: file must not have more than %d rows", model.ErrInvalidInput, model.MaxImportRows)
+		}
+
+		line, _ := cr.FieldPos(0)
+		field := func(name string) string {
+			if i := cols[name]; i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		req, errs := parseListingRow(field)
+		if len(errs) > 0 {
+			for _, fe := range errs {
+				invalid = append(invalid, model.ImportRowError{Line: line, Field: fe.Field, Code: fe.Code, Message: fe.Message})
+			}
+			continue
+		}
+		rows = append(rows, importer.Row{Line: line, Listing: model.Listing{
+			UserID:      req.UserID,
+			ListingType: string(req.ListingType),
+			Price:       req.Price,
+		}})
+	}
+	return rows, invalid, nil
+}
+
+// parseListingRow converts the cells of a row and validates them with the
+// same rules and messages as a JSON request
+func parseListingRow(field func(name string) string) (model.CreateListingRequest, []model.FieldError) {
+	var (
+		req  = model.CreateListingRequest{ListingType: model.ListingType(field("listing_type"))}
+		errs []model.FieldError
+		bad  = make(map[string]bool)
+	)
+	if raw := field("user_id"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			errs = append(errs, model.FieldError{Field: "user_id", Code: codeType, Message: "must be of type int64"})
+			bad["user_id"] = true
+		}
+		req.UserID = id
+	}
+	if raw := field("price"); raw != "" {
+		price, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			errs = append(errs, model.FieldError{Field: "price", Code: codeType, Message: "must be of type float64"})
+			bad["price"] = true
+		}
+		req.Price = price
+	}
+
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		for _, fe := range fieldErrors(err) {
+			if !bad[fe.Field] {
+				errs = append(errs, fe)
+			}
+		}
+	}
+	return req, errs
+}
+
+func blank(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
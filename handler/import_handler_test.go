@@ -0,0 +1,219 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"public-api/handler"
+	"public-api/importer"
+	"public-api/mocks"
+	"public-api/model"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func importRouter(ls *mocks.MockListingService, maxBytes int64) *gin.Engine {
+	h := handler.NewImportHandler(importer.New(ls), maxBytes)
+	router := gin.Default()
+	router.POST("/public-api/listings/import", h.ImportListings)
+	router.GET("/public-api/listings/import/:id", h.GetImport)
+	return router
+}
+
+func uploadRequest(t *testing.T, target, field, content string) *http.Request {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreateFormFile(field, "listings.csv")
+	require.NoError(t, err)
+	_, err = part.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	req := httptest.NewRequest(http.MethodPost, target, &body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	return req
+}
+
+func decodeImport(t *testing.T, body []byte) model.ImportJob {
+	var resp struct {
+		Import model.ImportJob `json:"import"`
+	}
+	require.NoError(t, json.Unmarshal(body, &resp))
+	return resp.Import
+}
+
+func TestImportHandler_DryRun(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	router := importRouter(mocks.NewMockListingService(ctrl), 1<<20)
+
+	csv := "\ufeffUser_ID,listing_type,price,notes\n" +
+		"1,sale,100,\"two\nlines\"\n" +
+		",,,\n" +
+		"abc,castle,100,\n" +
+		"2,rent,-5\n" +
+		"3, rent ,1500.50,ok\n"
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, uploadRequest(t, "/public-api/listings/import?dry_run=true", "file", csv))
+
+	require.Equal(t, http.StatusOK, resp.Code)
+	job := decodeImport(t, resp.Body.Bytes())
+	assert.Equal(t, model.ImportCompleted, job.Status)
+	assert.Equal(t, 4, job.Total)
+	assert.Equal(t, 2, job.Valid)
+	assert.Equal(t, 2, job.Failed)
+	assert.Equal(t, []model.ImportRowError{
+		{Line: 5, Field: "user_id", Code: "type", Message: "must be of type int64"},
+		{Line: 5, Field: "listing_type", Code: "listing_type", Message: "must be one of: sale, rent"},
+		{Line: 6, Field: "price", Code: "listing_price", Message: "price must be greater than 0"},
+	}, job.Errors)
+}
+
+func TestImportHandler_Import(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ls := mocks.NewMockListingService(ctrl)
+	router := importRouter(ls, 1<<20)
+
+	ls.EXPECT().
+		CreateListings(gomock.Any(), []model.Listing{{UserID: 1, ListingType: "sale", Price: 100}}).
+		Return([]*model.Listing{{ID: 9}}, []error{nil}, nil)
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, uploadRequest(t, "/public-api/listings/import", "file", "user_id,listing_type,price\n1,sale,100\n"))
+	require.Equal(t, http.StatusAccepted, resp.Code)
+	job := decodeImport(t, resp.Body.Bytes())
+	assert.Equal(t, "/public-api/listings/import/1", resp.Header().Get("Location"))
+
+	require.Eventually(t, func() bool {
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/public-api/listings/import/1", nil))
+		job = decodeImport(t, resp.Body.Bytes())
+		return job.Status == model.ImportCompleted
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, 1, job.Created)
+	assert.Empty(t, job.Errors)
+}
+
+func TestImportHandler_TooManyImports(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ls := mocks.NewMockListingService(ctrl)
+	h := handler.NewImportHandler(importer.New(ls, importer.WithMaxActive(1)), 1<<20)
+	router := gin.Default()
+	router.POST("/public-api/listings/import", h.ImportListings)
+
+	started, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+	ls.EXPECT().CreateListings(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(context.Context, []model.Listing) ([]*model.Listing, []error, error) {
+			close(started)
+			<-release
+			return []*model.Listing{{ID: 9}}, []error{nil}, nil
+		})
+
+	csv := "user_id,listing_type,price\n1,sale,100\n"
+	first := httptest.NewRecorder()
+	router.ServeHTTP(first, uploadRequest(t, "/public-api/listings/import", "file", csv))
+	require.Equal(t, http.StatusAccepted, first.Code)
+	<-started
+
+	second := httptest.NewRecorder()
+	router.ServeHTTP(second, uploadRequest(t, "/public-api/listings/import", "file", csv))
+	assert.Equal(t, http.StatusServiceUnavailable, second.Code)
+	assert.JSONEq(t, `{"error":"Too many imports in progress, retry later"}`, second.Body.String())
+}
+
+func TestImportHandler_Errors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name         string
+		req          func(t *testing.T) *http.Request
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name: "missing column",
+			req: func(t *testing.T) *http.Request {
+				return uploadRequest(t, "/public-api/listings/import", "file", "user_id,price\n1,100\n")
+			},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `missing column listing_type`,
+		},
+		{
+			name: "empty file",
+			req: func(t *testing.T) *http.Request {
+				return uploadRequest(t, "/public-api/listings/import", "file", "")
+			},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `file is empty`,
+		},
+		{
+			name: "malformed csv",
+			req: func(t *testing.T) *http.Request {
+				return uploadRequest(t, "/public-api/listings/import", "file", "user_id,listing_type,price\n1,\"sale,100\n")
+			},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `extraneous or missing \" in quoted-field`,
+		},
+		{
+			name: "wrong field",
+			req: func(t *testing.T) *http.Request {
+				return uploadRequest(t, "/public-api/listings/import", "csv", "user_id,listing_type,price\n")
+			},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `A CSV file is required in the file field`,
+		},
+		{
+			name: "too large",
+			req: func(t *testing.T) *http.Request {
+				return uploadRequest(t, "/public-api/listings/import", "file", "user_id,listing_type,price\n"+strings.Repeat("1,sale,100\n", 200))
+			},
+			expectedCode: http.StatusRequestEntityTooLarge,
+			expectedBody: `File must not exceed 1024 bytes`,
+		},
+		{
+			name: "invalid dry_run",
+			req: func(t *testing.T) *http.Request {
+				return uploadRequest(t, "/public-api/listings/import?dry_run=maybe", "file", "user_id,listing_type,price\n")
+			},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `dry_run must be true or false`,
+		},
+		{
+			name: "unknown import",
+			req: func(t *testing.T) *http.Request {
+				return httptest.NewRequest(http.MethodGet, "/public-api/listings/import/42", nil)
+			},
+			expectedCode: http.StatusNotFound,
+			expectedBody: `Import not found`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			router := importRouter(mocks.NewMockListingService(ctrl), 1024)
+
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, tt.req(t))
+
+			assert.Equal(t, tt.expectedCode, resp.Code)
+			assert.Contains(t, resp.Body.String(), tt.expectedBody)
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"public-api/model"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Export formats
+const (
+	exportCSV    = "csv"
+	exportNDJSON = "ndjson"
+)
+
+// exportErrorTrailer is the HTTP trailer set when an export fails after
+// the download started
+const exportErrorTrailer = "X-Export-Error"
+
+// exportColumns is the header row of CSV exports
+var exportColumns = []string{"id", "user_id", "user_name", "listing_type", "price", "created_at", "updated_at"}
+
+// ExportListings handles GET /public-api/listings/export. Listings are
+// written page by page as they arrive from the listing-service. Once the
+// first page was sent the status can no longer change, so a later failure
+// is reported in the X-Export-Error trailer; NDJSON exports also end with
+// an error line.
+func (h *ListingHandler) ExportListings(c *gin.Context) {
+	format := c.DefaultQuery("format", exportCSV)
+	if format != exportCSV && format != exportNDJSON {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be one of csv, ndjson"})
+		return
+	}
+	q, err := parseListingQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var w listingWriter
+	started := false
+	start := func() error {
+		started = true
+		w = newListingWriter(format, c.Writer)
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="listings-%s.%s"`, time.Now().UTC().Format("20060102T150405Z"), format))
+		c.Header("Trailer", exportErrorTrailer)
+		c.Status(http.StatusOK)
+		return w.Begin()
+	}
+
+	ctx := c.Request.Context()
+	err = h.service.ExportListings(ctx, q, func(page []model.Listing) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		for _, l := range page {
+			if err := w.Write(l); err != nil {
+				return err
+			}
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		c.Writer.Flush()
+		return nil
+	})
+
+	switch {
+	case errors.Is(err, context.Canceled) || ctx.Err() != nil:
+		// The client went away; there is no one to answer
+	case err != nil && !started:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	case err != nil:
+		log.Println("listing export interrupted", err)
+		w.Fail(err)
+		c.Writer.Header().Set(exportErrorTrailer, "export interrupted: "+err.Error())
+	case !started:
+		// Nothing matched; still send a well-formed empty file
+		if err := start(); err == nil {
+			_ = w.Flush()
+		}
+	}
+}
+
+// listingWriter encodes exported listings in one format
+type listingWriter interface {
+	Begin() error
+	Write(l model.Listing) error
+	Flush() error
+	Fail(err error)
+}
+
+func newListingWriter(format string, w gin.ResponseWriter) listingWriter {
+	if format == exportNDJSON {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		bw := bufio.NewWriter(w)
+		return &ndjsonListingWriter{w: bw, enc: json.NewEncoder(bw)}
+	}
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	return &csvListingWriter{w: csv.NewWriter(w)}
+}
+
+// csvListingWriter writes one row per listing with the owner's name inlined.
+// Text cells starting like a formula are prefixed with a quote so
+// spreadsheets do not evaluate them.
+type csvListingWriter struct {
+	w *csv.Writer
+}
+
+// csvFormulaPrefixes start cells spreadsheets treat as formulas
+const csvFormulaPrefixes = "=+-@\t\r"
+
+// csvText escapes a text cell that would be read as a formula
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune(csvFormulaPrefixes, rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+func (cw *csvListingWriter) Begin() error {
+	return cw.w.Write(exportColumns)
+}
+
+func (cw *csvListingWriter) Write(l model.Listing) error {
+	userName := ""
+	if l.User != nil {
+		userName = l.User.Name
+	}
+	return cw.w.Write([]string{
+		strconv.FormatInt(l.ID, 10),
+		strconv.FormatInt(l.UserID, 10),
+		csvText(userName),
+		csvText(l.ListingType),
+		model.FormatPrice(l.Price),
+		strconv.FormatInt(l.CreatedAt, 10),
+		strconv.FormatInt(l.UpdatedAt, 10),
+	})
+}
+
+func (cw *csvListingWriter) Flush() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// Fail leaves the file truncated; CSV has no way to mark an error, so only
+// the trailer tells
+func (cw *csvListingWriter) Fail(error) {}
+
+// ndjsonListingWriter writes one JSON listing per line
+type ndjsonListingWriter struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+func (nw *ndjsonListingWriter) Begin() error {
+	return nil
+}
+
+func (nw *ndjsonListingWriter) Write(l model.Listing) error {
+	return nw.enc.Encode(l)
+}
+
+func (nw *ndjsonListingWriter) Flush() error {
+	return nw.w.Flush()
+}
+
+// Fail ends the export with an error line, which has no listing fields
+func (nw *ndjsonListingWriter) Fail(err error) {
+	_ = nw.enc.Encode(model.ErrorResponse{Error: "export interrupted: " + err.Error()})
+	_ = nw.w.Flush()
+}
@@ -0,0 +1,193 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"public-api/handler"
+	"public-api/mocks"
+	"public-api/model"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func exportRouter(ls *mocks.MockListingService) *gin.Engine {
+	router := gin.Default()
+	router.GET("/public-api/listings/export", handler.NewListingHandler(ls).ExportListings)
+	return router
+}
+
+// exportPages makes ExportListings emit the given pages and then return err
+func exportPages(err error, pages ...[]model.Listing) func(context.Context, model.ListingQuery, func([]model.Listing) error) error {
+	return func(_ context.Context, _ model.ListingQuery, emit func([]model.Listing) error) error {
+		for _, p := range pages {
+			if err := emit(p); err != nil {
+				return err
+			}
+		}
+		return err
+	}
+}
+
+func TestListingHandler_ExportListings(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	pages := [][]model.Listing{
+		{{ID: 1, UserID: 5, ListingType: "rent", Price: 1500.5, CreatedAt: 10, UpdatedAt: 11, User: &model.User{ID: 5, Name: "Doe, Jane"}}},
+		{{ID: 2, UserID: 6, ListingType: "sale", Price: 100, CreatedAt: 20, UpdatedAt: 21}},
+	}
+
+	tests := []struct {
+		name          string
+		query         string
+		export        func(context.Context, model.ListingQuery, func([]model.Listing) error) error
+		expectedType  string
+		expectedFile  string
+		expectedBody  string
+		expectedError string
+	}{
+		{
+			name:         "csv by default",
+			query:        "",
+			export:       exportPages(nil, pages...),
+			expectedType: "text/csv; charset=utf-8",
+			expectedFile: ".csv\"",
+			expectedBody: "id,user_id,user_name,listing_type,price,created_at,updated_at\n" +
+				"1,5,\"Doe, Jane\",rent,1500.5,10,11\n" +
+				"2,6,,sale,100,20,21\n",
+		},
+		{
+			name:         "ndjson",
+			query:        "?format=ndjson",
+			export:       exportPages(nil, pages...),
+			expectedType: "application/x-ndjson",
+			expectedFile: ".ndjson\"",
+			expectedBody: `{"id":1,"user_id":5,"listing_type":"rent","price":1500.5,"created_at":10,"updated_at":11,"user":{"id":5,"name":"Doe, Jane","created_at":0,"updated_at":0}}` + "\n" +
+				`{"id":2,"user_id":6,"listing_type":"sale","price":100,"created_at":20,"updated_at":21}` + "\n",
+		},
+		{
+			name:         "nothing matched",
+			query:        "?format=csv",
+			export:       exportPages(nil),
+			expectedType: "text/csv; charset=utf-8",
+			expectedFile: ".csv\"",
+			expectedBody: "id,user_id,user_name,listing_type,price,created_at,updated_at\n",
+		},
+		{
+			name:         "csv interrupted",
+			query:        "?format=csv",
+			export:       exportPages(errors.New("listing error"), pages[0]),
+			expectedType: "text/csv; charset=utf-8",
+			expectedFile: ".csv\"",
+			expectedBody: "id,user_id,user_name,listing_type,price,created_at,updated_at\n" +
+				"1,5,\"Doe, Jane\",rent,1500.5,10,11\n",
+			expectedError: "export interrupted: listing error",
+		},
+		{
+			name:  "csv formulas escaped",
+			query: "?format=csv",
+			export: exportPages(nil, []model.Listing{
+				{ID: 1, UserID: 5, ListingType: "rent", Price: 1, User: &model.User{ID: 5, Name: "=HYPERLINK(\"http://x\")"}},
+				{ID: 2, UserID: 6, ListingType: "rent", Price: 1, User: &model.User{ID: 6, Name: "@SUM(A1)"}},
+				{ID: 3, UserID: 7, ListingType: "rent", Price: 1, User: &model.User{ID: 7, Name: "Jane = Doe"}},
+			}),
+			expectedType: "text/csv; charset=utf-8",
+			expectedFile: ".csv\"",
+			expectedBody: "id,user_id,user_name,listing_type,price,created_at,updated_at\n" +
+				"1,5,\"'=HYPERLINK(\"\"http://x\"\")\",rent,1,0,0\n" +
+				"2,6,'@SUM(A1),rent,1,0,0\n" +
+				"3,7,Jane = Doe,rent,1,0,0\n",
+		},
+		{
+			name:         "ndjson interrupted",
+			query:        "?format=ndjson",
+			export:       exportPages(errors.New("listing error"), pages[1]),
+			expectedType: "application/x-ndjson",
+			expectedFile: ".ndjson\"",
+			expectedBody: `{"id":2,"user_id":6,"listing_type":"sale","price":100,"created_at":20,"updated_at":21}` + "\n" +
+				`{"error":"export interrupted: listing error"}` + "\n",
+			expectedError: "export interrupted: listing error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockSvc := mocks.NewMockListingService(ctrl)
+			mockSvc.EXPECT().ExportListings(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(tt.export)
+
+			resp := httptest.NewRecorder()
+			exportRouter(mockSvc).ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/public-api/listings/export"+tt.query, nil))
+
+			require.Equal(t, http.StatusOK, resp.Code)
+			assert.Equal(t, tt.expectedType, resp.Header().Get("Content-Type"))
+			assert.Regexp(t, `^attachment; filename="listings-\d{8}T\d{6}Z`, resp.Header().Get("Content-Disposition"))
+			assert.Contains(t, resp.Header().Get("Content-Disposition"), tt.expectedFile)
+			assert.Equal(t, tt.expectedBody, resp.Body.String())
+			assert.Equal(t, tt.expectedError, resp.Result().Trailer.Get("X-Export-Error"))
+		})
+	}
+}
+
+func TestListingHandler_ExportListingsErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name         string
+		query        string
+		mockService  func(s *mocks.MockListingService)
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "unknown format",
+			query:        "?format=xlsx",
+			mockService:  func(s *mocks.MockListingService) {},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `format must be one of csv, ndjson`,
+		},
+		{
+			name:         "invalid filter",
+			query:        "?min_price=10&max_price=5",
+			mockService:  func(s *mocks.MockListingService) {},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `min_price must not exceed max_price`,
+		},
+		{
+			name:  "fails before the first page",
+			query: "?listing_type=rent",
+			mockService: func(s *mocks.MockListingService) {
+				s.EXPECT().
+					ExportListings(gomock.Any(), gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, q model.ListingQuery, _ func([]model.Listing) error) error {
+						assert.Equal(t, "rent", q.ListingType)
+						return errors.New("listing error")
+					})
+			},
+			expectedCode: http.StatusInternalServerError,
+			expectedBody: `{"error":"listing error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockSvc := mocks.NewMockListingService(ctrl)
+			tt.mockService(mockSvc)
+
+			resp := httptest.NewRecorder()
+			exportRouter(mockSvc).ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/public-api/listings/export"+tt.query, nil))
+
+			assert.Equal(t, tt.expectedCode, resp.Code)
+			assert.Equal(t, "", resp.Header().Get("Content-Disposition"))
+			assert.Contains(t, resp.Body.String(), tt.expectedBody)
+		})
+	}
+}
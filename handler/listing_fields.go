@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"fmt"
+	"public-api/model"
+	"public-api/pb"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// includeUser expands listings with their owner
+const includeUser = "user"
+
+// Fields that can be selected with the fields query parameter. Owner fields
+// are selected as user.<name>; ids are always returned.
+var (
+	listingFieldNames = []string{"id", "user_id", "listing_type", "price", "created_at", "updated_at", "user"}
+	userFieldNames    = []string{"id", "name", "created_at", "updated_at"}
+)
+
+var listingFieldValues = map[string]func(l *model.Listing) interface{}{
+	"id":           func(l *model.Listing) interface{} { return l.ID },
+	"user_id":      func(l *model.Listing) interface{} { return l.UserID },
+	"listing_type": func(l *model.Listing) interface{} { return l.ListingType },
+	"price":        func(l *model.Listing) interface{} { return l.Price },
+	"created_at":   func(l *model.Listing) interface{} { return l.CreatedAt },
+	"updated_at":   func(l *model.Listing) interface{} { return l.UpdatedAt },
+}
+
+var userFieldValues = map[string]func(u *model.User) interface{}{
+	"id":         func(u *model.User) interface{} { return u.ID },
+	"name":       func(u *model.User) interface{} { return u.Name },
+	"created_at": func(u *model.User) interface{} { return u.CreatedAt },
+	"updated_at": func(u *model.User) interface{} { return u.UpdatedAt },
+}
+
+// listingFields is what the fields and include query parameters select of
+// each listing
+type listingFields struct {
+	sparse   bool     // whether fields was given
+	listing  []string // selected listing fields, without user
+	user     []string // selected owner fields, nil for all of them
+	withUser bool     // whether owners are fetched and embedded
+}
+
+// parseListingFields reads the fields and include query parameters. Without
+// either, listings keep every field and their owner. Owners are otherwise
+// only fetched when included or when one of their fields is selected.
+func parseListingFields(c *gin.Context) (listingFields, error) {
+	fields, hasFields := c.GetQuery("fields")
+	include, hasInclude := c.GetQuery("include")
+	f := listingFields{sparse: hasFields, withUser: !hasFields && !hasInclude}
+
+	for _, name := range splitList(include) {
+		if name != includeUser {
+			return f, fmt.Errorf("%w: include must be one of %s", model.ErrInvalidInput, includeUser)
+		}
+		f.withUser = true
+	}
+	if !hasFields {
+		return f, nil
+	}
+
+	names := splitList(fields)
+	if len(names) == 0 {
+		return f, fmt.Errorf("%w: fields must name at least one field", model.ErrInvalidInput)
+	}
+	var unknown []string
+	wholeUser := false
+	for _, name := range names {
+		if owner, ok := strings.CutPrefix(name, "user."); ok {
+			if !slices.Contains(userFieldNames, owner) {
+				unknown = append(unknown, name)
+				continue
+			}
+			f.withUser = true
+			if f.user == nil {
+				f.user = []string{}
+			}
+			f.user = appendUnique(f.user, owner)
+			continue
+		}
+		switch {
+		case !slices.Contains(listingFieldNames, name):
+			unknown = append(unknown, name)
+		case name == includeUser:
+			f.withUser, wholeUser = true, true
+		default:
+			f.listing = appendUnique(f.listing, name)
+		}
+	}
+	if len(unknown) > 0 {
+		return f, fmt.Errorf("%w: fields has unknown %s, must be among %s", model.ErrInvalidInput, strings.Join(unknown, ", "), strings.Join(knownFields(), ", "))
+	}
+	if wholeUser {
+		f.user = nil
+	}
+	return f, nil
+}
+
+// project trims listings to the selected fields
+func (f listingFields) project(ls []model.Listing) []model.SparseListing {
+	out := make([]model.SparseListing, len(ls))
+	for i := range ls {
+		l := &ls[i]
+		sl := model.SparseListing{"id": l.ID}
+		for _, name := range f.listing {
+			sl[name] = listingFieldValues[name](l)
+		}
+		if f.withUser && l.User != nil {
+			sl["user"] = f.projectUser(l.User)
+		}
+		out[i] = sl
+	}
+	return out
+}
+
+func (f listingFields) projectUser(u *model.User) interface{} {
+	if f.user == nil {
+		return u
+	}
+	su := map[string]interface{}{"id": u.ID}
+	for _, name := range f.user {
+		su[name] = userFieldValues[name](u)
+	}
+	return su
+}
+
+// trimMessage clears the fields of a Protobuf page that were not selected
+func (f listingFields) trimMessage(page *pb.GetListingsResponse) *pb.GetListingsResponse {
+	keep := append([]string{"id"}, f.listing...)
+	if f.withUser {
+		keep = append(keep, "user")
+	}
+	for _, l := range page.GetListings() {
+		clearFieldsExcept(l.ProtoReflect(), keep)
+		if l.User != nil && f.user != nil {
+			clearFieldsExcept(l.User.ProtoReflect(), append([]string{"id"}, f.user...))
+		}
+	}
+	return page
+}
+
+func clearFieldsExcept(m protoreflect.Message, keep []string) {
+	fields := m.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		if fd := fields.Get(i); !slices.Contains(keep, string(fd.Name())) {
+			m.Clear(fd)
+		}
+	}
+}
+
+// knownFields lists every name the fields query parameter accepts
+func knownFields() []string {
+	names := slices.Clone(listingFieldNames)
+	for _, name := range userFieldNames {
+		names = append(names, "user."+name)
+	}
+	return names
+}
+
+// splitList splits a comma-separated query value, dropping empty items
+func splitList(raw string) []string {
+	var items []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func appendUnique(names []string, name string) []string {
+	if name == "id" || slices.Contains(names, name) {
+		return names
+	}
+	return append(names, name)
+}
@@ -1,10 +1,16 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
 	"public-api/model"
+	"public-api/pb"
 	"public-api/service"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -22,44 +28,188 @@ func NewListingHandler(s service.ListingService) *ListingHandler {
 // CreateListing handles POST /public-api/listings
 func (h *ListingHandler) CreateListing(c *gin.Context) {
 	var req model.CreateListingRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+	if err := bindBody(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, invalidRequest(err))
 		return
 	}
 
 	l := model.Listing{
 		UserID:      req.UserID,
-		ListingType: req.ListingType,
+		ListingType: string(req.ListingType),
 		Price:       req.Price,
 	}
 
 	created, err := h.service.CreateListing(c.Request.Context(), l)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(createListingStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"listing": created})
+	respond(c, http.StatusCreated, mapperFor(c).Listing(created), pb.FromListing(created))
 }
 
-// GetListings handles GET /public-api/listings
-func (h *ListingHandler) GetListings(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page_num", "1"))
-	size, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+// CreateListings handles POST /public-api/listings/batch. Every item is
+// validated before any is created; the response holds one result per item
+// and is a 207 unless all of them were created.
+func (h *ListingHandler) CreateListings(c *gin.Context) {
+	var reqs []model.CreateListingRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&reqs); err != nil {
+		c.JSON(http.StatusBadRequest, invalidRequest(err))
+		return
+	}
+	if errs := batchErrors(reqs, model.MaxListingBatchSize); len(errs) > 0 {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse{Error: "Invalid request", Errors: errs})
+		return
+	}
 
-	var userIDPtr *int64
+	listings := make([]model.Listing, len(reqs))
+	for i, req := range reqs {
+		listings[i] = model.Listing{
+			UserID:      req.UserID,
+			ListingType: string(req.ListingType),
+			Price:       req.Price,
+		}
+	}
 
-	if userIDStr := c.Query("user_id"); userIDStr != "" {
-		if id, err := strconv.ParseInt(userIDStr, 10, 64); err == nil {
-			userIDPtr = &id
+	created, errs, err := h.service.CreateListings(c.Request.Context(), listings)
+	if err != nil {
+		c.JSON(createListingStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	status := http.StatusCreated
+	results := make([]model.ListingResult, len(listings))
+	for i := range listings {
+		results[i] = model.ListingResult{Index: i, Status: http.StatusCreated, Listing: created[i]}
+		if errs[i] != nil {
+			results[i] = model.ListingResult{Index: i, Status: createListingStatus(errs[i]), Error: errs[i].Error()}
+			status = http.StatusMultiStatus
 		}
 	}
+	c.JSON(status, mapperFor(c).ListingResults(results))
+}
+
+// createListingStatus maps a failure to create a listing to its status
+func createListingStatus(err error) int {
+	switch {
+	case errors.Is(err, model.ErrInvalidInput):
+		return http.StatusBadRequest
+	case errors.Is(err, model.ErrUnprocessable):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// GetListings handles GET /public-api/listings. Owners are only fetched
+// when the fields or include query parameters ask for them.
+func (h *ListingHandler) GetListings(c *gin.Context) {
+	q, err := parseListingQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	fields, err := parseListingFields(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	find := h.service.FindListings
+	if fields.withUser {
+		find = h.service.GetListings
+	}
+	listings, err := find(c.Request.Context(), q)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if fields.sparse {
+		respond(c, http.StatusOK, mapperFor(c).SparseListings(fields.project(listings), q), fields.trimMessage(pb.FromListings(listings)))
+		return
+	}
+	respond(c, http.StatusOK, mapperFor(c).Listings(listings, q), pb.FromListings(listings))
+}
+
+// GetListingByID handles GET /public-api/listings/:id
+func (h *ListingHandler) GetListingByID(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid listing id"})
+		return
+	}
 
-	listings, err := h.service.GetListings(c.Request.Context(), page, size, userIDPtr)
+	listing, err := h.service.GetListingByID(c.Request.Context(), id)
 	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Listing not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"listings": listings})
+	respond(c, http.StatusOK, mapperFor(c).Listing(listing), pb.FromListing(listing))
+}
+
+// parseListingQuery reads and validates the filter and sort query parameters
+func parseListingQuery(c *gin.Context) (model.ListingQuery, error) {
+	q := model.ListingQuery{
+		ListingType: c.Query("listing_type"),
+		SortBy:      c.Query("sort_by"),
+		SortOrder:   strings.ToLower(c.Query("sort_order")),
+	}
+
+	var err error
+	if q.Page, err = strconv.Atoi(c.DefaultQuery("page_num", "1")); err != nil {
+		return q, fmt.Errorf("%w: page_num must be an integer", model.ErrInvalidInput)
+	}
+	if q.Size, err = strconv.Atoi(c.DefaultQuery("page_size", "10")); err != nil {
+		return q, fmt.Errorf("%w: page_size must be an integer", model.ErrInvalidInput)
+	}
+	if q.UserID, err = queryInt64(c, "user_id"); err != nil {
+		return q, err
+	}
+	if q.MinPrice, err = queryFloat64(c, "min_price"); err != nil {
+		return q, err
+	}
+	if q.MaxPrice, err = queryFloat64(c, "max_price"); err != nil {
+		return q, err
+	}
+	if q.CreatedFrom, err = queryInt64(c, "created_from"); err != nil {
+		return q, err
+	}
+	if q.CreatedTo, err = queryInt64(c, "created_to"); err != nil {
+		return q, err
+	}
+
+	return q, q.Validate()
+}
+
+// queryInt64 parses an optional integer query parameter
+func queryInt64(c *gin.Context, key string) (*int64, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return nil, nil
+	}
+	v, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s must be an integer", model.ErrInvalidInput, key)
+	}
+	return &v, nil
+}
+
+// queryFloat64 parses an optional numeric query parameter
+func queryFloat64(c *gin.Context, key string) (*float64, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return nil, nil
+	}
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return nil, fmt.Errorf("%w: %s must be a number", model.ErrInvalidInput, key)
+	}
+	return &v, nil
 }
@@ -2,11 +2,16 @@ package handler_test
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"public-api/apiversion"
 	"public-api/handler"
+	"public-api/middleware"
 	"public-api/mocks"
 	"public-api/model"
 	"testing"
@@ -14,6 +19,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestListingHandler_CreateListing(t *testing.T) {
@@ -51,7 +57,72 @@ func TestListingHandler_CreateListing(t *testing.T) {
 			requestBody:    `{invalid-json}`,                     // malformed JSON string
 			mockService:    func(s *mocks.MockListingService) {}, // no call expected
 			expectedCode:   http.StatusBadRequest,
-			expectedResult: `"error"`,
+			expectedResult: `"code":"malformed"`,
+		},
+		{
+			name:           "unknown listing type",
+			requestBody:    `{"user_id": 1, "listing_type": "house", "price": 100}`,
+			mockService:    func(s *mocks.MockListingService) {},
+			expectedCode:   http.StatusBadRequest,
+			expectedResult: `{"field":"listing_type","code":"listing_type","message":"must be one of: sale, rent"}`,
+		},
+		{
+			name:           "missing user_id",
+			requestBody:    `{"listing_type": "rent", "price": 100}`,
+			mockService:    func(s *mocks.MockListingService) {},
+			expectedCode:   http.StatusBadRequest,
+			expectedResult: `{"field":"user_id","code":"required","message":"is required"}`,
+		},
+		{
+			name:           "price above maximum",
+			requestBody:    `{"user_id": 1, "listing_type": "rent", "price": 2000000000000}`,
+			mockService:    func(s *mocks.MockListingService) {},
+			expectedCode:   http.StatusBadRequest,
+			expectedResult: `{"field":"price","code":"listing_price","message":"price must not exceed 1000000000000"}`,
+		},
+		{
+			name:           "price with too many decimals",
+			requestBody:    `{"user_id": 1, "listing_type": "rent", "price": 100.125}`,
+			mockService:    func(s *mocks.MockListingService) {},
+			expectedCode:   http.StatusBadRequest,
+			expectedResult: `"code":"listing_price"`,
+		},
+		{
+			name:           "wrong field type",
+			requestBody:    `{"user_id": "one", "listing_type": "rent", "price": 100}`,
+			mockService:    func(s *mocks.MockListingService) {},
+			expectedCode:   http.StatusBadRequest,
+			expectedResult: `{"field":"user_id","code":"type","message":"must be of type int64"}`,
+		},
+		{
+			name: "service validation error",
+			requestBody: model.CreateListingRequest{
+				UserID:      1,
+				ListingType: "sale",
+				Price:       100,
+			},
+			mockService: func(s *mocks.MockListingService) {
+				s.EXPECT().
+					CreateListing(gomock.Any(), gomock.Any()).
+					Return(nil, fmt.Errorf("%w: listing rejected", model.ErrInvalidInput))
+			},
+			expectedCode:   http.StatusBadRequest,
+			expectedResult: `listing rejected`,
+		},
+		{
+			name: "owner does not exist",
+			requestBody: model.CreateListingRequest{
+				UserID:      99,
+				ListingType: "sale",
+				Price:       100,
+			},
+			mockService: func(s *mocks.MockListingService) {
+				s.EXPECT().
+					CreateListing(gomock.Any(), gomock.Any()).
+					Return(nil, fmt.Errorf("%w: user 99 does not exist", model.ErrUnprocessable))
+			},
+			expectedCode:   http.StatusUnprocessableEntity,
+			expectedResult: `user 99 does not exist`,
 		},
 		{
 			name: "internal error",
@@ -116,7 +187,7 @@ func TestListingHandler_GetListings(t *testing.T) {
 			query: "/public-api/listings?page_num=1&page_size=2",
 			mockService: func(s *mocks.MockListingService) {
 				s.EXPECT().
-					GetListings(gomock.Any(), 1, 2, nil).
+					GetListings(gomock.Any(), model.ListingQuery{Page: 1, Size: 2}).
 					Return([]model.Listing{{ID: 1}, {ID: 2}}, nil)
 			},
 			expectedCode:   http.StatusOK,
@@ -128,7 +199,7 @@ func TestListingHandler_GetListings(t *testing.T) {
 			mockService: func(s *mocks.MockListingService) {
 				uid := int64(5)
 				s.EXPECT().
-					GetListings(gomock.Any(), 1, 2, &uid).
+					GetListings(gomock.Any(), model.ListingQuery{Page: 1, Size: 2, UserID: &uid}).
 					Return([]model.Listing{{ID: 5}}, nil)
 			},
 			expectedCode:   http.StatusOK,
@@ -139,12 +210,63 @@ func TestListingHandler_GetListings(t *testing.T) {
 			query: "/public-api/listings?page_num=1&page_size=2",
 			mockService: func(s *mocks.MockListingService) {
 				s.EXPECT().
-					GetListings(gomock.Any(), 1, 2, nil).
+					GetListings(gomock.Any(), model.ListingQuery{Page: 1, Size: 2}).
 					Return(nil, errors.New("internal error"))
 			},
 			expectedCode:   http.StatusInternalServerError,
 			expectedResult: `"error"`,
 		},
+		{
+			name:  "success with filters and sort",
+			query: "/public-api/listings?listing_type=rent&min_price=100&max_price=500&created_from=1&created_to=9&sort_by=price&sort_order=DESC",
+			mockService: func(s *mocks.MockListingService) {
+				minPrice, maxPrice := 100.0, 500.0
+				from, to := int64(1), int64(9)
+				s.EXPECT().
+					GetListings(gomock.Any(), model.ListingQuery{
+						Page:        1,
+						Size:        10,
+						ListingType: "rent",
+						MinPrice:    &minPrice,
+						MaxPrice:    &maxPrice,
+						CreatedFrom: &from,
+						CreatedTo:   &to,
+						SortBy:      model.ListingSortPrice,
+						SortOrder:   model.SortDesc,
+					}).
+					Return([]model.Listing{{ID: 1}}, nil)
+			},
+			expectedCode:   http.StatusOK,
+			expectedResult: `"listings"`,
+		},
+		{
+			name:           "invalid user_id",
+			query:          "/public-api/listings?user_id=abc",
+			mockService:    func(s *mocks.MockListingService) {},
+			expectedCode:   http.StatusBadRequest,
+			expectedResult: `user_id must be an integer`,
+		},
+		{
+			name:           "inverted price range",
+			query:          "/public-api/listings?min_price=500&max_price=100",
+			mockService:    func(s *mocks.MockListingService) {},
+			expectedCode:   http.StatusBadRequest,
+			expectedResult: `min_price must not exceed max_price`,
+		},
+		{
+			name:           "unknown sort field",
+			query:          "/public-api/listings?sort_by=name",
+			mockService:    func(s *mocks.MockListingService) {},
+			expectedCode:   http.StatusBadRequest,
+			expectedResult: `sort_by must be one of`,
+		},
+		{
+			name:           "page too large",
+			query:          "/public-api/listings?page_size=101",
+			mockService:    func(s *mocks.MockListingService) {},
+			expectedCode:   http.StatusBadRequest,
+			expectedResult: `page_size must be between 1 and 100`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -168,3 +290,299 @@ func TestListingHandler_GetListings(t *testing.T) {
 		})
 	}
 }
+
+func TestListingHandler_GetListingsFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	owned := []model.Listing{{ID: 1, UserID: 5, ListingType: "rent", Price: 1500, CreatedAt: 10, User: &model.User{ID: 5, Name: "Jane", CreatedAt: 3}}}
+	bare := []model.Listing{{ID: 1, UserID: 5, ListingType: "rent", Price: 1500, CreatedAt: 10}}
+	query := model.ListingQuery{Page: 1, Size: 10}
+
+	tests := []struct {
+		name         string
+		query        string
+		mockService  func(s *mocks.MockListingService)
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:  "listing fields skip owners",
+			query: "?fields=price,listing_type",
+			mockService: func(s *mocks.MockListingService) {
+				s.EXPECT().FindListings(gomock.Any(), query).Return(bare, nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"listings":[{"id":1,"listing_type":"rent","price":1500}]}`,
+		},
+		{
+			name:  "owner fields fetch owners",
+			query: "?fields=id,price,user.name",
+			mockService: func(s *mocks.MockListingService) {
+				s.EXPECT().GetListings(gomock.Any(), query).Return(owned, nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"listings":[{"id":1,"price":1500,"user":{"id":5,"name":"Jane"}}]}`,
+		},
+		{
+			name:  "included owner is whole",
+			query: "?fields=price&include=user",
+			mockService: func(s *mocks.MockListingService) {
+				s.EXPECT().GetListings(gomock.Any(), query).Return(owned, nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"listings":[{"id":1,"price":1500,"user":{"id":5,"name":"Jane","created_at":3,"updated_at":0}}]}`,
+		},
+		{
+			name:  "empty include keeps listing fields",
+			query: "?include=",
+			mockService: func(s *mocks.MockListingService) {
+				s.EXPECT().FindListings(gomock.Any(), query).Return(bare, nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"listings":[{"id":1,"user_id":5,"listing_type":"rent","price":1500,"created_at":10,"updated_at":0}]}`,
+		},
+		{
+			name:         "unknown fields",
+			query:        "?fields=price,colour,user.email",
+			mockService:  func(s *mocks.MockListingService) {},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"error":"invalid input: fields has unknown colour, user.email, must be among id, user_id, listing_type, price, created_at, updated_at, user, user.id, user.name, user.created_at, user.updated_at"}`,
+		},
+		{
+			name:         "empty fields",
+			query:        "?fields=,",
+			mockService:  func(s *mocks.MockListingService) {},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"error":"invalid input: fields must name at least one field"}`,
+		},
+		{
+			name:         "unknown include",
+			query:        "?include=owner",
+			mockService:  func(s *mocks.MockListingService) {},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"error":"invalid input: include must be one of user"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockSvc := mocks.NewMockListingService(ctrl)
+			tt.mockService(mockSvc)
+
+			router := gin.Default()
+			h := handler.NewListingHandler(mockSvc)
+			router.GET("/public-api/listings", h.GetListings)
+
+			req := httptest.NewRequest(http.MethodGet, "/public-api/listings"+tt.query, nil)
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedCode, resp.Code)
+			assert.JSONEq(t, tt.expectedBody, resp.Body.String())
+		})
+	}
+}
+
+func TestListingHandler_GetListingByID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	listing := &model.Listing{ID: 1, UserID: 2, ListingType: "rent", Price: 100, User: &model.User{ID: 2, Name: "John"}}
+
+	tests := []struct {
+		name           string
+		path           string
+		sendETag       bool
+		mockService    func(s *mocks.MockListingService)
+		expectedCode   int
+		expectedResult string
+	}{
+		{
+			name: "success",
+			path: "/public-api/listings/1",
+			mockService: func(s *mocks.MockListingService) {
+				s.EXPECT().GetListingByID(gomock.Any(), int64(1)).Return(listing, nil)
+			},
+			expectedCode:   http.StatusOK,
+			expectedResult: `"listing"`,
+		},
+		{
+			name:     "not modified",
+			path:     "/public-api/listings/1",
+			sendETag: true,
+			mockService: func(s *mocks.MockListingService) {
+				s.EXPECT().GetListingByID(gomock.Any(), int64(1)).Return(listing, nil)
+			},
+			expectedCode: http.StatusNotModified,
+		},
+		{
+			name:           "invalid id",
+			path:           "/public-api/listings/abc",
+			mockService:    func(s *mocks.MockListingService) {},
+			expectedCode:   http.StatusBadRequest,
+			expectedResult: `"error"`,
+		},
+		{
+			name: "not found",
+			path: "/public-api/listings/1",
+			mockService: func(s *mocks.MockListingService) {
+				s.EXPECT().GetListingByID(gomock.Any(), int64(1)).Return(nil, model.ErrNotFound)
+			},
+			expectedCode:   http.StatusNotFound,
+			expectedResult: `"error"`,
+		},
+		{
+			name: "internal error",
+			path: "/public-api/listings/1",
+			mockService: func(s *mocks.MockListingService) {
+				s.EXPECT().GetListingByID(gomock.Any(), int64(1)).Return(nil, errors.New("internal error"))
+			},
+			expectedCode:   http.StatusInternalServerError,
+			expectedResult: `"error"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockSvc := mocks.NewMockListingService(ctrl)
+			tt.mockService(mockSvc)
+
+			router := gin.Default()
+			router.Use(middleware.ETag()) // answers If-None-Match
+			h := handler.NewListingHandler(mockSvc)
+			router.GET("/public-api/listings/:id", h.GetListingByID)
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.sendETag {
+				body, err := json.Marshal(gin.H{"listing": listing})
+				require.NoError(t, err)
+				sum := sha256.Sum256(body)
+				req.Header.Set("If-None-Match", `"`+hex.EncodeToString(sum[:16])+`"`)
+			}
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedCode, resp.Code)
+			assert.Contains(t, resp.Body.String(), tt.expectedResult)
+			if resp.Code == http.StatusOK {
+				assert.NotEmpty(t, resp.Header().Get("ETag"))
+			}
+		})
+	}
+}
+
+func TestListingHandler_CreateListings(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		requestBody    string
+		mockService    func(s *mocks.MockListingService)
+		expectedCode   int
+		expectedResult string
+	}{
+		{
+			name:        "all created",
+			requestBody: `[{"user_id":1,"listing_type":"rent","price":100},{"user_id":2,"listing_type":"sale","price":200}]`,
+			mockService: func(s *mocks.MockListingService) {
+				s.EXPECT().
+					CreateListings(gomock.Any(), []model.Listing{
+						{UserID: 1, ListingType: "rent", Price: 100},
+						{UserID: 2, ListingType: "sale", Price: 200},
+					}).
+					Return([]*model.Listing{{ID: 1}, {ID: 2}}, []error{nil, nil}, nil)
+			},
+			expectedCode:   http.StatusCreated,
+			expectedResult: `{"results":[{"index":0,"status":201,"listing":{"id":1`,
+		},
+		{
+			name:        "some failed",
+			requestBody: `[{"user_id":1,"listing_type":"rent","price":100},{"user_id":9,"listing_type":"sale","price":200}]`,
+			mockService: func(s *mocks.MockListingService) {
+				s.EXPECT().
+					CreateListings(gomock.Any(), gomock.Any()).
+					Return([]*model.Listing{{ID: 1}, nil}, []error{nil, fmt.Errorf("%w: user 9 does not exist", model.ErrUnprocessable)}, nil)
+			},
+			expectedCode:   http.StatusMultiStatus,
+			expectedResult: `{"index":1,"status":422,"error":"unprocessable request: user 9 does not exist"}`,
+		},
+		{
+			name:           "invalid items are all reported",
+			requestBody:    `[{"user_id":1,"listing_type":"rent","price":100},{"user_id":0,"listing_type":"rent","price":100},{"user_id":1,"listing_type":"castle","price":100}]`,
+			mockService:    func(s *mocks.MockListingService) {},
+			expectedCode:   http.StatusBadRequest,
+			expectedResult: `"errors":[{"field":"[1].user_id","code":"required","message":"is required"},{"field":"[2].listing_type"`,
+		},
+		{
+			name:           "empty batch",
+			requestBody:    `[]`,
+			mockService:    func(s *mocks.MockListingService) {},
+			expectedCode:   http.StatusBadRequest,
+			expectedResult: `"code":"min"`,
+		},
+		{
+			name:           "not an array",
+			requestBody:    `{"user_id":1}`,
+			mockService:    func(s *mocks.MockListingService) {},
+			expectedCode:   http.StatusBadRequest,
+			expectedResult: `"code":"type"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockSvc := mocks.NewMockListingService(ctrl)
+			tt.mockService(mockSvc)
+
+			router := gin.Default()
+			router.POST("/public-api/listings/batch", handler.NewListingHandler(mockSvc).CreateListings)
+
+			req := httptest.NewRequest(http.MethodPost, "/public-api/listings/batch", bytes.NewBufferString(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedCode, resp.Code)
+			assert.Contains(t, resp.Body.String(), tt.expectedResult)
+		})
+	}
+}
+
+func TestListingHandler_V2Responses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSvc := mocks.NewMockListingService(ctrl)
+	mockSvc.EXPECT().
+		GetListings(gomock.Any(), model.ListingQuery{Page: 2, Size: 5}).
+		Return([]model.Listing{{ID: 1}, {ID: 2}}, nil)
+	mockSvc.EXPECT().
+		GetListingByID(gomock.Any(), int64(1)).
+		Return(&model.Listing{ID: 1}, nil)
+
+	router := gin.Default()
+	h := handler.NewListingHandler(mockSvc)
+	v2 := router.Group("/v2", apiversion.Fixed(apiversion.V2))
+	v2.GET("/listings", h.GetListings)
+	v2.GET("/listings/:id", h.GetListingByID)
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/v2/listings?page_num=2&page_size=5", nil))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"pagination":{"page_num":2,"page_size":5,"count":2}`)
+	assert.Contains(t, resp.Body.String(), `"data":[`)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/v2/listings/1", nil))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), `{"data":{"id":1`)
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"public-api/apiversion"
+	"public-api/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// responseMapper shapes successful handler results for one API version.
+// Error bodies are shared by every version.
+type responseMapper interface {
+	User(u *model.User) interface{}
+	Listing(l *model.Listing) interface{}
+	Listings(ls []model.Listing, q model.ListingQuery) interface{}
+	SparseListings(ls []model.SparseListing, q model.ListingQuery) interface{}
+	ListingResults(rs []model.ListingResult) interface{}
+	Onboarding(o *model.Onboarding) interface{}
+	ImportJob(j *model.ImportJob) interface{}
+}
+
+var mappers = map[apiversion.Version]responseMapper{
+	apiversion.V1: v1Mapper{},
+	apiversion.V2: v2Mapper{},
+}
+
+// mapperFor returns the mapper of the version negotiated for the request
+func mapperFor(c *gin.Context) responseMapper {
+	if m, ok := mappers[apiversion.FromContext(c)]; ok {
+		return m
+	}
+	return v1Mapper{}
+}
+
+// v1Mapper keys each payload by resource name
+type v1Mapper struct{}
+
+func (v1Mapper) User(u *model.User) interface{} {
+	return gin.H{"user": u}
+}
+
+func (v1Mapper) Listing(l *model.Listing) interface{} {
+	return gin.H{"listing": l}
+}
+
+func (v1Mapper) Listings(ls []model.Listing, _ model.ListingQuery) interface{} {
+	return gin.H{"listings": ls}
+}
+
+func (v1Mapper) SparseListings(ls []model.SparseListing, _ model.ListingQuery) interface{} {
+	return gin.H{"listings": ls}
+}
+
+func (v1Mapper) ListingResults(rs []model.ListingResult) interface{} {
+	return gin.H{"results": rs}
+}
+
+func (v1Mapper) Onboarding(o *model.Onboarding) interface{} {
+	return o
+}
+
+func (v1Mapper) ImportJob(j *model.ImportJob) interface{} {
+	return gin.H{"import": j}
+}
+
+// v2Mapper wraps payloads in a data envelope and paginates lists
+type v2Mapper struct{}
+
+func (v2Mapper) User(u *model.User) interface{} {
+	return model.UserEnvelope{Data: u}
+}
+
+func (v2Mapper) Listing(l *model.Listing) interface{} {
+	return model.ListingEnvelope{Data: l}
+}
+
+func (v2Mapper) Listings(ls []model.Listing, q model.ListingQuery) interface{} {
+	if ls == nil {
+		ls = []model.Listing{}
+	}
+	return model.ListingPage{
+		Data: ls,
+		Pagination: model.Pagination{
+			PageNum:  q.Page,
+			PageSize: q.Size,
+			Count:    len(ls),
+		},
+	}
+}
+
+func (v2Mapper) SparseListings(ls []model.SparseListing, q model.ListingQuery) interface{} {
+	if ls == nil {
+		ls = []model.SparseListing{}
+	}
+	return model.SparseListingPage{
+		Data: ls,
+		Pagination: model.Pagination{
+			PageNum:  q.Page,
+			PageSize: q.Size,
+			Count:    len(ls),
+		},
+	}
+}
+
+func (v2Mapper) ListingResults(rs []model.ListingResult) interface{} {
+	return model.ListingResultsEnvelope{Data: rs}
+}
+
+func (v2Mapper) Onboarding(o *model.Onboarding) interface{} {
+	return model.OnboardingEnvelope{Data: o}
+}
+
+func (v2Mapper) ImportJob(j *model.ImportJob) interface{} {
+	return model.ImportJobEnvelope{Data: j}
+}
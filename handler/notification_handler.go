@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"public-api/auth"
+	"public-api/model"
+	"public-api/notify"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// wsWriteWait bounds how long a single write to the client may take
+	wsWriteWait = 10 * time.Second
+	// wsMaxMessageSize bounds client messages, which are small commands
+	wsMaxMessageSize = 4096
+)
+
+// NotificationHandler serves the WebSocket notifications channel
+type NotificationHandler struct {
+	hub          *notify.Hub
+	auth         auth.Authenticator
+	pingInterval time.Duration
+	upgrader     websocket.Upgrader
+}
+
+// DefaultPingInterval is used when no positive ping interval is configured
+const DefaultPingInterval = 30 * time.Second
+
+// NewNotificationHandler constructs a new NotificationHandler. The server
+// pings every pingInterval and closes connections that have not answered
+// within two intervals. A non-positive pingInterval falls back to
+// DefaultPingInterval.
+func NewNotificationHandler(hub *notify.Hub, authenticator auth.Authenticator, pingInterval time.Duration) *NotificationHandler {
+	if pingInterval <= 0 {
+		pingInterval = DefaultPingInterval
+	}
+	return &NotificationHandler{
+		hub:          hub,
+		auth:         authenticator,
+		pingInterval: pingInterval,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+		},
+	}
+}
+
+// Connect handles GET /public-api/ws
+func (h *NotificationHandler) Connect(c *gin.Context) {
+	userID, err := h.auth.Authenticate(c.Request)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	conn, err := h.hub.Register(userID)
+	if err != nil {
+		status := http.StatusServiceUnavailable
+		if errors.Is(err, notify.ErrTooManyUserConnections) {
+			status = http.StatusTooManyRequests
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	defer conn.Close()
+
+	// On failure the upgrader has already replied to the client
+	ws, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.writeLoop(ws, conn)
+	}()
+	h.readLoop(ws, conn)
+
+	conn.Close()
+	<-done
+}
+
+// readLoop feeds client commands to conn until the client goes away or stops
+// answering pings
+func (h *NotificationHandler) readLoop(ws *websocket.Conn, conn *notify.Conn) {
+	pongWait := 2 * h.pingInterval
+	ws.SetReadLimit(wsMaxMessageSize)
+	_ = ws.SetReadDeadline(time.Now().Add(pongWait))
+	ws.SetPongHandler(func(string) error {
+		return ws.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		_, data, err := ws.ReadMessage()
+		if err != nil {
+			return
+		}
+		var msg notify.ClientMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			conn.Reject(fmt.Errorf("%w: messages must be JSON objects", model.ErrInvalidInput))
+			continue
+		}
+		conn.Handle(msg)
+	}
+}
+
+// writeLoop is the only writer to ws. It sends queued messages and pings
+// until conn is closed, then says goodbye and closes ws, which also ends
+// readLoop.
+func (h *NotificationHandler) writeLoop(ws *websocket.Conn, conn *notify.Conn) {
+	defer ws.Close()
+
+	ticker := time.NewTicker(h.pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case msg, ok := <-conn.Send():
+			if !ok {
+				code, reason := websocket.CloseNormalClosure, ""
+				if conn.Dropped() {
+					// The client can reconnect and subscribe again
+					code, reason = websocket.CloseTryAgainLater, "client too slow"
+				}
+				_ = ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(wsWriteWait))
+				return
+			}
+			_ = ws.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := ws.WriteJSON(msg); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if err := ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait)); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"public-api/auth"
+	"public-api/handler"
+	"public-api/model"
+	"public-api/notify"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func startNotificationServer(t *testing.T, hub *notify.Hub, pingInterval time.Duration) (string, *auth.TokenAuthenticator) {
+	gin.SetMode(gin.TestMode)
+	authenticator := auth.NewTokenAuthenticator("secret")
+
+	r := gin.New()
+	r.GET("/ws", handler.NewNotificationHandler(hub, authenticator, pingInterval).Connect)
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	return "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws", authenticator
+}
+
+func dial(t *testing.T, url, token string) (*websocket.Conn, *http.Response, error) {
+	header := http.Header{}
+	if token != "" {
+		header.Set("Authorization", "Bearer "+token)
+	}
+	ws, resp, err := websocket.DefaultDialer.Dial(url, header)
+	if ws != nil {
+		t.Cleanup(func() { ws.Close() })
+	}
+	return ws, resp, err
+}
+
+func readMessage(t *testing.T, ws *websocket.Conn) notify.ServerMessage {
+	require.NoError(t, ws.SetReadDeadline(time.Now().Add(time.Second)))
+	var msg notify.ServerMessage
+	require.NoError(t, ws.ReadJSON(&msg))
+	return msg
+}
+
+func TestNotificationHandler_SubscribeAndReceive(t *testing.T) {
+	hub := notify.NewHub(notify.Limits{SendBuffer: 10})
+	url, authenticator := startNotificationServer(t, hub, time.Minute)
+
+	ws, _, err := dial(t, url, authenticator.Issue(7, time.Hour))
+	require.NoError(t, err)
+
+	require.NoError(t, ws.WriteJSON(notify.ClientMessage{Type: notify.TypeSubscribe, ID: "a", Topic: notify.TopicMyListings}))
+	assert.Equal(t, notify.ServerMessage{Type: notify.TypeAck, ID: "a", Topic: notify.TopicMyListings}, readMessage(t, ws))
+
+	require.NoError(t, ws.WriteMessage(websocket.TextMessage, []byte("not json")))
+	reply := readMessage(t, ws)
+	assert.Equal(t, notify.TypeError, reply.Type)
+	assert.Contains(t, reply.Error, "JSON")
+
+	hub.Publish(model.Listing{ID: 1, UserID: 8, ListingType: "rent"})
+	hub.Publish(model.Listing{ID: 2, UserID: 7, ListingType: "rent", Price: 100})
+
+	ev := readMessage(t, ws)
+	assert.Equal(t, notify.TypeEvent, ev.Type)
+	assert.Equal(t, notify.EventListingCreated, ev.Event)
+	assert.Equal(t, map[string]interface{}{
+		"id": float64(2), "user_id": float64(7), "listing_type": "rent", "price": float64(100),
+		"created_at": float64(0), "updated_at": float64(0),
+	}, ev.Data)
+
+	ws.Close()
+	require.Eventually(t, func() bool { return hub.Connections() == 0 }, time.Second, 5*time.Millisecond)
+}
+
+func TestNotificationHandler_NonPositivePingInterval(t *testing.T) {
+	hub := notify.NewHub(notify.Limits{SendBuffer: 10})
+	url, authenticator := startNotificationServer(t, hub, 0)
+
+	ws, _, err := dial(t, url, authenticator.Issue(7, time.Hour))
+	require.NoError(t, err)
+	require.NoError(t, ws.WriteJSON(notify.ClientMessage{Type: notify.TypeSubscribe, ID: "a", Topic: notify.TopicMyListings}))
+	assert.Equal(t, notify.TypeAck, readMessage(t, ws).Type)
+}
+
+func TestNotificationHandler_Rejected(t *testing.T) {
+	hub := notify.NewHub(notify.Limits{MaxConnections: 2, MaxConnectionsPerUser: 1, SendBuffer: 10})
+	url, authenticator := startNotificationServer(t, hub, time.Minute)
+
+	_, resp, err := dial(t, url, "")
+	require.Error(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	_, resp, err = dial(t, url, authenticator.Issue(7, -time.Minute))
+	require.Error(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	_, _, err = dial(t, url, authenticator.Issue(7, time.Hour))
+	require.NoError(t, err)
+	_, resp, err = dial(t, url, authenticator.Issue(7, time.Hour))
+	require.Error(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+
+	_, _, err = dial(t, url, authenticator.Issue(8, time.Hour))
+	require.NoError(t, err)
+	_, resp, err = dial(t, url, authenticator.Issue(9, time.Hour))
+	require.Error(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+}
+
+func TestNotificationHandler_Keepalive(t *testing.T) {
+	hub := notify.NewHub(notify.Limits{SendBuffer: 10})
+	url, authenticator := startNotificationServer(t, hub, 20*time.Millisecond)
+
+	ws, _, err := dial(t, url, authenticator.Issue(7, time.Hour))
+	require.NoError(t, err)
+
+	pinged := make(chan struct{}, 1)
+	ws.SetPingHandler(func(data string) error {
+		select {
+		case pinged <- struct{}{}:
+		default:
+		}
+		return ws.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(time.Second))
+	})
+
+	// Control frames are only processed while reading
+	go func() {
+		for {
+			if _, _, err := ws.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	select {
+	case <-pinged:
+	case <-time.After(time.Second):
+		t.Fatal("no ping received")
+	}
+
+	// Answering pings keeps the connection open well past the pong deadline
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, 1, hub.Connections())
+}
+
+func TestNotificationHandler_ClosesUnresponsiveClients(t *testing.T) {
+	hub := notify.NewHub(notify.Limits{SendBuffer: 10})
+	url, authenticator := startNotificationServer(t, hub, 20*time.Millisecond)
+
+	// Never reading means pings are never answered
+	_, _, err := dial(t, url, authenticator.Issue(7, time.Hour))
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return hub.Connections() == 1 }, time.Second, 5*time.Millisecond)
+	require.Eventually(t, func() bool { return hub.Connections() == 0 }, time.Second, 5*time.Millisecond)
+}
+
+func TestNotificationHandler_DropsSlowClients(t *testing.T) {
+	hub := notify.NewHub(notify.Limits{SendBuffer: 1})
+	url, authenticator := startNotificationServer(t, hub, time.Minute)
+
+	ws, _, err := dial(t, url, authenticator.Issue(7, time.Hour))
+	require.NoError(t, err)
+	require.NoError(t, ws.WriteJSON(notify.ClientMessage{Type: notify.TypeSubscribe, Topic: "listings.type.sale"}))
+	readMessage(t, ws)
+
+	// Publishing faster than the connection drains overflows its buffer
+	for i := int64(1); i <= 100; i++ {
+		hub.Publish(model.Listing{ID: i, ListingType: "sale"})
+	}
+
+	for {
+		_, _, err := ws.ReadMessage()
+		if err != nil {
+			assert.True(t, websocket.IsCloseError(err, websocket.CloseTryAgainLater), err)
+			break
+		}
+	}
+	assert.Equal(t, 0, hub.Connections())
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"public-api/model"
+	"public-api/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OnboardingHandler handles creating a user together with their first
+// listing
+type OnboardingHandler struct {
+	service service.OnboardingService
+}
+
+// NewOnboardingHandler constructs a new OnboardingHandler
+func NewOnboardingHandler(s service.OnboardingService) *OnboardingHandler {
+	return &OnboardingHandler{service: s}
+}
+
+// Onboard handles POST /public-api/onboarding
+func (h *OnboardingHandler) Onboard(c *gin.Context) {
+	var req model.OnboardingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, invalidRequest(err))
+		return
+	}
+
+	l := model.Listing{
+		ListingType: string(req.Listing.ListingType),
+		Price:       req.Listing.Price,
+	}
+
+	result, err := h.service.Onboard(c.Request.Context(), req.Name, l)
+	if err != nil {
+		var partial *service.OnboardingError
+		switch {
+		case errors.As(err, &partial):
+			c.JSON(partialStatus(partial.Err), model.OnboardingErrorResponse{
+				Error:      err.Error(),
+				FailedStep: partial.Step,
+				User:       partial.User,
+			})
+		case errors.Is(err, model.ErrInvalidInput):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, mapperFor(c).Onboarding(result))
+}
+
+// partialStatus picks the status of an onboarding that failed after the
+// user was created
+func partialStatus(err error) int {
+	if errors.Is(err, model.ErrUnprocessable) {
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusInternalServerError
+}
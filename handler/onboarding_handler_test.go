@@ -0,0 +1,122 @@
+package handler_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"public-api/apiversion"
+	"public-api/handler"
+	"public-api/mocks"
+	"public-api/model"
+	"public-api/service"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOnboardingHandler_Onboard(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	user := &model.User{ID: 7, Name: "Jane"}
+	valid := `{"name":"Jane","listing":{"listing_type":"rent","price":100}}`
+
+	tests := []struct {
+		name           string
+		requestBody    string
+		mockService    func(s *mocks.MockOnboardingService)
+		expectedCode   int
+		expectedResult string
+	}{
+		{
+			name:        "success",
+			requestBody: valid,
+			mockService: func(s *mocks.MockOnboardingService) {
+				s.EXPECT().
+					Onboard(gomock.Any(), "Jane", model.Listing{ListingType: "rent", Price: 100}).
+					Return(&model.Onboarding{User: user, Listing: &model.Listing{ID: 3, UserID: 7}}, nil)
+			},
+			expectedCode:   http.StatusCreated,
+			expectedResult: `{"user":{"id":7,"name":"Jane","created_at":0,"updated_at":0},"listing":{"id":3`,
+		},
+		{
+			name:           "invalid listing",
+			requestBody:    `{"name":"Jane","listing":{"listing_type":"castle","price":100}}`,
+			mockService:    func(s *mocks.MockOnboardingService) {},
+			expectedCode:   http.StatusBadRequest,
+			expectedResult: `"field":"listing.listing_type"`,
+		},
+		{
+			name:        "user step fails",
+			requestBody: valid,
+			mockService: func(s *mocks.MockOnboardingService) {
+				s.EXPECT().Onboard(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("boom"))
+			},
+			expectedCode:   http.StatusInternalServerError,
+			expectedResult: `{"error":"boom"}`,
+		},
+		{
+			name:        "listing rejected after the user was created",
+			requestBody: valid,
+			mockService: func(s *mocks.MockOnboardingService) {
+				s.EXPECT().Onboard(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil, &service.OnboardingError{Step: service.StepListing, User: user, Err: model.ErrUnprocessable})
+			},
+			expectedCode:   http.StatusUnprocessableEntity,
+			expectedResult: `"failed_step":"listing","user":{"id":7`,
+		},
+		{
+			name:        "listing step fails after the user was created",
+			requestBody: valid,
+			mockService: func(s *mocks.MockOnboardingService) {
+				s.EXPECT().Onboard(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil, &service.OnboardingError{Step: service.StepListing, User: user, Err: errors.New("boom")})
+			},
+			expectedCode:   http.StatusInternalServerError,
+			expectedResult: `"failed_step":"listing","user":{"id":7`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockSvc := mocks.NewMockOnboardingService(ctrl)
+			tt.mockService(mockSvc)
+
+			router := gin.Default()
+			router.POST("/public-api/onboarding", handler.NewOnboardingHandler(mockSvc).Onboard)
+
+			req := httptest.NewRequest(http.MethodPost, "/public-api/onboarding", bytes.NewBufferString(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedCode, resp.Code)
+			assert.Contains(t, resp.Body.String(), tt.expectedResult)
+		})
+	}
+}
+
+func TestOnboardingHandler_V2Response(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSvc := mocks.NewMockOnboardingService(ctrl)
+	mockSvc.EXPECT().Onboard(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&model.Onboarding{User: &model.User{ID: 7}, Listing: &model.Listing{ID: 3}}, nil)
+
+	router := gin.Default()
+	v2 := router.Group("/v2", apiversion.Fixed(apiversion.V2))
+	v2.POST("/onboarding", handler.NewOnboardingHandler(mockSvc).Onboard)
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/v2/onboarding",
+		bytes.NewBufferString(`{"name":"Jane","listing":{"listing_type":"sale","price":100}}`)))
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	assert.Contains(t, resp.Body.String(), `{"data":{"user":{"id":7`)
+}
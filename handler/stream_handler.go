@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"public-api/model"
+	"public-api/stream"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StreamHandler pushes newly created listings to clients with Server-Sent Events
+type StreamHandler struct {
+	hub       *stream.Hub
+	heartbeat time.Duration
+}
+
+// DefaultStreamHeartbeat is used when no positive heartbeat is configured
+const DefaultStreamHeartbeat = 15 * time.Second
+
+// NewStreamHandler constructs a new StreamHandler sending a heartbeat event
+// every heartbeat so idle connections are not dropped by proxies. A
+// non-positive heartbeat falls back to DefaultStreamHeartbeat.
+func NewStreamHandler(hub *stream.Hub, heartbeat time.Duration) *StreamHandler {
+	if heartbeat <= 0 {
+		heartbeat = DefaultStreamHeartbeat
+	}
+	return &StreamHandler{hub: hub, heartbeat: heartbeat}
+}
+
+// StreamListings handles GET /public-api/listings/stream
+func (h *StreamHandler) StreamListings(c *gin.Context) {
+	filter, err := parseStreamFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	lastEventID, err := lastEventID(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sub, missed := h.hub.Subscribe(lastEventID, filter)
+	defer sub.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	mapper := mapperFor(c)
+	for _, ev := range missed {
+		if err := writeListingEvent(c.Writer, mapper, ev); err != nil {
+			return
+		}
+	}
+	c.Writer.Flush()
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case ev, ok := <-sub.Events():
+			if !ok {
+				// Dropped for falling behind; the client resumes with Last-Event-ID
+				return
+			}
+			if err := writeListingEvent(c.Writer, mapper, ev); err != nil {
+				return
+			}
+		case t := <-ticker.C:
+			if _, err := fmt.Fprintf(c.Writer, "event: heartbeat\ndata: {\"time\":%d}\n\n", t.Unix()); err != nil {
+				return
+			}
+		}
+		c.Writer.Flush()
+	}
+}
+
+// writeListingEvent writes ev as a "listing" event shaped like the listing
+// detail response of the negotiated API version
+func writeListingEvent(w io.Writer, mapper responseMapper, ev stream.Event) error {
+	data, err := json.Marshal(mapper.Listing(&ev.Listing))
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: listing\ndata: %s\n\n", ev.ID, data)
+	return err
+}
+
+// parseStreamFilter builds the subscription filter from the user_id and
+// listing_type query parameters
+func parseStreamFilter(c *gin.Context) (func(model.Listing) bool, error) {
+	q := model.ListingQuery{ListingType: c.Query("listing_type")}
+	if q.ListingType != "" && !model.ListingType(q.ListingType).IsValid() {
+		return nil, fmt.Errorf("%w: listing_type must be one of %s, %s",
+			model.ErrInvalidInput, model.ListingTypeSale, model.ListingTypeRent)
+	}
+
+	var err error
+	if q.UserID, err = queryInt64(c, "user_id"); err != nil {
+		return nil, err
+	}
+	return q.Matches, nil
+}
+
+// lastEventID reads the Last-Event-ID header sent by reconnecting clients
+func lastEventID(c *gin.Context) (uint64, error) {
+	raw := c.GetHeader("Last-Event-ID")
+	if raw == "" {
+		return 0, nil
+	}
+	id, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: Last-Event-ID must be a non-negative integer", model.ErrInvalidInput)
+	}
+	return id, nil
+}
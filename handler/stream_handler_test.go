@@ -0,0 +1,165 @@
+package handler_test
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"public-api/apiversion"
+	"public-api/handler"
+	"public-api/model"
+	"public-api/stream"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sseEvent is one parsed Server-Sent Event
+type sseEvent struct {
+	id    string
+	event string
+	data  string
+}
+
+// readEvent reads the next event from an SSE stream
+func readEvent(t *testing.T, r *bufio.Reader) sseEvent {
+	var ev sseEvent
+	for {
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimRight(line, "\n")
+		if line == "" {
+			return ev
+		}
+		field, value, _ := strings.Cut(line, ": ")
+		switch field {
+		case "id":
+			ev.id = value
+		case "event":
+			ev.event = value
+		case "data":
+			ev.data = value
+		}
+	}
+}
+
+func startStreamServer(t *testing.T, hub *stream.Hub, heartbeat time.Duration, version apiversion.Version) *httptest.Server {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/listings/stream", apiversion.Fixed(version), handler.NewStreamHandler(hub, heartbeat).StreamListings)
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func openStream(t *testing.T, url, lastEventID string) (*http.Response, *bufio.Reader) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	require.NoError(t, err)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp, bufio.NewReader(resp.Body)
+}
+
+func waitForSubscribers(t *testing.T, hub *stream.Hub, n int) {
+	require.Eventually(t, func() bool { return hub.Subscribers() == n }, time.Second, 5*time.Millisecond)
+}
+
+func TestStreamHandler_StreamListings(t *testing.T) {
+	hub := stream.NewHub(10, 10)
+	srv := startStreamServer(t, hub, time.Minute, apiversion.V1)
+
+	// Published before connecting: replayed after Last-Event-ID 1, filter permitting
+	hub.Publish(model.Listing{ID: 1, UserID: 7, ListingType: "rent"})
+	hub.Publish(model.Listing{ID: 2, UserID: 7, ListingType: "rent"})
+	hub.Publish(model.Listing{ID: 3, UserID: 8, ListingType: "rent"})
+
+	resp, events := openStream(t, srv.URL+"/listings/stream?user_id=7", "1")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	assert.Equal(t, sseEvent{
+		id:    "2",
+		event: "listing",
+		data:  `{"listing":{"id":2,"user_id":7,"listing_type":"rent","price":0,"created_at":0,"updated_at":0}}`,
+	}, readEvent(t, events))
+
+	waitForSubscribers(t, hub, 1)
+	hub.Publish(model.Listing{ID: 4, UserID: 8, ListingType: "sale"})
+	hub.Publish(model.Listing{ID: 5, UserID: 7, ListingType: "sale", User: &model.User{ID: 7, Name: "John"}})
+
+	ev := readEvent(t, events)
+	assert.Equal(t, "5", ev.id)
+	assert.Contains(t, ev.data, `"user":{"id":7,"name":"John"`)
+}
+
+func TestStreamHandler_V2Payload(t *testing.T) {
+	hub := stream.NewHub(10, 10)
+	srv := startStreamServer(t, hub, time.Minute, apiversion.V2)
+
+	_, events := openStream(t, srv.URL+"/listings/stream?listing_type=sale", "")
+	waitForSubscribers(t, hub, 1)
+	hub.Publish(model.Listing{ID: 1, ListingType: "rent"})
+	hub.Publish(model.Listing{ID: 2, ListingType: "sale"})
+
+	ev := readEvent(t, events)
+	assert.Equal(t, "2", ev.id)
+	assert.True(t, strings.HasPrefix(ev.data, `{"data":{"id":2,`))
+}
+
+func TestStreamHandler_Heartbeat(t *testing.T) {
+	hub := stream.NewHub(10, 10)
+	srv := startStreamServer(t, hub, 10*time.Millisecond, apiversion.V1)
+
+	_, events := openStream(t, srv.URL+"/listings/stream", "")
+
+	ev := readEvent(t, events)
+	assert.Equal(t, "heartbeat", ev.event)
+	assert.Empty(t, ev.id)
+	assert.Contains(t, ev.data, `"time":`)
+}
+
+func TestStreamHandler_InvalidRequest(t *testing.T) {
+	hub := stream.NewHub(10, 10)
+	srv := startStreamServer(t, hub, time.Minute, apiversion.V1)
+
+	tests := []struct {
+		name        string
+		query       string
+		lastEventID string
+	}{
+		{name: "invalid listing type", query: "?listing_type=castle"},
+		{name: "invalid user id", query: "?user_id=abc"},
+		{name: "invalid Last-Event-ID", lastEventID: "-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, _ := openStream(t, srv.URL+"/listings/stream"+tt.query, tt.lastEventID)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		})
+	}
+	assert.Equal(t, 0, hub.Subscribers())
+}
+
+func TestStreamHandler_NonPositiveHeartbeat(t *testing.T) {
+	hub := stream.NewHub(10, 10)
+	srv := startStreamServer(t, hub, 0, apiversion.V1)
+
+	resp, events := openStream(t, srv.URL+"/listings/stream", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	waitForSubscribers(t, hub, 1)
+	hub.Publish(model.Listing{ID: 1, ListingType: "rent"})
+
+	assert.Equal(t, "1", readEvent(t, events).id)
+}
@@ -1,9 +1,12 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"public-api/model"
+	"public-api/pb"
 	"public-api/service"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -21,16 +24,50 @@ func NewUserHandler(s service.UserService) *UserHandler {
 // CreateUser handles POST /public-api/users
 func (h *UserHandler) CreateUser(c *gin.Context) {
 	var req model.CreateUserRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+	if err := bindBody(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, invalidRequest(err))
 		return
 	}
 
 	user, err := h.service.CreateUser(c.Request.Context(), req.Name)
 	if err != nil {
+		if errors.Is(err, model.ErrInvalidInput) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"user": user})
+	respond(c, http.StatusCreated, mapperFor(c).User(user), pb.FromUser(user))
+}
+
+// UpdateUser handles PATCH /public-api/users/:id
+func (h *UserHandler) UpdateUser(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user id"})
+		return
+	}
+
+	var req model.UpdateUserRequest
+	if err := bindBody(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, invalidRequest(err))
+		return
+	}
+
+	user, err := h.service.UpdateUser(c.Request.Context(), id, req.Name)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrInvalidInput):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, model.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	respond(c, http.StatusOK, mapperFor(c).User(user), pb.FromUser(user))
 }
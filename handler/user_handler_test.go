@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"public-api/apiversion"
 	"public-api/handler"
 	"public-api/mocks"
 	"public-api/model"
@@ -54,6 +56,35 @@ func TestUserHandler_CreateUser(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			expectedBody:   `Invalid request`,
 		},
+		{
+			name:           "missing name",
+			requestBody:    model.CreateUserRequest{},
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"field":"name","code":"required","message":"is required"}`,
+		},
+		{
+			name: "invalid characters in name",
+			requestBody: model.CreateUserRequest{
+				Name: "John #1",
+			},
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"field":"name","code":"user_name","message":"name contains invalid character '#'"}`,
+		},
+		{
+			name: "service validation error",
+			requestBody: model.CreateUserRequest{
+				Name: "John Doe",
+			},
+			mockSetup: func() {
+				mockService.EXPECT().
+					CreateUser(gomock.Any(), "John Doe").
+					Return(nil, fmt.Errorf("%w: name rejected", model.ErrInvalidInput))
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `name rejected`,
+		},
 		{
 			name: "internal error",
 			requestBody: model.CreateUserRequest{
@@ -99,3 +130,134 @@ func TestUserHandler_CreateUser(t *testing.T) {
 		})
 	}
 }
+
+func TestUserHandler_UpdateUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		path           string
+		requestBody    string
+		mockSetup      func(s *mocks.MockUserService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:        "success",
+			path:        "/public-api/users/1",
+			requestBody: `{"name":"Jane Doe"}`,
+			mockSetup: func(s *mocks.MockUserService) {
+				s.EXPECT().
+					UpdateUser(gomock.Any(), int64(1), "Jane Doe").
+					Return(&model.User{ID: 1, Name: "Jane Doe"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"name":"Jane Doe"`,
+		},
+		{
+			name:           "invalid id",
+			path:           "/public-api/users/abc",
+			requestBody:    `{"name":"Jane Doe"}`,
+			mockSetup:      func(s *mocks.MockUserService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `Invalid user id`,
+		},
+		{
+			name:           "invalid JSON",
+			path:           "/public-api/users/1",
+			requestBody:    `{invalid}`,
+			mockSetup:      func(s *mocks.MockUserService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `Invalid request`,
+		},
+		{
+			name:           "name too short",
+			path:           "/public-api/users/1",
+			requestBody:    `{"name":"J"}`,
+			mockSetup:      func(s *mocks.MockUserService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"field":"name","code":"user_name","message":"name must be between 2 and 100 characters"}`,
+		},
+		{
+			name:        "service validation error",
+			path:        "/public-api/users/1",
+			requestBody: `{"name":"Jane Doe"}`,
+			mockSetup: func(s *mocks.MockUserService) {
+				s.EXPECT().
+					UpdateUser(gomock.Any(), int64(1), "Jane Doe").
+					Return(nil, fmt.Errorf("%w: name rejected", model.ErrInvalidInput))
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `name rejected`,
+		},
+		{
+			name:        "not found",
+			path:        "/public-api/users/1",
+			requestBody: `{"name":"Jane Doe"}`,
+			mockSetup: func(s *mocks.MockUserService) {
+				s.EXPECT().
+					UpdateUser(gomock.Any(), int64(1), "Jane Doe").
+					Return(nil, model.ErrNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `User not found`,
+		},
+		{
+			name:        "internal error",
+			path:        "/public-api/users/1",
+			requestBody: `{"name":"Jane Doe"}`,
+			mockSetup: func(s *mocks.MockUserService) {
+				s.EXPECT().
+					UpdateUser(gomock.Any(), int64(1), "Jane Doe").
+					Return(nil, errors.New("failed to update user"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `failed to update user`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockService := mocks.NewMockUserService(ctrl)
+			tt.mockSetup(mockService)
+
+			router := gin.Default()
+			h := handler.NewUserHandler(mockService)
+			router.PATCH("/public-api/users/:id", h.UpdateUser)
+
+			req := httptest.NewRequest(http.MethodPatch, tt.path, bytes.NewBufferString(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+		})
+	}
+}
+
+func TestUserHandler_V2Responses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mocks.NewMockUserService(ctrl)
+	mockService.EXPECT().
+		CreateUser(gomock.Any(), "John Doe").
+		Return(&model.User{ID: 1, Name: "John Doe"}, nil)
+
+	router := gin.Default()
+	h := handler.NewUserHandler(mockService)
+	router.POST("/v2/users", apiversion.Fixed(apiversion.V2), h.CreateUser)
+
+	req := httptest.NewRequest(http.MethodPost, "/v2/users", bytes.NewBufferString(`{"name":"John Doe"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Contains(t, rec.Body.String(), `{"data":{"id":1,"name":"John Doe"`)
+}
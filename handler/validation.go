@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"public-api/model"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// Validation codes that are not validator tags
+const (
+	codeMalformed = "malformed"
+	codeType      = "type"
+)
+
+func init() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		panic("handler: unexpected binding validator engine")
+	}
+
+	// Report JSON field names instead of Go struct field names
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	mustRegister(v, "listing_type", func(fl validator.FieldLevel) bool {
+		return model.ListingType(fl.Field().String()).IsValid()
+	})
+	mustRegister(v, "listing_price", func(fl validator.FieldLevel) bool {
+		return model.ValidatePrice(fl.Field().Float()) == nil
+	})
+	mustRegister(v, "user_name", func(fl validator.FieldLevel) bool {
+		_, err := model.NormalizeUserName(fl.Field().String())
+		return err == nil
+	})
+	mustRegister(v, "webhook_url", func(fl validator.FieldLevel) bool {
+		return model.ValidateWebhookURL(fl.Field().String()) == nil
+	})
+	mustRegister(v, "webhook_event", func(fl validator.FieldLevel) bool {
+		return model.WebhookEventType(fl.Field().String()).IsValid()
+	})
+}
+
+func mustRegister(v *validator.Validate, tag string, fn validator.Func) {
+	if err := v.RegisterValidation(tag, fn); err != nil {
+		panic(fmt.Sprintf("handler: register %s validator: %v", tag, err))
+	}
+}
+
+// invalidRequest builds the 400 response body for a binding error
+func invalidRequest(err error) model.ErrorResponse {
+	return model.ErrorResponse{Error: "Invalid request", Errors: fieldErrors(err)}
+}
+
+// batchErrors checks the size of a batch request and validates each item,
+// prefixing field paths with the item's index
+func batchErrors[T any](items []T, max int) []model.FieldError {
+	if len(items) == 0 {
+		return []model.FieldError{{Code: "min", Message: "must have at least 1 items"}}
+	}
+	if len(items) > max {
+		return []model.FieldError{{Code: "max", Message: fmt.Sprintf("must have at most %d items", max)}}
+	}
+
+	var out []model.FieldError
+	for i := range items {
+		if err := binding.Validator.ValidateStruct(&items[i]); err != nil {
+			for _, fe := range fieldErrors(err) {
+				fe.Field = fmt.Sprintf("[%d].%s", i, fe.Field)
+				out = append(out, fe)
+			}
+		}
+	}
+	return out
+}
+
+// fieldErrors translates a binding error into per-field errors
+func fieldErrors(err error) []model.FieldError {
+	var verrs validator.ValidationErrors
+	if errors.As(err, &verrs) {
+		out := make([]model.FieldError, 0, len(verrs))
+		for _, fe := range verrs {
+			out = append(out, model.FieldError{
+				Field:   fieldPath(fe),
+				Code:    fe.Tag(),
+				Message: fieldMessage(fe),
+			})
+		}
+		return out
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return []model.FieldError{{
+			Field:   typeErr.Field,
+			Code:    codeType,
+			Message: fmt.Sprintf("must be of type %s", typeErr.Type),
+		}}
+	}
+
+	return []model.FieldError{{Code: codeMalformed, Message: "request body is not valid JSON"}}
+}
+
+// fieldPath drops the top-level struct name from the validator namespace
+func fieldPath(fe validator.FieldError) string {
+	ns := fe.Namespace()
+	if i := strings.Index(ns, "."); i >= 0 {
+		return ns[i+1:]
+	}
+	return fe.Field()
+}
+
+// fieldMessage describes a single failed validation rule
+func fieldMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "gt":
+		return fmt.Sprintf("must be greater than %s", fe.Param())
+	case "min":
+		if fe.Kind() == reflect.Slice {
+			return fmt.Sprintf("must have at least %s items", fe.Param())
+		}
+		return fmt.Sprintf("must be at least %s characters", fe.Param())
+	case "max":
+		if fe.Kind() == reflect.Slice {
+			return fmt.Sprintf("must have at most %s items", fe.Param())
+		}
+		return fmt.Sprintf("must be at most %s characters", fe.Param())
+	case "lte":
+		return fmt.Sprintf("must be at most %s", fe.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of: %s", strings.ReplaceAll(fe.Param(), " ", ", "))
+	case "listing_type":
+		types := make([]string, len(model.ListingTypes))
+		for i, t := range model.ListingTypes {
+			types[i] = string(t)
+		}
+		return fmt.Sprintf("must be one of: %s", strings.Join(types, ", "))
+	case "listing_price":
+		price, _ := fe.Value().(float64)
+		return domainMessage(model.ValidatePrice(price))
+	case "user_name":
+		name, _ := fe.Value().(string)
+		_, err := model.NormalizeUserName(name)
+		return domainMessage(err)
+	case "webhook_url":
+		raw, _ := fe.Value().(string)
+		return domainMessage(model.ValidateWebhookURL(raw))
+	case "webhook_event":
+		events := make([]string, len(model.WebhookEventTypes))
+		for i, t := range model.WebhookEventTypes {
+			events[i] = string(t)
+		}
+		return fmt.Sprintf("must be one of: %s", strings.Join(events, ", "))
+	default:
+		return fmt.Sprintf("failed %s validation", fe.Tag())
+	}
+}
+
+// domainMessage strips the ErrInvalidInput prefix from a model validation error
+func domainMessage(err error) string {
+	if err == nil {
+		return "is invalid"
+	}
+	return strings.TrimPrefix(err.Error(), model.ErrInvalidInput.Error()+": ")
+}
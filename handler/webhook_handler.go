@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"public-api/model"
+	"public-api/webhook"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WebhookHandler serves the admin API for webhook subscriptions
+type WebhookHandler struct {
+	dispatcher *webhook.Dispatcher
+}
+
+// NewWebhookHandler constructs a new WebhookHandler
+func NewWebhookHandler(d *webhook.Dispatcher) *WebhookHandler {
+	return &WebhookHandler{dispatcher: d}
+}
+
+// CreateWebhook handles POST /public-api/admin/webhooks
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	var req model.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, invalidRequest(err))
+		return
+	}
+
+	w, err := h.dispatcher.Create(req.URL, req.Events, req.Secret)
+	if err != nil {
+		webhookError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"webhook": w})
+}
+
+// ListWebhooks handles GET /public-api/admin/webhooks
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"webhooks": h.dispatcher.List()})
+}
+
+// GetWebhook handles GET /public-api/admin/webhooks/:id
+func (h *WebhookHandler) GetWebhook(c *gin.Context) {
+	id, ok := pathID(c, "Invalid webhook id")
+	if !ok {
+		return
+	}
+	w, err := h.dispatcher.Get(id)
+	if err != nil {
+		webhookError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"webhook": w})
+}
+
+// DeleteWebhook handles DELETE /public-api/admin/webhooks/:id
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	id, ok := pathID(c, "Invalid webhook id")
+	if !ok {
+		return
+	}
+	if err := h.dispatcher.Delete(id); err != nil {
+		webhookError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListDeliveries handles GET /public-api/admin/webhooks/:id/deliveries
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	id, ok := pathID(c, "Invalid webhook id")
+	if !ok {
+		return
+	}
+	deliveries, err := h.dispatcher.Deliveries(id)
+	if err != nil {
+		webhookError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"deliveries": deliveries})
+}
+
+// ListDeadLetters handles GET /public-api/admin/dead-letters
+func (h *WebhookHandler) ListDeadLetters(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"dead_letters": h.dispatcher.DeadLetters()})
+}
+
+// RedeliverDeadLetter handles POST /public-api/admin/dead-letters/:id/redeliver
+func (h *WebhookHandler) RedeliverDeadLetter(c *gin.Context) {
+	id, ok := pathID(c, "Invalid dead letter id")
+	if !ok {
+		return
+	}
+	if err := h.dispatcher.Redeliver(id); err != nil {
+		webhookError(c, err)
+		return
+	}
+	c.Status(http.StatusAccepted)
+}
+
+// pathID parses the :id path parameter, replying 400 when it is invalid
+func pathID(c *gin.Context, msg string) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return 0, false
+	}
+	return id, true
+}
+
+func webhookError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, model.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, model.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, model.ErrUnprocessable):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
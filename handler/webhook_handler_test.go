@@ -0,0 +1,164 @@
+package handler_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"public-api/handler"
+	"public-api/model"
+	"public-api/webhook"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// webhookRouter mounts the webhook admin routes on a dispatcher that is not
+// started and has no queue, so every published event is dead-lettered
+func webhookRouter() (*gin.Engine, *webhook.Dispatcher) {
+	cfg := webhook.DefaultConfig()
+	cfg.QueueSize = 0
+	d := webhook.NewDispatcher(cfg)
+	h := handler.NewWebhookHandler(d)
+
+	router := gin.Default()
+	router.POST("/admin/webhooks", h.CreateWebhook)
+	router.GET("/admin/webhooks", h.ListWebhooks)
+	router.GET("/admin/webhooks/:id", h.GetWebhook)
+	router.DELETE("/admin/webhooks/:id", h.DeleteWebhook)
+	router.GET("/admin/webhooks/:id/deliveries", h.ListDeliveries)
+	router.GET("/admin/dead-letters", h.ListDeadLetters)
+	router.POST("/admin/dead-letters/:id/redeliver", h.RedeliverDeadLetter)
+	return router, d
+}
+
+func serveWebhook(router *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+func TestWebhookHandler_CreateWebhook(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		requestBody    string
+		expectedCode   int
+		expectedResult string
+	}{
+		{
+			name:           "success",
+			requestBody:    `{"url":"https://example.com/hook","events":["listing.created"],"secret":"0123456789abcdef"}`,
+			expectedCode:   http.StatusCreated,
+			expectedResult: `"secret":"0123456789abcdef"`,
+		},
+		{
+			name:           "generated secret",
+			requestBody:    `{"url":"https://example.com/hook","events":["user.created"]}`,
+			expectedCode:   http.StatusCreated,
+			expectedResult: `"secret":"`,
+		},
+		{
+			name:           "invalid url",
+			requestBody:    `{"url":"ftp://example.com","events":["listing.created"]}`,
+			expectedCode:   http.StatusBadRequest,
+			expectedResult: `"field":"url"`,
+		},
+		{
+			name:           "unknown event",
+			requestBody:    `{"url":"https://example.com/hook","events":["listing.deleted"]}`,
+			expectedCode:   http.StatusBadRequest,
+			expectedResult: `"field":"events[0]"`,
+		},
+		{
+			name:           "no events",
+			requestBody:    `{"url":"https://example.com/hook","events":[]}`,
+			expectedCode:   http.StatusBadRequest,
+			expectedResult: `"field":"events"`,
+		},
+		{
+			name:           "short secret",
+			requestBody:    `{"url":"https://example.com/hook","events":["listing.created"],"secret":"short"}`,
+			expectedCode:   http.StatusBadRequest,
+			expectedResult: `"field":"secret"`,
+		},
+		{
+			name:           "malformed body",
+			requestBody:    `{"url":`,
+			expectedCode:   http.StatusBadRequest,
+			expectedResult: `"error":"Invalid request"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, _ := webhookRouter()
+
+			resp := serveWebhook(router, http.MethodPost, "/admin/webhooks", tt.requestBody)
+
+			assert.Equal(t, tt.expectedCode, resp.Code)
+			assert.Contains(t, resp.Body.String(), tt.expectedResult)
+		})
+	}
+}
+
+func TestWebhookHandler_ManageWebhooks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router, d := webhookRouter()
+	w, err := d.Create("https://example.com/hook", []model.WebhookEventType{model.WebhookListingCreated}, "")
+	require.NoError(t, err)
+
+	list := serveWebhook(router, http.MethodGet, "/admin/webhooks", "")
+	assert.Equal(t, http.StatusOK, list.Code)
+	assert.Contains(t, list.Body.String(), `"url":"https://example.com/hook"`)
+	assert.NotContains(t, list.Body.String(), `"secret"`)
+
+	get := serveWebhook(router, http.MethodGet, "/admin/webhooks/1", "")
+	assert.Equal(t, http.StatusOK, get.Code)
+	assert.Contains(t, get.Body.String(), `"id":1`)
+
+	deliveries := serveWebhook(router, http.MethodGet, "/admin/webhooks/1/deliveries", "")
+	assert.Equal(t, http.StatusOK, deliveries.Code)
+	assert.JSONEq(t, `{"deliveries":[]}`, deliveries.Body.String())
+
+	assert.Equal(t, http.StatusBadRequest, serveWebhook(router, http.MethodGet, "/admin/webhooks/abc", "").Code)
+	assert.Equal(t, http.StatusNotFound, serveWebhook(router, http.MethodGet, "/admin/webhooks/2", "").Code)
+	assert.Equal(t, http.StatusNotFound, serveWebhook(router, http.MethodGet, "/admin/webhooks/2/deliveries", "").Code)
+
+	assert.Equal(t, http.StatusBadRequest, serveWebhook(router, http.MethodDelete, "/admin/webhooks/0", "").Code)
+	assert.Equal(t, http.StatusNoContent, serveWebhook(router, http.MethodDelete, "/admin/webhooks/1", "").Code)
+	assert.Equal(t, http.StatusNotFound, serveWebhook(router, http.MethodDelete, "/admin/webhooks/1", "").Code)
+
+	_, err = d.Get(w.ID)
+	assert.ErrorIs(t, err, model.ErrNotFound)
+	assert.JSONEq(t, `{"webhooks":[]}`, serveWebhook(router, http.MethodGet, "/admin/webhooks", "").Body.String())
+}
+
+func TestWebhookHandler_RedeliverDeadLetter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router, d := webhookRouter()
+	w, err := d.Create("https://example.com/hook", []model.WebhookEventType{model.WebhookUserCreated}, "")
+	require.NoError(t, err)
+	d.PublishUserCreation("evt_1", model.User{ID: 1})
+
+	dead := serveWebhook(router, http.MethodGet, "/admin/dead-letters", "")
+	assert.Equal(t, http.StatusOK, dead.Code)
+	assert.Contains(t, dead.Body.String(), `"last_error":"delivery queue full"`)
+
+	assert.Equal(t, http.StatusBadRequest, serveWebhook(router, http.MethodPost, "/admin/dead-letters/abc/redeliver", "").Code)
+	assert.Equal(t, http.StatusNotFound, serveWebhook(router, http.MethodPost, "/admin/dead-letters/9/redeliver", "").Code)
+
+	// The queue is still full, so the event is dead-lettered again
+	assert.Equal(t, http.StatusAccepted, serveWebhook(router, http.MethodPost, "/admin/dead-letters/1/redeliver", "").Code)
+	require.Len(t, d.DeadLetters(), 1)
+	assert.Equal(t, http.StatusNotFound, serveWebhook(router, http.MethodPost, "/admin/dead-letters/1/redeliver", "").Code)
+
+	require.NoError(t, d.Delete(w.ID))
+	gone := serveWebhook(router, http.MethodPost, "/admin/dead-letters/2/redeliver", "")
+	assert.Equal(t, http.StatusUnprocessableEntity, gone.Code)
+	assert.Contains(t, gone.Body.String(), "no longer exists")
+}
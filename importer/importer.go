@@ -0,0 +1,241 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"public-api/model"
+	"public-api/service"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrTooManyJobs is returned by Submit when as many jobs as allowed are
+// queued or running
+var ErrTooManyJobs = errors.New("too many imports in progress")
+
+// Row is a validated CSV row ready to be created
+type Row struct {
+	Line    int
+	Listing model.Listing
+}
+
+// Importer runs listing imports as background jobs and keeps their status
+// in memory. Jobs are lost on restart.
+type Importer struct {
+	listings  service.ListingService
+	chunkSize int
+	maxJobs   int
+	maxActive int
+	now       func() time.Time
+
+	mu     sync.Mutex
+	active int
+	lastID int64
+	jobs   map[int64]*model.ImportJob
+	order  []int64
+}
+
+// Option configures an Importer
+type Option func(*Importer)
+
+// WithChunkSize sets how many rows are handed to the listing service at a
+// time. Progress is reported after each chunk.
+func WithChunkSize(n int) Option {
+	return func(im *Importer) {
+		im.chunkSize = n
+	}
+}
+
+// WithMaxJobs sets how many jobs are remembered. The oldest finished jobs
+// are forgotten first.
+func WithMaxJobs(n int) Option {
+	return func(im *Importer) {
+		im.maxJobs = n
+	}
+}
+
+// WithMaxActive sets how many jobs may be queued or running at once. Further
+// submissions fail with ErrTooManyJobs until one completes.
+func WithMaxActive(n int) Option {
+	return func(im *Importer) {
+		im.maxActive = n
+	}
+}
+
+// New returns an importer creating listings through listings
+func New(listings service.ListingService, opts ...Option) *Importer {
+	im := &Importer{
+		listings:  listings,
+		chunkSize: 20,
+		maxJobs:   100,
+		maxActive: 4,
+		now:       time.Now,
+		jobs:      make(map[int64]*model.ImportJob),
+	}
+	for _, opt := range opts {
+		opt(im)
+	}
+	if im.chunkSize < 1 {
+		im.chunkSize = 1
+	}
+	if im.maxActive < 1 {
+		im.maxActive = 1
+	}
+	if im.chunkSize > model.MaxListingBatchSize {
+		im.chunkSize = model.MaxListingBatchSize
+	}
+	return im
+}
+
+// Submit records a job for rows and the rows that already failed
+// validation, then creates the valid rows in the background. A dry run only
+// reports the validation result. The job keeps running after ctx is
+// canceled. It fails with ErrTooManyJobs when the job would have to run
+// while too many others are in progress.
+func (im *Importer) Submit(ctx context.Context, rows []Row, invalid []model.ImportRowError, dryRun bool) (model.ImportJob, error) {
+	failed := make(map[int]struct{}, len(invalid))
+	for _, e := range invalid {
+		failed[e.Line] = struct{}{}
+	}
+
+	im.mu.Lock()
+	defer im.mu.Unlock()
+
+	runs := !dryRun && len(rows) > 0
+	if runs && im.active >= im.maxActive {
+		return model.ImportJob{}, ErrTooManyJobs
+	}
+
+	im.lastID++
+	job := &model.ImportJob{
+		ID:        im.lastID,
+		Status:    model.ImportQueued,
+		DryRun:    dryRun,
+		Total:     len(rows) + len(failed),
+		Valid:     len(rows),
+		Processed: len(failed),
+		Failed:    len(failed),
+		Errors:    append([]model.ImportRowError{}, invalid...),
+		CreatedAt: im.now().Unix(),
+	}
+	im.jobs[job.ID] = job
+	im.order = append(im.order, job.ID)
+	im.evict()
+
+	if !runs {
+		job.Status = model.ImportCompleted
+		job.Processed = job.Total
+		job.FinishedAt = job.CreatedAt
+		return snapshot(job), nil
+	}
+
+	im.active++
+	go im.run(context.WithoutCancel(ctx), job.ID, rows)
+	return snapshot(job), nil
+}
+
+// Get returns the current state of job id
+func (im *Importer) Get(id int64) (*model.ImportJob, error) {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+
+	job, ok := im.jobs[id]
+	if !ok {
+		return nil, fmt.Errorf("import %d: %w", id, model.ErrNotFound)
+	}
+	s := snapshot(job)
+	return &s, nil
+}
+
+// run creates rows chunk by chunk, updating job id after each
+func (im *Importer) run(ctx context.Context, id int64, rows []Row) {
+	defer im.finish(id)
+	im.update(id, func(job *model.ImportJob) {
+		job.Status = model.ImportRunning
+	})
+
+	for start := 0; start < len(rows); start += im.chunkSize {
+		chunk := rows[start:min(start+im.chunkSize, len(rows))]
+		listings := make([]model.Listing, len(chunk))
+		for i, r := range chunk {
+			listings[i] = r.Listing
+		}
+
+		created, errs, err := im.listings.CreateListings(ctx, listings)
+		im.update(id, func(job *model.ImportJob) {
+			job.Processed += len(chunk)
+			for i, r := range chunk {
+				rowErr := err
+				if rowErr == nil {
+					rowErr = errs[i]
+				}
+				if rowErr == nil && created[i] != nil {
+					job.Created++
+					continue
+				}
+				if rowErr == nil {
+					rowErr = errors.New("listing-service returned no listing")
+				}
+				job.Failed++
+				job.Errors = append(job.Errors, model.ImportRowError{Line: r.Line, Code: createErrorCode(rowErr), Message: rowErr.Error()})
+			}
+			sort.SliceStable(job.Errors, func(a, b int) bool { return job.Errors[a].Line < job.Errors[b].Line })
+		})
+	}
+}
+
+// finish completes job id and releases its slot under one lock, so a
+// completed job never still counts as active. run defers it so the slot is
+// released however it exits.
+func (im *Importer) finish(id int64) {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	im.active--
+	// Running jobs are never evicted; the slot is released regardless
+	if job, ok := im.jobs[id]; ok {
+		job.Status = model.ImportCompleted
+		job.FinishedAt = im.now().Unix()
+	}
+}
+
+func (im *Importer) update(id int64, fn func(job *model.ImportJob)) {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	if job, ok := im.jobs[id]; ok {
+		fn(job)
+	}
+}
+
+// evict forgets the oldest finished jobs beyond maxJobs. The caller must
+// hold im.mu.
+func (im *Importer) evict() {
+	for i := 0; len(im.jobs) > im.maxJobs && i < len(im.order); {
+		id := im.order[i]
+		if im.jobs[id].Status != model.ImportCompleted {
+			i++
+			continue
+		}
+		delete(im.jobs, id)
+		im.order = append(im.order[:i], im.order[i+1:]...)
+	}
+}
+
+// createErrorCode classifies why the listing-service did not create a row
+func createErrorCode(err error) string {
+	switch {
+	case errors.Is(err, model.ErrInvalidInput):
+		return "invalid"
+	case errors.Is(err, model.ErrUnprocessable):
+		return "unprocessable"
+	default:
+		return "failed"
+	}
+}
+
+func snapshot(job *model.ImportJob) model.ImportJob {
+	s := *job
+	s.Errors = append([]model.ImportRowError{}, job.Errors...)
+	return s
+}
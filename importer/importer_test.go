@@ -0,0 +1,136 @@
+package importer_test
+
+import (
+	"context"
+	"errors"
+	"public-api/importer"
+	"public-api/mocks"
+	"public-api/model"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func rows(lines ...int) []importer.Row {
+	out := make([]importer.Row, len(lines))
+	for i, line := range lines {
+		out[i] = importer.Row{Line: line, Listing: model.Listing{UserID: int64(line), ListingType: "sale", Price: 100}}
+	}
+	return out
+}
+
+func waitCompleted(t *testing.T, im *importer.Importer, id int64) *model.ImportJob {
+	var job *model.ImportJob
+	require.Eventually(t, func() bool {
+		var err error
+		job, err = im.Get(id)
+		return err == nil && job.Status == model.ImportCompleted
+	}, time.Second, time.Millisecond)
+	return job
+}
+
+func TestImporterCreatesRowsInChunks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ls := mocks.NewMockListingService(ctrl)
+	im := importer.New(ls, importer.WithChunkSize(2))
+
+	gomock.InOrder(
+		ls.EXPECT().CreateListings(gomock.Any(), []model.Listing{rows(2)[0].Listing, rows(4)[0].Listing}).
+			Return([]*model.Listing{{ID: 1}, nil}, []error{nil, model.ErrUnprocessable}, nil),
+		ls.EXPECT().CreateListings(gomock.Any(), []model.Listing{rows(5)[0].Listing}).
+			Return([]*model.Listing{nil}, []error{errors.New("listing-service down")}, nil),
+	)
+
+	invalid := []model.ImportRowError{
+		{Line: 3, Field: "price", Code: "required", Message: "is required"},
+		{Line: 3, Field: "user_id", Code: "required", Message: "is required"},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	job, err := im.Submit(ctx, rows(2, 4, 5), invalid, false)
+	cancel() // the job outlives the request
+	require.NoError(t, err)
+
+	assert.Equal(t, model.ImportQueued, job.Status)
+	assert.Equal(t, 4, job.Total)
+	assert.Equal(t, 3, job.Valid)
+	assert.Equal(t, 1, job.Processed)
+	assert.Equal(t, 1, job.Failed)
+
+	done := waitCompleted(t, im, job.ID)
+	assert.Equal(t, 4, done.Processed)
+	assert.Equal(t, 1, done.Created)
+	assert.Equal(t, 3, done.Failed)
+	assert.NotZero(t, done.FinishedAt)
+	assert.Equal(t, []model.ImportRowError{
+		{Line: 3, Field: "price", Code: "required", Message: "is required"},
+		{Line: 3, Field: "user_id", Code: "required", Message: "is required"},
+		{Line: 4, Code: "unprocessable", Message: "unprocessable request"},
+		{Line: 5, Code: "failed", Message: "listing-service down"},
+	}, done.Errors)
+}
+
+func TestImporterDryRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	im := importer.New(mocks.NewMockListingService(ctrl)) // nothing is created
+
+	job, err := im.Submit(context.Background(), rows(2, 3), []model.ImportRowError{{Line: 4, Code: "required"}}, true)
+	require.NoError(t, err)
+
+	assert.Equal(t, model.ImportCompleted, job.Status)
+	assert.True(t, job.DryRun)
+	assert.Equal(t, 3, job.Total)
+	assert.Equal(t, 2, job.Valid)
+	assert.Equal(t, 3, job.Processed)
+	assert.Equal(t, 0, job.Created)
+	assert.Equal(t, 1, job.Failed)
+}
+
+func TestImporterForgetsOldestFinishedJobs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	im := importer.New(mocks.NewMockListingService(ctrl), importer.WithMaxJobs(2))
+
+	first, _ := im.Submit(context.Background(), nil, nil, true)
+	second, _ := im.Submit(context.Background(), nil, nil, true)
+	third, _ := im.Submit(context.Background(), nil, nil, true)
+
+	_, err := im.Get(first.ID)
+	assert.ErrorIs(t, err, model.ErrNotFound)
+	for _, id := range []int64{second.ID, third.ID} {
+		_, err := im.Get(id)
+		assert.NoError(t, err)
+	}
+}
+
+func TestImporterLimitsActiveJobs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ls := mocks.NewMockListingService(ctrl)
+	im := importer.New(ls, importer.WithMaxActive(1))
+
+	release := make(chan struct{})
+	ls.EXPECT().CreateListings(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(context.Context, []model.Listing) ([]*model.Listing, []error, error) {
+			<-release
+			return []*model.Listing{{ID: 1}}, []error{nil}, nil
+		}).Times(2)
+
+	running, err := im.Submit(context.Background(), rows(2), nil, false)
+	require.NoError(t, err)
+
+	_, err = im.Submit(context.Background(), rows(2), nil, false)
+	assert.ErrorIs(t, err, importer.ErrTooManyJobs)
+	_, err = im.Submit(context.Background(), rows(2), nil, true)
+	assert.NoError(t, err, "dry runs do not count")
+
+	close(release)
+	waitCompleted(t, im, running.ID)
+	next, err := im.Submit(context.Background(), rows(2), nil, false)
+	require.NoError(t, err)
+	waitCompleted(t, im, next.ID)
+}
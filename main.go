@@ -1,13 +1,26 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log"
+	"net"
 	"public-api/config"
 
+	"public-api/auth"
 	"public-api/client"
+	"public-api/event"
+	"public-api/graph"
+	"public-api/grpcserver"
 	"public-api/handler"
+	"public-api/importer"
+	"public-api/middleware"
+	"public-api/notify"
+	"public-api/outbox"
 	"public-api/router"
 	"public-api/service"
+	"public-api/stream"
+	"public-api/webhook"
 )
 
 func main() {
@@ -15,22 +28,236 @@ func main() {
 	cfg := config.Load()
 
 	// Init clients
-	listingClient := client.NewListingClient(cfg.ListingServiceURL)
-	userClient := client.NewUserClient(cfg.UserServiceURL)
+	listingClient, err := newListingClient(cfg)
+	if err != nil {
+		log.Fatalf("failed to init listing client: %v", err)
+	}
+	baseUserClient, err := newUserClient(cfg)
+	if err != nil {
+		log.Fatalf("failed to init user client: %v", err)
+	}
+	userClient := client.NewCachedUserClient(baseUserClient, cfg.UserCacheTTL)
 
 	// Init services
-	listingService := service.NewListingService(listingClient, userClient)
-	userService := service.NewUserService(userClient)
+	hub := stream.NewHub(cfg.StreamBacklog, cfg.StreamBuffer)
+	notifications := notify.NewHub(notify.Limits{
+		MaxConnections:        cfg.WSMaxConnections,
+		MaxConnectionsPerUser: cfg.WSMaxConnectionsPerUser,
+		MaxSubscriptions:      cfg.WSMaxSubscriptions,
+		SendBuffer:            cfg.WSSendBuffer,
+	})
+	webhooks := webhook.NewDispatcher(webhook.Config{
+		Workers:        cfg.WebhookWorkers,
+		QueueSize:      cfg.WebhookQueueSize,
+		MaxAttempts:    cfg.WebhookMaxAttempts,
+		InitialBackoff: cfg.WebhookInitialBackoff,
+		MaxBackoff:     cfg.WebhookMaxBackoff,
+		Timeout:        cfg.WebhookTimeout,
+		LogSize:        cfg.WebhookLogSize,
+		DeadLetterSize: cfg.WebhookDeadLetterSize,
+
+		AllowPrivateTargets: cfg.WebhookAllowPrivate,
+	})
+	webhooks.Start(context.Background())
+
+	var responseCache *middleware.ResponseCache
+	if cfg.ResponseCacheSize > 0 {
+		responseCache = middleware.NewResponseCache(cfg.ResponseCacheSize)
+	}
+
+	bus := event.NewBus()
+	subscribeConsumers(bus, hub, notifications, webhooks, cfg.EventDedupSize)
+	if responseCache != nil {
+		bus.Subscribe("cache", event.All, cacheInvalidation(responseCache))
+	}
+
+	// Events go through the outbox so they survive a crash after the
+	// downstream service confirmed the change
+	store, err := outbox.OpenBolt(cfg.OutboxPath)
+	if err != nil {
+		log.Fatalf("failed to init outbox: %v", err)
+	}
+	defer store.Close()
+	events := outbox.New(store, bus, outbox.WithPollInterval(cfg.OutboxPollInterval))
+	go events.Run(context.Background())
+
+	// Writes invalidate the response cache before they are answered, and
+	// again when the outbox relays their event as a backstop
+	var publisher event.Publisher = events
+	if responseCache != nil {
+		publisher = invalidatingPublisher{next: events, invalidate: cacheInvalidation(responseCache)}
+	}
+
+	listingService := service.NewListingService(listingClient, userClient,
+		service.WithOwnerVerification(cfg.VerifyListingUser),
+		service.WithBatchConcurrency(cfg.ListingBatchConcurrency),
+		service.WithExportPageSize(cfg.ListingExportPageSize),
+		service.WithPublisher(publisher))
+	userService := service.NewUserService(userClient,
+		service.WithUserPublisher(publisher))
 
 	// Init handlers
 	listingHandler := handler.NewListingHandler(listingService)
 	userHandler := handler.NewUserHandler(userService)
 
+	executor, err := graph.NewExecutor(userService, listingService,
+		graph.WithMaxDepth(cfg.GraphQLMaxDepth),
+		graph.WithMaxComplexity(cfg.GraphQLMaxCost))
+	if err != nil {
+		log.Fatalf("failed to init graphql: %v", err)
+	}
+	graphQLHandler := handler.NewGraphQLHandler(executor)
+	streamHandler := handler.NewStreamHandler(hub, cfg.StreamHeartbeat)
+
+	if cfg.WSAuthSecret == "" {
+		log.Println("WS_AUTH_SECRET is not set, WebSocket notifications will reject every connection")
+	}
+	notificationHandler := handler.NewNotificationHandler(notifications,
+		auth.NewTokenAuthenticator(cfg.WSAuthSecret), cfg.WSPingInterval)
+	webhookHandler := handler.NewWebhookHandler(webhooks)
+	onboardingHandler := handler.NewOnboardingHandler(service.NewOnboardingService(userService, listingService))
+	importHandler := handler.NewImportHandler(importer.New(listingService,
+		importer.WithMaxJobs(cfg.ImportMaxJobs),
+		importer.WithMaxActive(cfg.ImportMaxActive)),
+		int64(cfg.ImportMaxFileSize))
+	if cfg.AdminToken == "" {
+		log.Println("ADMIN_API_TOKEN is not set, the admin API will reject every request")
+	}
+
+	// Serve gRPC on its own port next to REST
+	grpcServer := grpcserver.NewServer(userService, listingService)
+	lis, err := net.Listen("tcp", ":"+cfg.GRPCPort)
+	if err != nil {
+		log.Fatalf("failed to listen for gRPC: %v", err)
+	}
+	go func() {
+		log.Printf("🚀 gRPC API is running at :%s", cfg.GRPCPort)
+		if err := grpcServer.Serve(lis); err != nil {
+			log.Fatalf("failed to serve gRPC: %v", err)
+		}
+	}()
+
 	// Setup and run router
-	r := router.SetupRouter(userHandler, listingHandler)
+	r := router.SetupRouter(router.Handlers{
+		User:         userHandler,
+		Listing:      listingHandler,
+		GraphQL:      graphQLHandler,
+		Stream:       streamHandler,
+		Notification: notificationHandler,
+		Webhook:      webhookHandler,
+		Onboarding:   onboardingHandler,
+		Import:       importHandler,
+	},
+		router.WithResponseValidation(cfg.ValidateResponses),
+		router.WithV1Sunset(cfg.V1DeprecatedAt, cfg.V1SunsetAt),
+		router.WithAdminToken(cfg.AdminToken),
+		router.WithCompression(cfg.CompressionMinSize),
+		router.WithResponseCache(responseCache, map[string]middleware.CachePolicy{
+			"/listings": {
+				TTL:                  cfg.ListingsCacheTTL,
+				StaleWhileRevalidate: cfg.CacheStaleWhileRevalidate,
+				Tags:                 []string{cacheTagListings},
+			},
+			"/listings/:id": {
+				TTL:                  cfg.ListingCacheTTL,
+				StaleWhileRevalidate: cfg.CacheStaleWhileRevalidate,
+				Tags:                 []string{cacheTagListings},
+			},
+		}))
 
 	log.Println("🚀 Public API is running at :8080")
 	if err := r.Run(":8080"); err != nil {
 		log.Fatalf("failed to start server: %v", err)
 	}
 }
+
+// newListingClient picks the listing-service transport from the config
+func newListingClient(cfg config.Config) (client.ListingClient, error) {
+	switch cfg.ListingServiceTransport {
+	case config.TransportHTTP:
+		return client.NewListingClient(cfg.ListingServiceURL), nil
+	case config.TransportGRPC:
+		conn, err := client.DialGRPC(cfg.ListingServiceGRPCAddr)
+		if err != nil {
+			return nil, err
+		}
+		return client.NewGRPCListingClient(conn), nil
+	default:
+		return nil, fmt.Errorf("unknown transport %q", cfg.ListingServiceTransport)
+	}
+}
+
+// newUserClient picks the user-service transport from the config
+func newUserClient(cfg config.Config) (client.UserClient, error) {
+	switch cfg.UserServiceTransport {
+	case config.TransportHTTP:
+		return client.NewUserClient(cfg.UserServiceURL), nil
+	case config.TransportGRPC:
+		conn, err := client.DialGRPC(cfg.UserServiceGRPCAddr)
+		if err != nil {
+			return nil, err
+		}
+		return client.NewGRPCUserClient(conn), nil
+	default:
+		return nil, fmt.Errorf("unknown transport %q", cfg.UserServiceTransport)
+	}
+}
+
+// subscribeConsumers feeds domain events to the live channels and webhooks.
+// They only queue work without blocking, so they run synchronously. The
+// outbox may relay an event twice, so each consumer drops event IDs it has
+// already seen.
+func subscribeConsumers(bus *event.Bus, hub *stream.Hub, notifications *notify.Hub, webhooks *webhook.Dispatcher, dedupSize int) {
+	bus.Subscribe("stream", event.NameListingCreated, event.Deduplicate(dedupSize, event.Typed(func(_ context.Context, e event.ListingCreated) error {
+		hub.Publish(e.Listing)
+		return nil
+	})))
+	bus.Subscribe("notify", event.All, event.Deduplicate(dedupSize, func(_ context.Context, e event.Event) error {
+		switch e := e.(type) {
+		case event.ListingCreated:
+			notifications.Publish(e.Listing)
+		case event.UserUpdated:
+			notifications.PublishUserUpdate(e.User)
+		}
+		return nil
+	}))
+	bus.Subscribe("webhook", event.All, event.Deduplicate(dedupSize, func(_ context.Context, e event.Event) error {
+		switch e := e.(type) {
+		case event.ListingCreated:
+			webhooks.Publish(e.EventID(), e.Listing)
+		case event.UserCreated:
+			webhooks.PublishUserCreation(e.EventID(), e.User)
+		}
+		return nil
+	}))
+}
+
+// cacheTagListings tags cached responses built from listings and their owners
+const cacheTagListings = "listings"
+
+// cacheInvalidation drops cached listings once a listing is created or an
+// owner embedded in them is renamed. Invalidating is idempotent, so
+// duplicate events need no filtering.
+func cacheInvalidation(rc *middleware.ResponseCache) event.Handler {
+	return func(_ context.Context, e event.Event) error {
+		switch e.(type) {
+		case event.ListingCreated, event.UserUpdated:
+			rc.Invalidate(cacheTagListings)
+		}
+		return nil
+	}
+}
+
+// invalidatingPublisher invalidates the response cache before passing events
+// on, so a read right after a write is not served from the cache while the
+// outbox relays the event
+type invalidatingPublisher struct {
+	next       event.Publisher
+	invalidate event.Handler
+}
+
+// Publish implements event.Publisher
+func (p invalidatingPublisher) Publish(ctx context.Context, e event.Event) {
+	_ = p.invalidate(ctx, e)
+	p.next.Publish(ctx, e)
+}
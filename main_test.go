@@ -0,0 +1,69 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"public-api/event"
+	"public-api/handler"
+	"public-api/middleware"
+	"public-api/mocks"
+	"public-api/model"
+	"public-api/router"
+	"public-api/service"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreatedListingIsNotServedFromCache(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	lc := mocks.NewMockListingClient(ctrl)
+	uc := mocks.NewMockUserClient(ctrl)
+
+	rc := middleware.NewResponseCache(10)
+	// No subscribers: the relayed event must not be what invalidates
+	publisher := invalidatingPublisher{next: event.NewBus(), invalidate: cacheInvalidation(rc)}
+	ls := service.NewListingService(lc, uc, service.WithPublisher(publisher))
+	r := router.SetupRouter(router.Handlers{
+		Listing: handler.NewListingHandler(ls),
+	}, router.WithResponseCache(rc, map[string]middleware.CachePolicy{
+		"/listings": {TTL: time.Minute, Tags: []string{cacheTagListings}},
+	}))
+
+	owner := &model.User{ID: 1, Name: "Jane"}
+	existing := model.Listing{ID: 1, UserID: 1, ListingType: "rent", Price: 100}
+	created := model.Listing{ID: 2, UserID: 1, ListingType: "sale", Price: 200}
+	gomock.InOrder(
+		lc.EXPECT().FetchListings(gomock.Any()).Return([]model.Listing{existing}, nil),
+		lc.EXPECT().CreateListing(gomock.Any()).Return(&created, nil),
+		lc.EXPECT().FetchListings(gomock.Any()).Return([]model.Listing{existing, created}, nil),
+	)
+	uc.EXPECT().FetchUsersByIDs(gomock.Any()).Return(map[int64]*model.User{1: owner}, nil).Times(2)
+	uc.EXPECT().FetchUserByID(int64(1)).Return(owner, nil)
+
+	get := func() *httptest.ResponseRecorder {
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/api/v1/listings", nil))
+		return resp
+	}
+
+	require.Equal(t, "MISS", get().Header().Get("X-Cache"))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/listings",
+		strings.NewReader(`{"user_id":1,"listing_type":"sale","price":200}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+	require.Equal(t, http.StatusCreated, resp.Code)
+
+	after := get()
+	assert.Equal(t, "MISS", after.Header().Get("X-Cache"))
+	assert.Contains(t, after.Body.String(), `"id":2`)
+}
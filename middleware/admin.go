@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"public-api/model"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireAdminToken only lets through requests carrying
+// "Authorization: Bearer <token>". With an empty token every request is
+// rejected, so the admin API stays closed until one is configured.
+func RequireAdminToken(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		got, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if token == "" || !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, model.ErrorResponse{Error: "Invalid admin token"})
+			return
+		}
+		c.Next()
+	}
+}
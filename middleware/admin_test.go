@@ -0,0 +1,46 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"public-api/middleware"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireAdminToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name         string
+		token        string
+		header       string
+		expectedCode int
+	}{
+		{name: "valid token", token: "s3cret", header: "Bearer s3cret", expectedCode: http.StatusOK},
+		{name: "wrong token", token: "s3cret", header: "Bearer nope", expectedCode: http.StatusUnauthorized},
+		{name: "missing header", token: "s3cret", expectedCode: http.StatusUnauthorized},
+		{name: "other scheme", token: "s3cret", header: "Basic s3cret", expectedCode: http.StatusUnauthorized},
+		{name: "not configured", token: "", header: "Bearer ", expectedCode: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.GET("/admin", middleware.RequireAdminToken(tt.token), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedCode, resp.Code)
+		})
+	}
+}
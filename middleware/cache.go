@@ -0,0 +1,283 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Values of the X-Cache header telling how a response was served
+const (
+	cacheHit   = "HIT"
+	cacheStale = "STALE"
+	cacheMiss  = "MISS"
+)
+
+// CachePolicy sets how the responses of one route are cached
+type CachePolicy struct {
+	// TTL is how long a response is served from the cache
+	TTL time.Duration
+	// StaleWhileRevalidate is how long after TTL a response is still served
+	// while it is refreshed in the background
+	StaleWhileRevalidate time.Duration
+	// Tags name the data a response is built from, for Invalidate
+	Tags []string
+	// PerPrincipal keys responses by the Authorization header as well and
+	// keeps them out of shared caches
+	PerPrincipal bool
+}
+
+// cacheControl is the Cache-Control header sent with cacheable responses
+func (p CachePolicy) cacheControl() string {
+	if p.PerPrincipal {
+		return "private, max-age=" + seconds(p.TTL)
+	}
+	cc := "public, max-age=" + seconds(p.TTL)
+	if p.StaleWhileRevalidate > 0 {
+		cc += ", stale-while-revalidate=" + seconds(p.StaleWhileRevalidate)
+	}
+	return cc
+}
+
+// ResponseCache keeps successful GET responses in memory, keyed by path,
+// query and Accept header. Entries are dropped when their data changes
+// through Invalidate; copies kept by CDNs only expire with max-age.
+type ResponseCache struct {
+	maxEntries int
+	handler    http.Handler
+
+	mu         sync.Mutex
+	entries    map[string]*cacheEntry
+	generation uint64
+	inFlight   sync.WaitGroup
+}
+
+// cacheEntry is one cached response
+type cacheEntry struct {
+	header     http.Header
+	body       []byte
+	storedAt   time.Time
+	policy     CachePolicy
+	refreshing bool
+}
+
+func (e *cacheEntry) age(now time.Time) time.Duration {
+	return now.Sub(e.storedAt)
+}
+
+// revalidating marks the requests a ResponseCache makes to refresh entries
+type revalidating struct{}
+
+// NewResponseCache creates a cache holding at most maxEntries responses.
+// When it is full, expired responses are dropped first, then the oldest.
+func NewResponseCache(maxEntries int) *ResponseCache {
+	if maxEntries < 1 {
+		maxEntries = 1
+	}
+	return &ResponseCache{maxEntries: maxEntries, entries: make(map[string]*cacheEntry)}
+}
+
+// RevalidateWith refreshes stale responses by replaying their request on h,
+// usually the router the cache is mounted on. Without it, stale responses
+// are not served.
+func (rc *ResponseCache) RevalidateWith(h http.Handler) {
+	rc.handler = h
+}
+
+// Cache serves GET requests from the cache under policy p. Responses other
+// than a 200 with a body, and responses flushed while being written, are
+// not cached.
+func (rc *ResponseCache) Cache(p CachePolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet || c.GetHeader("Upgrade") != "" {
+			c.Next()
+			return
+		}
+
+		key := cacheKey(c.Request, p.PerPrincipal)
+		if c.Request.Context().Value(revalidating{}) == nil {
+			if e, state, age := rc.lookup(key, c.Request); e != nil {
+				h := c.Writer.Header()
+				for name, values := range e.header {
+					h[name] = slices.Clone(values)
+				}
+				h.Set("Cache-Control", e.policy.cacheControl())
+				h.Set("Age", seconds(age))
+				h.Set("X-Cache", state)
+				c.Writer.WriteHeader(http.StatusOK)
+				_, _ = c.Writer.Write(e.body)
+				c.Abort()
+				return
+			}
+		}
+
+		rc.fill(c, key, p)
+	}
+}
+
+// Invalidate drops every response tagged with one of tags. Responses being
+// built meanwhile are not stored, as they may predate the change.
+func (rc *ResponseCache) Invalidate(tags ...string) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	rc.generation++
+	for key, e := range rc.entries {
+		if slices.ContainsFunc(e.policy.Tags, func(t string) bool { return slices.Contains(tags, t) }) {
+			delete(rc.entries, key)
+		}
+	}
+}
+
+// Wait blocks until every background revalidation started so far has
+// finished
+func (rc *ResponseCache) Wait() {
+	rc.inFlight.Wait()
+}
+
+// lookup returns the entry to serve for key with its X-Cache state and age.
+// A stale entry starts a revalidation unless one is running already.
+func (rc *ResponseCache) lookup(key string, r *http.Request) (*cacheEntry, string, time.Duration) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	e, ok := rc.entries[key]
+	if !ok {
+		return nil, "", 0
+	}
+	now := time.Now()
+	age := e.age(now)
+	switch {
+	case age < e.policy.TTL:
+		return e, cacheHit, age
+	case age < e.policy.TTL+e.policy.StaleWhileRevalidate && rc.handler != nil:
+		if !e.refreshing {
+			e.refreshing = true
+			rc.revalidate(r, e)
+		}
+		return e, cacheStale, age
+	default:
+		delete(rc.entries, key)
+		return nil, "", 0
+	}
+}
+
+// revalidate replays r in the background so the cache middleware of its
+// route stores a fresh response
+func (rc *ResponseCache) revalidate(r *http.Request, e *cacheEntry) {
+	req := r.Clone(context.WithValue(context.WithoutCancel(r.Context()), revalidating{}, true))
+	req.Header.Del("Accept-Encoding")
+	req.Header.Del("If-None-Match")
+
+	rc.inFlight.Add(1)
+	go func() {
+		defer rc.inFlight.Done()
+		rc.handler.ServeHTTP(discardWriter{header: make(http.Header)}, req)
+
+		rc.mu.Lock()
+		e.refreshing = false
+		rc.mu.Unlock()
+	}()
+}
+
+// fill runs the handler and caches its response
+func (rc *ResponseCache) fill(c *gin.Context, key string, p CachePolicy) {
+	rc.mu.Lock()
+	generation := rc.generation
+	rc.mu.Unlock()
+
+	original := c.Writer
+	w := &heldWriter{ResponseWriter: original}
+	c.Writer = w
+	c.Next()
+	c.Writer = original
+
+	if w.streaming {
+		return
+	}
+	if w.Status() == http.StatusOK && len(w.body) > 0 {
+		rc.store(key, generation, &cacheEntry{
+			header:   original.Header().Clone(),
+			body:     w.body,
+			storedAt: time.Now(),
+			policy:   p,
+		})
+		original.Header().Set("Cache-Control", p.cacheControl())
+		original.Header().Set("X-Cache", cacheMiss)
+	}
+	if len(w.body) > 0 {
+		_, _ = original.Write(w.body)
+	}
+}
+
+// store adds e unless the cache was invalidated since generation
+func (rc *ResponseCache) store(key string, generation uint64, e *cacheEntry) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if generation != rc.generation {
+		return
+	}
+	if _, ok := rc.entries[key]; !ok && len(rc.entries) >= rc.maxEntries {
+		rc.evict(e.storedAt)
+	}
+	rc.entries[key] = e
+}
+
+// evict drops expired entries, or the oldest one if none has expired
+func (rc *ResponseCache) evict(now time.Time) {
+	oldest := ""
+	for key, e := range rc.entries {
+		if e.age(now) >= e.policy.TTL+e.policy.StaleWhileRevalidate {
+			delete(rc.entries, key)
+			continue
+		}
+		if oldest == "" || e.storedAt.Before(rc.entries[oldest].storedAt) {
+			oldest = key
+		}
+	}
+	if len(rc.entries) >= rc.maxEntries && oldest != "" {
+		delete(rc.entries, oldest)
+	}
+}
+
+// cacheKey identifies the response to r by its cleaned path, its query with
+// sorted parameters, its Accept header and, per principal, a digest of its
+// Authorization header
+func cacheKey(r *http.Request, perPrincipal bool) string {
+	var b strings.Builder
+	b.WriteString(path.Clean(r.URL.Path))
+	b.WriteString("?")
+	b.WriteString(r.URL.Query().Encode())
+	b.WriteString("\naccept:")
+	b.WriteString(strings.ToLower(strings.Join(strings.Fields(r.Header.Get("Accept")), "")))
+	if perPrincipal {
+		sum := sha256.Sum256([]byte(r.Header.Get("Authorization")))
+		b.WriteString("\nprincipal:")
+		b.WriteString(hex.EncodeToString(sum[:]))
+	}
+	return b.String()
+}
+
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(d / time.Second))
+}
+
+// discardWriter drops the responses of revalidations; the cache middleware
+// has stored them by the time they are written
+type discardWriter struct {
+	header http.Header
+}
+
+func (w discardWriter) Header() http.Header         { return w.header }
+func (w discardWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w discardWriter) WriteHeader(int)             {}
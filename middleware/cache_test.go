@@ -0,0 +1,122 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"public-api/middleware"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func cacheRouter(rc *middleware.ResponseCache, p middleware.CachePolicy, calls *atomic.Int32) *gin.Engine {
+	r := gin.New()
+	r.GET("/listings", rc.Cache(p), func(c *gin.Context) {
+		n := calls.Add(1)
+		c.JSON(http.StatusOK, gin.H{"call": n, "user": c.GetHeader("Authorization")})
+	})
+	r.GET("/missing", rc.Cache(p), func(c *gin.Context) {
+		calls.Add(1)
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+	})
+	rc.RevalidateWith(r)
+	return r
+}
+
+func get(r http.Handler, path string, header ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+	return resp
+}
+
+func TestResponseCache(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var calls atomic.Int32
+	rc := middleware.NewResponseCache(10)
+	r := cacheRouter(rc, middleware.CachePolicy{TTL: time.Minute, StaleWhileRevalidate: 30 * time.Second, Tags: []string{"listings"}}, &calls)
+
+	first := get(r, "/listings?page_num=1&sort_by=price")
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, "MISS", first.Header().Get("X-Cache"))
+	assert.Equal(t, "public, max-age=60, stale-while-revalidate=30", first.Header().Get("Cache-Control"))
+
+	hit := get(r, "/listings?sort_by=price&page_num=1")
+	assert.Equal(t, "HIT", hit.Header().Get("X-Cache"))
+	assert.Equal(t, first.Body.String(), hit.Body.String())
+	assert.Equal(t, "application/json; charset=utf-8", hit.Header().Get("Content-Type"))
+	assert.Equal(t, "0", hit.Header().Get("Age"))
+
+	assert.Equal(t, "MISS", get(r, "/listings?page_num=1&sort_by=price", "Accept", "application/msgpack").Header().Get("X-Cache"))
+	assert.Equal(t, "MISS", get(r, "/listings?page_num=2").Header().Get("X-Cache"))
+	assert.EqualValues(t, 3, calls.Load())
+
+	rc.Invalidate("users")
+	assert.Equal(t, "HIT", get(r, "/listings?page_num=1&sort_by=price").Header().Get("X-Cache"))
+	rc.Invalidate("listings")
+	assert.Equal(t, "MISS", get(r, "/listings?page_num=1&sort_by=price").Header().Get("X-Cache"))
+
+	get(r, "/missing")
+	missing := get(r, "/missing")
+	assert.Equal(t, http.StatusNotFound, missing.Code)
+	assert.Empty(t, missing.Header().Get("X-Cache"))
+	assert.Empty(t, missing.Header().Get("Cache-Control"))
+	assert.EqualValues(t, 6, calls.Load())
+}
+
+func TestResponseCacheStaleWhileRevalidate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var calls atomic.Int32
+	rc := middleware.NewResponseCache(10)
+	r := cacheRouter(rc, middleware.CachePolicy{TTL: 20 * time.Millisecond, StaleWhileRevalidate: time.Minute}, &calls)
+
+	first := get(r, "/listings")
+	time.Sleep(30 * time.Millisecond)
+
+	stale := get(r, "/listings", "Accept-Encoding", "gzip", "If-None-Match", `"old"`)
+	assert.Equal(t, "STALE", stale.Header().Get("X-Cache"))
+	assert.Equal(t, first.Body.String(), stale.Body.String())
+	rc.Wait()
+
+	fresh := get(r, "/listings")
+	assert.Equal(t, "HIT", fresh.Header().Get("X-Cache"))
+	assert.JSONEq(t, `{"call":2,"user":""}`, fresh.Body.String())
+	assert.EqualValues(t, 2, calls.Load())
+}
+
+func TestResponseCachePerPrincipal(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var calls atomic.Int32
+	rc := middleware.NewResponseCache(10)
+	r := cacheRouter(rc, middleware.CachePolicy{TTL: time.Minute, PerPrincipal: true}, &calls)
+
+	jane := get(r, "/listings", "Authorization", "Bearer jane")
+	assert.Equal(t, "private, max-age=60", jane.Header().Get("Cache-Control"))
+	john := get(r, "/listings", "Authorization", "Bearer john")
+	assert.Equal(t, "MISS", john.Header().Get("X-Cache"))
+	assert.NotEqual(t, jane.Body.String(), john.Body.String())
+
+	again := get(r, "/listings", "Authorization", "Bearer jane")
+	assert.Equal(t, "HIT", again.Header().Get("X-Cache"))
+	assert.Equal(t, jane.Body.String(), again.Body.String())
+}
+
+func TestResponseCacheEvictsOldest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var calls atomic.Int32
+	rc := middleware.NewResponseCache(2)
+	r := cacheRouter(rc, middleware.CachePolicy{TTL: time.Minute}, &calls)
+
+	for page := 1; page <= 3; page++ {
+		get(r, "/listings?page_num="+strconv.Itoa(page))
+	}
+	assert.Equal(t, "MISS", get(r, "/listings?page_num=1").Header().Get("X-Cache"))
+	assert.Equal(t, "HIT", get(r, "/listings?page_num=3").Header().Get("X-Cache"))
+}
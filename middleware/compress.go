@@ -0,0 +1,227 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"public-api/content"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+)
+
+// Content codings in order of preference
+const (
+	encodingBrotli = "br"
+	encodingGzip   = "gzip"
+)
+
+var encodings = []string{encodingBrotli, encodingGzip}
+
+// encoder is a compressing writer that can be reused for another response
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+var encoderPools = map[string]*sync.Pool{
+	encodingBrotli: {New: func() interface{} { return brotli.NewWriterLevel(nil, 5) }},
+	encodingGzip:   {New: func() interface{} { return gzip.NewWriter(nil) }},
+}
+
+// Compress encodes responses of at least minSize bytes with brotli or gzip,
+// whichever Accept-Encoding prefers. Smaller responses, event streams and
+// bodies that are already encoded are sent as they are. Strong ETags of
+// compressed responses get the coding as suffix, so each representation has
+// its own; the suffix is removed from If-None-Match again before handlers
+// compare it.
+func Compress(minSize int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodHead || c.GetHeader("Upgrade") != "" {
+			c.Next()
+			return
+		}
+
+		w := &compressWriter{ResponseWriter: c.Writer, encoding: negotiateEncoding(c.GetHeader("Accept-Encoding")), minSize: minSize}
+		if match := c.GetHeader("If-None-Match"); match != "" {
+			stripped, ok := stripETagSuffixes(match)
+			c.Request.Header.Set("If-None-Match", stripped)
+			w.suffixed = ok
+		}
+
+		c.Writer = w
+		c.Next()
+		c.Writer = w.ResponseWriter
+		w.close()
+	}
+}
+
+// compressWriter holds the first minSize bytes back to decide whether the
+// response is worth compressing
+type compressWriter struct {
+	gin.ResponseWriter
+	encoding string
+	minSize  int
+	suffixed bool
+
+	buf     []byte
+	decided bool
+	enc     encoder
+}
+
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if w.decided {
+		if w.enc != nil {
+			return w.enc.Write(b)
+		}
+		return w.ResponseWriter.Write(b)
+	}
+	w.buf = append(w.buf, b...)
+	if len(w.buf) >= w.minSize {
+		if err := w.decide(); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *compressWriter) WriteHeaderNow() {
+	if !w.decided {
+		_ = w.decide()
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+// Flush decides on what was written so far, so streams start right away
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		_ = w.decide()
+	}
+	if w.enc != nil {
+		_ = w.enc.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// decide sets the headers for the coding the response is sent with and
+// writes what was held back
+func (w *compressWriter) decide() error {
+	w.decided = true
+	h := w.Header()
+	content.AddVary(h, "Accept-Encoding")
+
+	status := w.Status()
+	if status == http.StatusNotModified && w.suffixed && w.encoding != "" {
+		h.Set("ETag", suffixETag(h.Get("ETag"), w.encoding))
+	}
+	if w.encoding != "" && len(w.buf) >= w.minSize && compressible(status, h) {
+		h.Set("Content-Encoding", w.encoding)
+		h.Del("Content-Length")
+		if etag := h.Get("ETag"); etag != "" {
+			h.Set("ETag", suffixETag(etag, w.encoding))
+		}
+		w.enc = encoderPools[w.encoding].Get().(encoder)
+		w.enc.Reset(w.ResponseWriter)
+	}
+
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	if w.enc != nil {
+		_, err := w.enc.Write(buf)
+		return err
+	}
+	_, err := w.ResponseWriter.Write(buf)
+	return err
+}
+
+// close writes out a response shorter than minSize, or finishes the
+// compressed stream
+func (w *compressWriter) close() {
+	if !w.decided {
+		_ = w.decide()
+	}
+	if w.enc != nil {
+		_ = w.enc.Close()
+		w.enc.Reset(nil)
+		encoderPools[w.encoding].Put(w.enc)
+		w.enc = nil
+	}
+}
+
+func compressible(status int, h http.Header) bool {
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified {
+		return false
+	}
+	if h.Get("Content-Encoding") != "" {
+		return false
+	}
+	ct, _, _ := mime.ParseMediaType(h.Get("Content-Type"))
+	return ct != "text/event-stream"
+}
+
+// negotiateEncoding picks the preferred coding the Accept-Encoding header
+// allows, or "" to send the body as it is
+func negotiateEncoding(header string) string {
+	accepted := make(map[string]float64)
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if raw, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if v, err := strconv.ParseFloat(raw, 64); err == nil {
+				q = v
+			}
+		}
+		accepted[strings.ToLower(strings.TrimSpace(name))] = q
+	}
+
+	best, bestQ := "", 0.0
+	for _, enc := range encodings {
+		q, ok := accepted[enc]
+		if !ok {
+			q, ok = accepted["*"]
+		}
+		if ok && q > bestQ {
+			best, bestQ = enc, q
+		}
+	}
+	return best
+}
+
+// suffixETag appends the coding to a strong ETag. Weak ETags already allow
+// for different encodings and are kept.
+func suffixETag(etag, encoding string) string {
+	if !strings.HasPrefix(etag, `"`) || !strings.HasSuffix(etag, `"`) || len(etag) < 2 {
+		return etag
+	}
+	return strings.TrimSuffix(etag, `"`) + "-" + encoding + `"`
+}
+
+// stripETagSuffixes removes the codings added by suffixETag from the ETags
+// of an If-None-Match header, reporting whether there were any
+func stripETagSuffixes(header string) (string, bool) {
+	stripped := false
+	tags := strings.Split(header, ",")
+	for i, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		for _, enc := range encodings {
+			if base, ok := strings.CutSuffix(tag, "-"+enc+`"`); ok {
+				tag, stripped = base+`"`, true
+				break
+			}
+		}
+		tags[i] = tag
+	}
+	return strings.Join(tags, ", "), stripped
+}
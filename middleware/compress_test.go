@@ -0,0 +1,149 @@
+package middleware_test
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"public-api/middleware"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func compressRouter() *gin.Engine {
+	r := gin.New()
+	r.Use(middleware.Compress(100), middleware.ETag())
+	r.GET("/big", func(c *gin.Context) {
+		c.String(http.StatusOK, strings.Repeat("listing ", 50))
+	})
+	r.GET("/small", func(c *gin.Context) {
+		c.String(http.StatusOK, "pong")
+	})
+	r.GET("/encoded", func(c *gin.Context) {
+		c.Header("Content-Encoding", "gzip")
+		c.Data(http.StatusOK, "application/octet-stream", []byte(strings.Repeat("x", 200)))
+	})
+	return r
+}
+
+func decompress(t *testing.T, encoding string, body io.Reader) string {
+	var r io.Reader
+	switch encoding {
+	case "br":
+		r = brotli.NewReader(body)
+	case "gzip":
+		gz, err := gzip.NewReader(body)
+		require.NoError(t, err)
+		r = gz
+	default:
+		r = body
+	}
+	b, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestCompress(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := compressRouter()
+
+	tests := []struct {
+		name           string
+		path           string
+		acceptEncoding string
+		wantEncoding   string
+	}{
+		{name: "brotli preferred", path: "/big", acceptEncoding: "gzip, deflate, br", wantEncoding: "br"},
+		{name: "quality wins", path: "/big", acceptEncoding: "br;q=0.5, gzip", wantEncoding: "gzip"},
+		{name: "wildcard", path: "/big", acceptEncoding: "*", wantEncoding: "br"},
+		{name: "refused", path: "/big", acceptEncoding: "br;q=0, gzip;q=0"},
+		{name: "not accepted", path: "/big"},
+		{name: "below threshold", path: "/small", acceptEncoding: "gzip"},
+		{name: "already encoded", path: "/encoded", acceptEncoding: "br", wantEncoding: "gzip"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+
+			assert.Equal(t, http.StatusOK, resp.Code)
+			assert.Equal(t, tt.wantEncoding, resp.Header().Get("Content-Encoding"))
+			assert.Equal(t, "Accept-Encoding", resp.Header().Get("Vary"))
+			if tt.path == "/big" {
+				assert.Equal(t, strings.Repeat("listing ", 50), decompress(t, tt.wantEncoding, resp.Body))
+			}
+		})
+	}
+}
+
+func TestCompressKeepsETagsPerCoding(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := compressRouter()
+
+	get := func(acceptEncoding, match string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/big", nil)
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		req.Header.Set("If-None-Match", match)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+		return resp
+	}
+
+	plain := get("", "").Header().Get("ETag")
+	gzipped := get("gzip", "").Header().Get("ETag")
+	assert.Equal(t, strings.TrimSuffix(plain, `"`)+`-gzip"`, gzipped)
+
+	resp := get("gzip", gzipped)
+	assert.Equal(t, http.StatusNotModified, resp.Code)
+	assert.Equal(t, gzipped, resp.Header().Get("ETag"))
+	assert.Empty(t, resp.Body.String())
+
+	assert.Equal(t, http.StatusNotModified, get("", plain).Code)
+	assert.Equal(t, http.StatusOK, get("br", `"stale-br"`).Code)
+}
+
+func TestCompressStreamsFlushedResponses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	flushed := make(chan struct{})
+	r := gin.New()
+	r.Use(middleware.Compress(10), middleware.ETag())
+	r.GET("/export", func(c *gin.Context) {
+		c.Header("Content-Type", "application/x-ndjson")
+		c.String(http.StatusOK, strings.Repeat(`{"id":1}`+"\n", 5))
+		c.Writer.Flush()
+		<-flushed
+		c.String(http.StatusOK, `{"id":2}`+"\n")
+	})
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/export", nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, "gzip", resp.Header.Get("Content-Encoding"))
+	assert.Empty(t, resp.Header.Get("ETag"))
+	gz, err := gzip.NewReader(resp.Body)
+	require.NoError(t, err)
+	body := bufio.NewReader(gz)
+	line, err := body.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, `{"id":1}`+"\n", line)
+
+	close(flushed)
+	rest, err := io.ReadAll(body)
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(string(rest), `{"id":2}`+"\n"))
+}
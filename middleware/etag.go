@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ETag gives successful GET responses a strong ETag hashed over their body
+// and answers a matching If-None-Match with 304. Responses that are flushed
+// while being written, like event streams and exports, are passed through
+// untouched.
+func ETag() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet || c.GetHeader("Upgrade") != "" {
+			c.Next()
+			return
+		}
+
+		original := c.Writer
+		w := &heldWriter{ResponseWriter: original}
+		c.Writer = w
+		c.Next()
+		c.Writer = original
+
+		if w.streaming {
+			return
+		}
+		if w.Status() == http.StatusOK && len(w.body) > 0 {
+			etag := computeETag(w.body)
+			original.Header().Set("ETag", etag)
+			if match := c.GetHeader("If-None-Match"); match != "" && etagMatches(match, etag) {
+				original.Header().Del("Content-Type")
+				original.WriteHeader(http.StatusNotModified)
+				original.WriteHeaderNow()
+				return
+			}
+		}
+		if len(w.body) > 0 {
+			_, _ = original.Write(w.body)
+		}
+	}
+}
+
+// heldWriter holds the body back until the middleware has seen all of it,
+// unless the handler flushes it
+type heldWriter struct {
+	gin.ResponseWriter
+	body      []byte
+	streaming bool
+}
+
+func (w *heldWriter) Write(b []byte) (int, error) {
+	if w.streaming {
+		return w.ResponseWriter.Write(b)
+	}
+	w.body = append(w.body, b...)
+	return len(b), nil
+}
+
+func (w *heldWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// WriteHeaderNow is deferred with the body; the status is already recorded
+func (w *heldWriter) WriteHeaderNow() {
+	if w.streaming {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+// Flush gives up on the ETag: a flushed response is a stream
+func (w *heldWriter) Flush() {
+	if !w.streaming {
+		w.streaming = true
+		if len(w.body) > 0 {
+			_, _ = w.ResponseWriter.Write(w.body)
+			w.body = nil
+		} else {
+			w.ResponseWriter.WriteHeaderNow()
+		}
+	}
+	w.ResponseWriter.Flush()
+}
+
+// computeETag returns a strong ETag for the given response body
+func computeETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatches reports whether an If-None-Match header value matches etag
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"public-api/middleware"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestETag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(middleware.ETag())
+	r.GET("/listings", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"listings": []int{1, 2}})
+	})
+	r.GET("/missing", func(c *gin.Context) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+	})
+	r.POST("/listings", func(c *gin.Context) {
+		c.JSON(http.StatusCreated, gin.H{"listing": 1})
+	})
+
+	first := httptest.NewRecorder()
+	r.ServeHTTP(first, httptest.NewRequest(http.MethodGet, "/listings", nil))
+	etag := first.Header().Get("ETag")
+	assert.Regexp(t, `^"[0-9a-f]{32}"$`, etag)
+
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		ifNoneMatch  string
+		expectedCode int
+		expectETag   bool
+	}{
+		{name: "matching", method: http.MethodGet, path: "/listings", ifNoneMatch: etag, expectedCode: http.StatusNotModified, expectETag: true},
+		{name: "one of several", method: http.MethodGet, path: "/listings", ifNoneMatch: `"other", ` + etag, expectedCode: http.StatusNotModified, expectETag: true},
+		{name: "weak comparison", method: http.MethodGet, path: "/listings", ifNoneMatch: "W/" + etag, expectedCode: http.StatusNotModified, expectETag: true},
+		{name: "stale", method: http.MethodGet, path: "/listings", ifNoneMatch: `"other"`, expectedCode: http.StatusOK, expectETag: true},
+		{name: "errors are not tagged", method: http.MethodGet, path: "/missing", ifNoneMatch: "*", expectedCode: http.StatusNotFound},
+		{name: "only GET", method: http.MethodPost, path: "/listings", ifNoneMatch: "*", expectedCode: http.StatusCreated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedCode, resp.Code)
+			if tt.expectETag {
+				assert.Equal(t, etag, resp.Header().Get("ETag"))
+			} else {
+				assert.Empty(t, resp.Header().Get("ETag"))
+			}
+			if tt.expectedCode == http.StatusNotModified {
+				assert.Empty(t, resp.Body.String())
+			} else {
+				assert.NotEmpty(t, resp.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// redactedParams are query parameters kept out of access logs. WebSocket
+// clients that cannot set headers send their token as access_token.
+var redactedParams = []string{"access_token"}
+
+// Logger logs requests like gin's default logger, with the values of
+// credential query parameters replaced by REDACTED
+func Logger() gin.HandlerFunc {
+	return gin.LoggerWithFormatter(func(p gin.LogFormatterParams) string {
+		if p.Latency > time.Minute {
+			p.Latency = p.Latency.Truncate(time.Second)
+		}
+		return fmt.Sprintf("[GIN] %v | %3d | %13v | %15s | %-7s %#v\n%s",
+			p.TimeStamp.Format("2006/01/02 - 15:04:05"),
+			p.StatusCode,
+			p.Latency,
+			p.ClientIP,
+			p.Method,
+			redactQuery(p.Path),
+			p.ErrorMessage,
+		)
+	})
+}
+
+// redactQuery replaces the values of redactedParams in the query of a
+// logged path, keeping the order of the other parameters
+func redactQuery(path string) string {
+	base, query, ok := strings.Cut(path, "?")
+	if !ok {
+		return path
+	}
+	params := strings.Split(query, "&")
+	for i, param := range params {
+		name, _, _ := strings.Cut(param, "=")
+		for _, redacted := range redactedParams {
+			if name == redacted {
+				params[i] = name + "=REDACTED"
+			}
+		}
+	}
+	return base + "?" + strings.Join(params, "&")
+}
@@ -0,0 +1,29 @@
+package middleware_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"public-api/middleware"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggerRedactsAccessTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var logs bytes.Buffer
+	defaultWriter := gin.DefaultWriter
+	gin.DefaultWriter = &logs
+	defer func() { gin.DefaultWriter = defaultWriter }()
+
+	r := gin.New()
+	r.Use(middleware.Logger())
+	r.GET("/ws", func(c *gin.Context) { c.Status(http.StatusUnauthorized) })
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/ws?v=2&access_token=7.123.secret", nil))
+
+	assert.Contains(t, logs.String(), `"/ws?v=2&access_token=REDACTED"`)
+	assert.NotContains(t, logs.String(), "secret")
+}
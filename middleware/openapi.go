@@ -0,0 +1,257 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"public-api/content"
+	"public-api/model"
+	"public-api/openapi"
+	"regexp"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+var ginParam = regexp.MustCompile(`:([^/]+)`)
+
+// OpenAPIValidator rejects requests whose path parameters, query parameters
+// or JSON bodies do not match the operation documented in doc. When
+// validateResponses is set, responses are buffered and checked as well, and
+// any that drift from the contract are replaced with a 500 so tests catch it.
+func OpenAPIValidator(doc *openapi.Document, validateResponses bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := ginParam.ReplaceAllString(c.FullPath(), "{$1}")
+		op := doc.Operation(c.Request.Method, path)
+		if op == nil {
+			c.Next()
+			return
+		}
+
+		if errs := validateRequest(doc, op, c); len(errs) > 0 {
+			c.AbortWithStatusJSON(http.StatusBadRequest, model.ErrorResponse{
+				Error:  "Invalid request",
+				Errors: fieldErrors(errs),
+			})
+			return
+		}
+
+		// Streams and exports are never buffered, they would not reach the
+		// client until closed, and neither are WebSocket upgrades, which hijack
+		// the connection
+		if !validateResponses || !hasJSONResponse(op) || isStream(op) {
+			c.Next()
+			return
+		}
+
+		original := c.Writer
+		buf := &bufferedWriter{ResponseWriter: original, status: http.StatusOK}
+		c.Writer = buf
+		c.Next()
+		c.Writer = original
+
+		if errs := validateResponse(doc, op, buf); len(errs) > 0 {
+			log.Printf("response of %s %s violates API contract: %v", c.Request.Method, path, errs)
+			c.JSON(http.StatusInternalServerError, model.ErrorResponse{
+				Error:  "response violates API contract",
+				Errors: fieldErrors(errs),
+			})
+			return
+		}
+
+		original.WriteHeader(buf.status)
+		if buf.body.Len() > 0 {
+			_, _ = original.Write(buf.body.Bytes())
+		} else {
+			original.WriteHeaderNow()
+		}
+	}
+}
+
+func validateRequest(doc *openapi.Document, op *openapi.Operation, c *gin.Context) []openapi.ValidationError {
+	var errs []openapi.ValidationError
+	for _, p := range op.Parameters {
+		raw, present := paramValue(c, p)
+		if !present {
+			if p.Required {
+				errs = append(errs, openapi.ValidationError{Path: p.Name, Code: openapi.CodeRequired, Message: "is required"})
+			}
+			continue
+		}
+
+		v, perr := openapi.ParseParam(doc.Resolve(p.Schema), raw)
+		if perr != nil {
+			perr.Path = p.Name
+			errs = append(errs, *perr)
+			continue
+		}
+		errs = append(errs, doc.ValidateValue(p.Schema, v, p.Name)...)
+	}
+
+	if op.RequestBody == nil {
+		return errs
+	}
+	media, ok := op.RequestBody.Content[openapi.ContentJSON]
+	if !ok {
+		return errs
+	}
+	// Bodies in the other documented formats are validated by the handler
+	if ct := content.Normalize(c.ContentType()); ct != openapi.ContentJSON {
+		if _, documented := op.RequestBody.Content[ct]; documented {
+			return errs
+		}
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return append(errs, openapi.ValidationError{Code: "malformed", Message: "request body could not be read"})
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		if op.RequestBody.Required {
+			errs = append(errs, openapi.ValidationError{Code: openapi.CodeRequired, Message: "request body is required"})
+		}
+		return errs
+	}
+
+	v, err := decodeJSON(body)
+	if err != nil {
+		return append(errs, openapi.ValidationError{Code: "malformed", Message: "request body is not valid JSON"})
+	}
+	return append(errs, doc.ValidateValue(media.Schema, v, "")...)
+}
+
+func validateResponse(doc *openapi.Document, op *openapi.Operation, w *bufferedWriter) []openapi.ValidationError {
+	resp, ok := op.Responses[strconv.Itoa(w.status)]
+	if !ok {
+		resp, ok = op.Responses["default"]
+	}
+	if !ok {
+		return []openapi.ValidationError{{Code: "status", Message: fmt.Sprintf("status %d is not documented", w.status)}}
+	}
+
+	media, ok := resp.Content[openapi.ContentJSON]
+	if !ok || w.body.Len() == 0 {
+		return nil
+	}
+	if ct, _, _ := mime.ParseMediaType(w.Header().Get("Content-Type")); ct != openapi.ContentJSON {
+		if _, documented := resp.Content[ct]; documented {
+			// Only JSON bodies can be checked against the schema
+			return nil
+		}
+		return []openapi.ValidationError{{Code: "content_type", Message: fmt.Sprintf("content type %q is not documented", ct)}}
+	}
+
+	v, err := decodeJSON(w.body.Bytes())
+	if err != nil {
+		return []openapi.ValidationError{{Code: "malformed", Message: "response body is not valid JSON"}}
+	}
+	return doc.ValidateValue(media.Schema, v, "")
+}
+
+func paramValue(c *gin.Context, p *openapi.Parameter) (string, bool) {
+	switch p.In {
+	case openapi.InPath:
+		v := c.Param(p.Name)
+		return v, v != ""
+	case openapi.InQuery:
+		return c.GetQuery(p.Name)
+	case openapi.InHeader:
+		v := c.GetHeader(p.Name)
+		return v, v != ""
+	default:
+		return "", false
+	}
+}
+
+func hasJSONResponse(op *openapi.Operation) bool {
+	for _, r := range op.Responses {
+		if _, ok := r.Content[openapi.ContentJSON]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func isStream(op *openapi.Operation) bool {
+	if _, ok := op.Responses["101"]; ok {
+		return true
+	}
+	for _, r := range op.Responses {
+		for _, ct := range []string{openapi.ContentEventStream, openapi.ContentCSV, openapi.ContentNDJSON} {
+			if _, ok := r.Content[ct]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func decodeJSON(b []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func fieldErrors(errs []openapi.ValidationError) []model.FieldError {
+	out := make([]model.FieldError, len(errs))
+	for i, e := range errs {
+		out[i] = model.FieldError{Field: e.Path, Code: e.Code, Message: e.Message}
+	}
+	return out
+}
+
+// bufferedWriter holds the status and body written by handlers so the
+// response can be validated before it reaches the client
+type bufferedWriter struct {
+	gin.ResponseWriter
+	status  int
+	written bool
+	body    bytes.Buffer
+}
+
+func (w *bufferedWriter) WriteHeader(code int) {
+	if code > 0 && !w.written {
+		w.status = code
+	}
+}
+
+func (w *bufferedWriter) WriteHeaderNow() {
+	w.written = true
+}
+
+func (w *bufferedWriter) Write(b []byte) (int, error) {
+	w.written = true
+	return w.body.Write(b)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	w.written = true
+	return w.body.WriteString(s)
+}
+
+func (w *bufferedWriter) Status() int {
+	return w.status
+}
+
+func (w *bufferedWriter) Size() int {
+	if !w.written {
+		return -1
+	}
+	return w.body.Len()
+}
+
+func (w *bufferedWriter) Written() bool {
+	return w.written
+}
+
+func (w *bufferedWriter) Flush() {}
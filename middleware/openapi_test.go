@@ -0,0 +1,191 @@
+package middleware_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"public-api/middleware"
+	"public-api/openapi"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func testSpec() *openapi.Document {
+	doc := openapi.New(openapi.Info{Title: "test", Version: "1"})
+	thing := &openapi.Schema{
+		Type:       "object",
+		Required:   []string{"id"},
+		Properties: map[string]*openapi.Schema{"id": {Type: "integer"}},
+	}
+	doc.AddOperation(http.MethodGet, "/things/{id}", &openapi.Operation{
+		OperationID: "getThing",
+		Parameters: []*openapi.Parameter{
+			{Name: "id", In: openapi.InPath, Required: true, Schema: &openapi.Schema{Type: "integer", Minimum: openapi.Float(1)}},
+			{Name: "verbose", In: openapi.InQuery, Schema: &openapi.Schema{Type: "boolean"}},
+		},
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "ok", Content: openapi.JSONContent(thing)},
+		},
+	})
+	doc.AddOperation(http.MethodPost, "/things", &openapi.Operation{
+		OperationID: "createThing",
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content: openapi.JSONContent(&openapi.Schema{
+				Type:       "object",
+				Required:   []string{"kind"},
+				Properties: map[string]*openapi.Schema{"kind": {Type: "string", Enum: []interface{}{"a", "b"}}},
+			}),
+		},
+		Responses: map[string]*openapi.Response{
+			"201": {Description: "created", Content: openapi.JSONContent(thing)},
+		},
+	})
+	return doc
+}
+
+func TestOpenAPIValidator_Requests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		body         string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "valid path and query",
+			method:       http.MethodGet,
+			path:         "/things/1?verbose=true",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "invalid path param",
+			method:       http.MethodGet,
+			path:         "/things/abc",
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"field":"id","code":"type","message":"must be an integer"}`,
+		},
+		{
+			name:         "path param below minimum",
+			method:       http.MethodGet,
+			path:         "/things/0",
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"field":"id","code":"minimum","message":"must be at least 1"}`,
+		},
+		{
+			name:         "invalid query param",
+			method:       http.MethodGet,
+			path:         "/things/1?verbose=maybe",
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"field":"verbose","code":"type","message":"must be a boolean"}`,
+		},
+		{
+			name:         "valid body",
+			method:       http.MethodPost,
+			path:         "/things",
+			body:         `{"kind": "a"}`,
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "missing body",
+			method:       http.MethodPost,
+			path:         "/things",
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `"code":"required"`,
+		},
+		{
+			name:         "body violates enum",
+			method:       http.MethodPost,
+			path:         "/things",
+			body:         `{"kind": "c"}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"field":"kind","code":"enum","message":"must be one of: a, b"}`,
+		},
+		{
+			name:         "malformed body",
+			method:       http.MethodPost,
+			path:         "/things",
+			body:         `{kind`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `"code":"malformed"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.Use(middleware.OpenAPIValidator(testSpec(), false))
+			r.GET("/things/:id", func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{"id": 1})
+			})
+			r.POST("/things", func(c *gin.Context) {
+				var body map[string]interface{}
+				assert.NoError(t, c.ShouldBindJSON(&body))
+				c.JSON(http.StatusCreated, gin.H{"id": 1})
+			})
+
+			req := httptest.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedCode, resp.Code)
+			assert.Contains(t, resp.Body.String(), tt.expectedBody)
+		})
+	}
+}
+
+func TestOpenAPIValidator_Responses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name         string
+		handler      gin.HandlerFunc
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name: "matching response",
+			handler: func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{"id": 1})
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"id":1}`,
+		},
+		{
+			name: "response drifts from schema",
+			handler: func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{"id": "one"})
+			},
+			expectedCode: http.StatusInternalServerError,
+			expectedBody: `{"field":"id","code":"type","message":"must be an integer"}`,
+		},
+		{
+			name: "undocumented status",
+			handler: func(c *gin.Context) {
+				c.JSON(http.StatusTeapot, gin.H{"id": 1})
+			},
+			expectedCode: http.StatusInternalServerError,
+			expectedBody: `status 418 is not documented`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.Use(middleware.OpenAPIValidator(testSpec(), true))
+			r.GET("/things/:id", tt.handler)
+
+			req := httptest.NewRequest(http.MethodGet, "/things/1", nil)
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedCode, resp.Code)
+			assert.Contains(t, resp.Body.String(), tt.expectedBody)
+		})
+	}
+}
@@ -49,17 +49,32 @@ func (mr *MockListingClientMockRecorder) CreateListing(arg0 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateListing", reflect.TypeOf((*MockListingClient)(nil).CreateListing), arg0)
 }
 
+// FetchListingByID mocks base method.
+func (m *MockListingClient) FetchListingByID(arg0 int64) (*model.Listing, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchListingByID", arg0)
+	ret0, _ := ret[0].(*model.Listing)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchListingByID indicates an expected call of FetchListingByID.
+func (mr *MockListingClientMockRecorder) FetchListingByID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchListingByID", reflect.TypeOf((*MockListingClient)(nil).FetchListingByID), arg0)
+}
+
 // FetchListings mocks base method.
-func (m *MockListingClient) FetchListings(arg0, arg1 int, arg2 *int64) ([]model.Listing, error) {
+func (m *MockListingClient) FetchListings(arg0 model.ListingQuery) ([]model.Listing, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FetchListings", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "FetchListings", arg0)
 	ret0, _ := ret[0].([]model.Listing)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // FetchListings indicates an expected call of FetchListings.
-func (mr *MockListingClientMockRecorder) FetchListings(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockListingClientMockRecorder) FetchListings(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchListings", reflect.TypeOf((*MockListingClient)(nil).FetchListings), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchListings", reflect.TypeOf((*MockListingClient)(nil).FetchListings), arg0)
 }
@@ -50,17 +50,77 @@ func (mr *MockListingServiceMockRecorder) CreateListing(arg0, arg1 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateListing", reflect.TypeOf((*MockListingService)(nil).CreateListing), arg0, arg1)
 }
 
+// CreateListings mocks base method.
+func (m *MockListingService) CreateListings(arg0 context.Context, arg1 []model.Listing) ([]*model.Listing, []error, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateListings", arg0, arg1)
+	ret0, _ := ret[0].([]*model.Listing)
+	ret1, _ := ret[1].([]error)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateListings indicates an expected call of CreateListings.
+func (mr *MockListingServiceMockRecorder) CreateListings(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateListings", reflect.TypeOf((*MockListingService)(nil).CreateListings), arg0, arg1)
+}
+
+// ExportListings mocks base method.
+func (m *MockListingService) ExportListings(arg0 context.Context, arg1 model.ListingQuery, arg2 func([]model.Listing) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportListings", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportListings indicates an expected call of ExportListings.
+func (mr *MockListingServiceMockRecorder) ExportListings(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportListings", reflect.TypeOf((*MockListingService)(nil).ExportListings), arg0, arg1, arg2)
+}
+
+// FindListings mocks base method.
+func (m *MockListingService) FindListings(arg0 context.Context, arg1 model.ListingQuery) ([]model.Listing, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindListings", arg0, arg1)
+	ret0, _ := ret[0].([]model.Listing)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindListings indicates an expected call of FindListings.
+func (mr *MockListingServiceMockRecorder) FindListings(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindListings", reflect.TypeOf((*MockListingService)(nil).FindListings), arg0, arg1)
+}
+
+// GetListingByID mocks base method.
+func (m *MockListingService) GetListingByID(arg0 context.Context, arg1 int64) (*model.Listing, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetListingByID", arg0, arg1)
+	ret0, _ := ret[0].(*model.Listing)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetListingByID indicates an expected call of GetListingByID.
+func (mr *MockListingServiceMockRecorder) GetListingByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetListingByID", reflect.TypeOf((*MockListingService)(nil).GetListingByID), arg0, arg1)
+}
+
 // GetListings mocks base method.
-func (m *MockListingService) GetListings(arg0 context.Context, arg1, arg2 int, arg3 *int64) ([]model.Listing, error) {
+func (m *MockListingService) GetListings(arg0 context.Context, arg1 model.ListingQuery) ([]model.Listing, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetListings", arg0, arg1, arg2, arg3)
+	ret := m.ctrl.Call(m, "GetListings", arg0, arg1)
 	ret0, _ := ret[0].([]model.Listing)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetListings indicates an expected call of GetListings.
-func (mr *MockListingServiceMockRecorder) GetListings(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+func (mr *MockListingServiceMockRecorder) GetListings(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetListings", reflect.TypeOf((*MockListingService)(nil).GetListings), arg0, arg1, arg2, arg3)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetListings", reflect.TypeOf((*MockListingService)(nil).GetListings), arg0, arg1)
 }
@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: public-api/service (interfaces: OnboardingService)
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	model "public-api/model"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockOnboardingService is a mock of OnboardingService interface.
+type MockOnboardingService struct {
+	ctrl     *gomock.Controller
+	recorder *MockOnboardingServiceMockRecorder
+}
+
+// MockOnboardingServiceMockRecorder is the mock recorder for MockOnboardingService.
+type MockOnboardingServiceMockRecorder struct {
+	mock *MockOnboardingService
+}
+
+// NewMockOnboardingService creates a new mock instance.
+func NewMockOnboardingService(ctrl *gomock.Controller) *MockOnboardingService {
+	mock := &MockOnboardingService{ctrl: ctrl}
+	mock.recorder = &MockOnboardingServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOnboardingService) EXPECT() *MockOnboardingServiceMockRecorder {
+	return m.recorder
+}
+
+// Onboard mocks base method.
+func (m *MockOnboardingService) Onboard(arg0 context.Context, arg1 string, arg2 model.Listing) (*model.Onboarding, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Onboard", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*model.Onboarding)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Onboard indicates an expected call of Onboard.
+func (mr *MockOnboardingServiceMockRecorder) Onboard(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Onboard", reflect.TypeOf((*MockOnboardingService)(nil).Onboard), arg0, arg1, arg2)
+}
@@ -0,0 +1,15 @@
+package model
+
+import "errors"
+
+var (
+	// ErrNotFound is returned when a downstream service reports a missing resource
+	ErrNotFound = errors.New("resource not found")
+
+	// ErrInvalidInput is returned when a request fails business validation
+	ErrInvalidInput = errors.New("invalid input")
+
+	// ErrUnprocessable is returned when a well-formed request references
+	// resources that do not exist
+	ErrUnprocessable = errors.New("unprocessable request")
+)
@@ -0,0 +1,45 @@
+package model
+
+// MaxImportRows is the most data rows one CSV import may contain
+const MaxImportRows = 10000
+
+// ImportStatus is the state of a listing import job
+type ImportStatus string
+
+// Import job states
+const (
+	ImportQueued    ImportStatus = "queued"
+	ImportRunning   ImportStatus = "running"
+	ImportCompleted ImportStatus = "completed"
+)
+
+// ImportRowError describes why a row of an import was not created. Line is
+// the CSV line the row starts on, counting the header as line 1.
+type ImportRowError struct {
+	Line    int    `json:"line"`
+	Field   string `json:"field,omitempty"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ImportJob reports the progress of a listing import. Rows that failed
+// validation count as processed and failed from the start; a dry run
+// completes immediately without creating anything.
+type ImportJob struct {
+	ID         int64            `json:"id"`
+	Status     ImportStatus     `json:"status"`
+	DryRun     bool             `json:"dry_run"`
+	Total      int              `json:"total"`
+	Valid      int              `json:"valid"`
+	Processed  int              `json:"processed"`
+	Created    int              `json:"created"`
+	Failed     int              `json:"failed"`
+	Errors     []ImportRowError `json:"errors"`
+	CreatedAt  int64            `json:"created_at"`
+	FinishedAt int64            `json:"finished_at,omitempty"`
+}
+
+// ImportJobEnvelope wraps an import job in v2 responses
+type ImportJobEnvelope struct {
+	Data *ImportJob `json:"data"`
+}
@@ -0,0 +1,91 @@
+package model
+
+import "fmt"
+
+// Sortable listing fields
+const (
+	ListingSortPrice     = "price"
+	ListingSortCreatedAt = "created_at"
+)
+
+// Sort directions
+const (
+	SortAsc  = "asc"
+	SortDesc = "desc"
+)
+
+// MaxListingPageSize is the largest page of listings that can be requested
+const MaxListingPageSize = 100
+
+// ListingQuery holds pagination, filter and sort options for listing lookups
+type ListingQuery struct {
+	Page        int
+	Size        int
+	UserID      *int64
+	ListingType string
+	MinPrice    *float64
+	MaxPrice    *float64
+	CreatedFrom *int64 // inclusive, same unit as Listing.CreatedAt
+	CreatedTo   *int64 // inclusive, same unit as Listing.CreatedAt
+	SortBy      string
+	SortOrder   string
+}
+
+// Validate checks that the query is internally consistent
+func (q ListingQuery) Validate() error {
+	if q.Page < 1 {
+		return fmt.Errorf("%w: page_num must be at least 1", ErrInvalidInput)
+	}
+	if q.Size < 1 || q.Size > MaxListingPageSize {
+		return fmt.Errorf("%w: page_size must be between 1 and %d", ErrInvalidInput, MaxListingPageSize)
+	}
+	if q.ListingType != "" && !ListingType(q.ListingType).IsValid() {
+		return fmt.Errorf("%w: listing_type must be one of %s, %s", ErrInvalidInput, ListingTypeSale, ListingTypeRent)
+	}
+	if q.MinPrice != nil && *q.MinPrice < 0 {
+		return fmt.Errorf("%w: min_price must not be negative", ErrInvalidInput)
+	}
+	if q.MaxPrice != nil && *q.MaxPrice < 0 {
+		return fmt.Errorf("%w: max_price must not be negative", ErrInvalidInput)
+	}
+	if q.MinPrice != nil && q.MaxPrice != nil && *q.MinPrice > *q.MaxPrice {
+		return fmt.Errorf("%w: min_price must not exceed max_price", ErrInvalidInput)
+	}
+	if q.CreatedFrom != nil && q.CreatedTo != nil && *q.CreatedFrom > *q.CreatedTo {
+		return fmt.Errorf("%w: created_from must not exceed created_to", ErrInvalidInput)
+	}
+	switch q.SortBy {
+	case "", ListingSortPrice, ListingSortCreatedAt:
+	default:
+		return fmt.Errorf("%w: sort_by must be one of %s, %s", ErrInvalidInput, ListingSortPrice, ListingSortCreatedAt)
+	}
+	switch q.SortOrder {
+	case "", SortAsc, SortDesc:
+	default:
+		return fmt.Errorf("%w: sort_order must be one of %s, %s", ErrInvalidInput, SortAsc, SortDesc)
+	}
+	return nil
+}
+
+// Matches reports whether l satisfies every filter in the query
+func (q ListingQuery) Matches(l Listing) bool {
+	if q.UserID != nil && l.UserID != *q.UserID {
+		return false
+	}
+	if q.ListingType != "" && l.ListingType != q.ListingType {
+		return false
+	}
+	if q.MinPrice != nil && l.Price < *q.MinPrice {
+		return false
+	}
+	if q.MaxPrice != nil && l.Price > *q.MaxPrice {
+		return false
+	}
+	if q.CreatedFrom != nil && l.CreatedAt < *q.CreatedFrom {
+		return false
+	}
+	if q.CreatedTo != nil && l.CreatedAt > *q.CreatedTo {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MaxListingPrice is the highest price a listing may be created with
+	MaxListingPrice = 1_000_000_000_000
+
+	// MaxPriceDecimals is the number of fractional digits a price may carry
+	MaxPriceDecimals = 2
+)
+
+// ValidatePrice checks that p is positive, within MaxListingPrice and has at
+// most MaxPriceDecimals fractional digits. Together these bounds keep every
+// accepted price within the 15 significant digits a float64 round-trips
+// exactly, so FormatPrice never alters the value the client sent.
+func ValidatePrice(p float64) error {
+	if math.IsNaN(p) || math.IsInf(p, 0) || p <= 0 {
+		return fmt.Errorf("%w: price must be greater than 0", ErrInvalidInput)
+	}
+	if p > MaxListingPrice {
+		return fmt.Errorf("%w: price must not exceed %d", ErrInvalidInput, int64(MaxListingPrice))
+	}
+	if s := FormatPrice(p); strings.Contains(s, ".") && len(s)-strings.Index(s, ".")-1 > MaxPriceDecimals {
+		return fmt.Errorf("%w: price must have at most %d decimal places", ErrInvalidInput, MaxPriceDecimals)
+	}
+	return nil
+}
+
+// FormatPrice renders p as the shortest decimal string that parses back to p
+func FormatPrice(p float64) string {
+	return strconv.FormatFloat(p, 'f', -1, 64)
+}
@@ -2,12 +2,34 @@ package model
 
 // CreateUserRequest represents the payload to create a user
 type CreateUserRequest struct {
-	Name string `json:"name" binding:"required"`
+	Name string `json:"name" binding:"required,user_name"`
 }
 
+// UpdateUserRequest represents the payload to update a user
+type UpdateUserRequest struct {
+	Name string `json:"name" binding:"required,user_name"`
+}
+
+// MaxListingBatchSize is the most listings one batch request may create
+const MaxListingBatchSize = 100
+
 // CreateListingRequest represents the payload to create a listing
 type CreateListingRequest struct {
-	UserID      int64   `json:"user_id" binding:"required"`
-	ListingType string  `json:"listing_type" binding:"required"`
-	Price       float64 `json:"price" binding:"required"`
+	UserID      int64       `json:"user_id" binding:"required,gt=0"`
+	ListingType ListingType `json:"listing_type" binding:"required,listing_type"`
+	Price       float64     `json:"price" binding:"required,listing_price"`
+}
+
+// OnboardingRequest represents the payload to create a user together with
+// their first listing
+type OnboardingRequest struct {
+	Name    string            `json:"name" binding:"required,user_name"`
+	Listing OnboardingListing `json:"listing"`
+}
+
+// OnboardingListing is the first listing of a user being onboarded. Its
+// owner is the user created by the same request.
+type OnboardingListing struct {
+	ListingType ListingType `json:"listing_type" binding:"required,listing_type"`
+	Price       float64     `json:"price" binding:"required,listing_price"`
 }
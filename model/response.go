@@ -0,0 +1,81 @@
+package model
+
+// FieldError describes why a single request field was rejected
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ErrorResponse is the body returned by every failing endpoint
+type ErrorResponse struct {
+	Error  string       `json:"error"`
+	Errors []FieldError `json:"errors,omitempty"`
+}
+
+// OnboardingErrorResponse is returned when onboarding created the user but
+// failed to create their listing. The user is kept; the listing can be
+// created for it on its own.
+type OnboardingErrorResponse struct {
+	Error      string `json:"error"`
+	FailedStep string `json:"failed_step"`
+	User       *User  `json:"user"`
+}
+
+// ListingResult is the outcome of one item of a batch listing creation.
+// Status is the code the item would have had on its own.
+type ListingResult struct {
+	Index   int      `json:"index"`
+	Status  int      `json:"status"`
+	Listing *Listing `json:"listing,omitempty"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// Onboarding is a user created together with their first listing
+type Onboarding struct {
+	User    *User    `json:"user"`
+	Listing *Listing `json:"listing"`
+}
+
+// Pagination describes the page returned in a paginated v2 response
+type Pagination struct {
+	PageNum  int `json:"page_num"`
+	PageSize int `json:"page_size"`
+	Count    int `json:"count"`
+}
+
+// UserEnvelope wraps a single user in v2 responses
+type UserEnvelope struct {
+	Data *User `json:"data"`
+}
+
+// ListingEnvelope wraps a single listing in v2 responses
+type ListingEnvelope struct {
+	Data *Listing `json:"data"`
+}
+
+// ListingResultsEnvelope wraps batch listing results in v2 responses
+type ListingResultsEnvelope struct {
+	Data []ListingResult `json:"data"`
+}
+
+// OnboardingEnvelope wraps an onboarding result in v2 responses
+type OnboardingEnvelope struct {
+	Data *Onboarding `json:"data"`
+}
+
+// ListingPage wraps a page of listings in v2 responses
+type ListingPage struct {
+	Data       []Listing  `json:"data"`
+	Pagination Pagination `json:"pagination"`
+}
+
+// SparseListing is a listing trimmed to the fields a client selected, keyed
+// like the JSON of Listing
+type SparseListing map[string]interface{}
+
+// SparseListingPage wraps a page of sparse listings in v2 responses
+type SparseListingPage struct {
+	Data       []SparseListing `json:"data"`
+	Pagination Pagination      `json:"pagination"`
+}
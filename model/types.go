@@ -8,6 +8,28 @@ type User struct {
 	UpdatedAt int64  `json:"updated_at"`
 }
 
+// ListingType enumerates the kinds of listing the platform accepts
+type ListingType string
+
+// Supported listing types
+const (
+	ListingTypeSale ListingType = "sale"
+	ListingTypeRent ListingType = "rent"
+)
+
+// ListingTypes lists every supported listing type
+var ListingTypes = []ListingType{ListingTypeSale, ListingTypeRent}
+
+// IsValid reports whether t is a supported listing type
+func (t ListingType) IsValid() bool {
+	for _, known := range ListingTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
 // Listing represents a property listing
 type Listing struct {
 	ID          int64   `json:"id"`
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// MinUserNameLength is the minimum number of characters in a user name
+	MinUserNameLength = 2
+
+	// MaxUserNameLength is the maximum number of characters in a user name
+	MaxUserNameLength = 100
+)
+
+// NormalizeUserName trims surrounding and repeated whitespace and checks the
+// name's length and characters
+func NormalizeUserName(name string) (string, error) {
+	name = strings.Join(strings.Fields(name), " ")
+
+	n := utf8.RuneCountInString(name)
+	if n < MinUserNameLength || n > MaxUserNameLength {
+		return "", fmt.Errorf("%w: name must be between %d and %d characters",
+			ErrInvalidInput, MinUserNameLength, MaxUserNameLength)
+	}
+
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsMark(r) || r == ' ' || r == '\'' || r == '-' || r == '.' {
+			continue
+		}
+		return "", fmt.Errorf("%w: name contains invalid character %q", ErrInvalidInput, r)
+	}
+
+	return name, nil
+}
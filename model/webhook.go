@@ -0,0 +1,90 @@
+package model
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// WebhookEventType names an event partners can subscribe to
+type WebhookEventType string
+
+// Supported webhook event types
+const (
+	WebhookListingCreated WebhookEventType = "listing.created"
+	WebhookUserCreated    WebhookEventType = "user.created"
+)
+
+// WebhookEventTypes lists every supported webhook event type
+var WebhookEventTypes = []WebhookEventType{WebhookListingCreated, WebhookUserCreated}
+
+// IsValid reports whether t is a supported webhook event type
+func (t WebhookEventType) IsValid() bool {
+	for _, known := range WebhookEventTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// MinWebhookSecretLength is the shortest secret a webhook may be signed with
+const MinWebhookSecretLength = 16
+
+// ValidateWebhookURL checks that raw is an absolute http or https URL
+func ValidateWebhookURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalidInput)
+	}
+	return nil
+}
+
+// CreateWebhookRequest represents the payload to subscribe a webhook. The
+// secret is generated when omitted.
+type CreateWebhookRequest struct {
+	URL    string             `json:"url" binding:"required,webhook_url"`
+	Events []WebhookEventType `json:"events" binding:"required,min=1,dive,webhook_event"`
+	Secret string             `json:"secret,omitempty" binding:"omitempty,min=16,max=256"`
+}
+
+// Webhook is a partner endpoint subscribed to events. Secret is only
+// returned when the webhook is created.
+type Webhook struct {
+	ID        int64              `json:"id"`
+	URL       string             `json:"url"`
+	Events    []WebhookEventType `json:"events"`
+	Secret    string             `json:"secret,omitempty"`
+	CreatedAt int64              `json:"created_at"`
+}
+
+// WebhookEvent is the body POSTed to webhook endpoints. ID stays the same
+// across retries so receivers can ignore duplicates.
+type WebhookEvent struct {
+	ID        string           `json:"id"`
+	Type      WebhookEventType `json:"type"`
+	CreatedAt int64            `json:"created_at"`
+	Data      interface{}      `json:"data"`
+}
+
+// WebhookDelivery records one attempt to deliver an event to a webhook
+type WebhookDelivery struct {
+	EventID     string           `json:"event_id"`
+	EventType   WebhookEventType `json:"event_type"`
+	Attempt     int              `json:"attempt"`
+	Succeeded   bool             `json:"succeeded"`
+	StatusCode  int              `json:"status_code,omitempty"`
+	Error       string           `json:"error,omitempty"`
+	DurationMs  int64            `json:"duration_ms"`
+	AttemptedAt int64            `json:"attempted_at"`
+}
+
+// WebhookDeadLetter is an event that could not be delivered to a webhook
+// after every retry
+type WebhookDeadLetter struct {
+	ID        int64        `json:"id"`
+	WebhookID int64        `json:"webhook_id"`
+	Event     WebhookEvent `json:"event"`
+	Attempts  int          `json:"attempts"`
+	LastError string       `json:"last_error"`
+	FailedAt  int64        `json:"failed_at"`
+}
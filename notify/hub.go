@@ -0,0 +1,271 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"public-api/model"
+	"strings"
+	"sync"
+)
+
+// Topics a connection can subscribe to. Listing type topics are named
+// TopicListingTypePrefix followed by the type, e.g. "listings.type.rent".
+const (
+	TopicMyListings        = "listings.mine"
+	TopicMyProfile         = "users.me"
+	TopicListingTypePrefix = "listings.type."
+)
+
+// Events delivered on the topics
+const (
+	EventListingCreated = "listing.created"
+	EventUserUpdated    = "user.updated"
+)
+
+// Message types exchanged with clients
+const (
+	TypeSubscribe   = "subscribe"
+	TypeUnsubscribe = "unsubscribe"
+	TypeAck         = "ack"
+	TypeError       = "error"
+	TypeEvent       = "event"
+)
+
+var (
+	// ErrTooManyConnections is returned when the hub is at its connection limit
+	ErrTooManyConnections = errors.New("too many connections")
+	// ErrTooManyUserConnections is returned when a user has as many
+	// connections open as allowed
+	ErrTooManyUserConnections = errors.New("too many connections for this user")
+)
+
+// ClientMessage is a command sent by a client. ID is echoed in the reply so
+// clients can match acknowledgements to their requests.
+type ClientMessage struct {
+	Type  string `json:"type"`
+	ID    string `json:"id,omitempty"`
+	Topic string `json:"topic,omitempty"`
+}
+
+// ServerMessage is an acknowledgement, error or event sent to a client
+type ServerMessage struct {
+	Type  string      `json:"type"`
+	ID    string      `json:"id,omitempty"`
+	Topic string      `json:"topic,omitempty"`
+	Event string      `json:"event,omitempty"`
+	Data  interface{} `json:"data,omitempty"`
+	Error string      `json:"error,omitempty"`
+}
+
+// Limits bounds the resources used by connected clients
+type Limits struct {
+	MaxConnections        int
+	MaxConnectionsPerUser int
+	MaxSubscriptions      int
+	SendBuffer            int
+}
+
+// Hub routes listing and user events to the connections subscribed to them
+type Hub struct {
+	mu      sync.Mutex
+	limits  Limits
+	conns   map[*Conn]struct{}
+	perUser map[int64]int
+}
+
+// NewHub creates a hub enforcing limits. Zero limits mean unlimited, except
+// SendBuffer which is at least 1.
+func NewHub(limits Limits) *Hub {
+	if limits.SendBuffer < 1 {
+		limits.SendBuffer = 1
+	}
+	return &Hub{
+		limits:  limits,
+		conns:   make(map[*Conn]struct{}),
+		perUser: make(map[int64]int),
+	}
+}
+
+// Register opens a connection for userID, or fails when a connection limit
+// has been reached
+func (h *Hub) Register(userID int64) (*Conn, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.limits.MaxConnections > 0 && len(h.conns) >= h.limits.MaxConnections {
+		return nil, ErrTooManyConnections
+	}
+	if h.limits.MaxConnectionsPerUser > 0 && h.perUser[userID] >= h.limits.MaxConnectionsPerUser {
+		return nil, ErrTooManyUserConnections
+	}
+
+	c := &Conn{
+		hub:    h,
+		userID: userID,
+		send:   make(chan ServerMessage, h.limits.SendBuffer),
+		topics: make(map[string]struct{}),
+	}
+	h.conns[c] = struct{}{}
+	h.perUser[userID]++
+	return c, nil
+}
+
+// Connections returns the number of open connections
+func (h *Hub) Connections() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.conns)
+}
+
+// Publish sends a listing.created event to the owner's "listings.mine"
+// subscriptions and to the subscribers of the listing's type
+func (h *Hub) Publish(l model.Listing) {
+	typeTopic := TopicListingTypePrefix + l.ListingType
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for c := range h.conns {
+		if c.userID == l.UserID {
+			c.deliver(TopicMyListings, EventListingCreated, l)
+		}
+		c.deliver(typeTopic, EventListingCreated, l)
+	}
+}
+
+// PublishUserUpdate sends a user.updated event to the user's own "users.me"
+// subscriptions
+func (h *Hub) PublishUserUpdate(u model.User) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for c := range h.conns {
+		if c.userID == u.ID {
+			c.deliver(TopicMyProfile, EventUserUpdated, u)
+		}
+	}
+}
+
+// remove drops c and closes its send channel. The caller must hold h.mu.
+func (h *Hub) remove(c *Conn, dropped bool) {
+	if _, ok := h.conns[c]; !ok {
+		return
+	}
+	delete(h.conns, c)
+	if h.perUser[c.userID]--; h.perUser[c.userID] == 0 {
+		delete(h.perUser, c.userID)
+	}
+	c.dropped = dropped
+	close(c.send)
+}
+
+// Conn is one client connection registered with a hub. It is independent
+// of the transport: the caller feeds it client messages with Handle and
+// writes out everything received from Send.
+type Conn struct {
+	hub     *Hub
+	userID  int64
+	send    chan ServerMessage
+	topics  map[string]struct{}
+	dropped bool
+}
+
+// UserID returns the authenticated user owning the connection
+func (c *Conn) UserID() int64 {
+	return c.userID
+}
+
+// Send returns the channel of messages to write to the client. It is closed
+// when the connection is closed or dropped.
+func (c *Conn) Send() <-chan ServerMessage {
+	return c.send
+}
+
+// Dropped reports whether the connection was closed because the client did
+// not keep up with its messages. Only meaningful once Send is closed.
+func (c *Conn) Dropped() bool {
+	c.hub.mu.Lock()
+	defer c.hub.mu.Unlock()
+	return c.dropped
+}
+
+// Handle applies a client command and queues the acknowledgement or error
+func (c *Conn) Handle(msg ClientMessage) {
+	c.hub.mu.Lock()
+	defer c.hub.mu.Unlock()
+
+	if err := c.apply(msg); err != nil {
+		c.enqueue(ServerMessage{Type: TypeError, ID: msg.ID, Topic: msg.Topic, Error: err.Error()})
+		return
+	}
+	c.enqueue(ServerMessage{Type: TypeAck, ID: msg.ID, Topic: msg.Topic})
+}
+
+// Reject queues an error reply for a message that could not be decoded
+func (c *Conn) Reject(err error) {
+	c.hub.mu.Lock()
+	defer c.hub.mu.Unlock()
+	c.enqueue(ServerMessage{Type: TypeError, Error: err.Error()})
+}
+
+// Close unregisters the connection. It is safe to call more than once.
+func (c *Conn) Close() {
+	c.hub.mu.Lock()
+	defer c.hub.mu.Unlock()
+	c.hub.remove(c, false)
+}
+
+// apply changes the subscriptions. The caller must hold c.hub.mu.
+func (c *Conn) apply(msg ClientMessage) error {
+	switch msg.Type {
+	case TypeSubscribe:
+		if err := validateTopic(msg.Topic); err != nil {
+			return err
+		}
+		if _, ok := c.topics[msg.Topic]; ok {
+			return nil
+		}
+		if max := c.hub.limits.MaxSubscriptions; max > 0 && len(c.topics) >= max {
+			return fmt.Errorf("at most %d subscriptions per connection", max)
+		}
+		c.topics[msg.Topic] = struct{}{}
+		return nil
+	case TypeUnsubscribe:
+		delete(c.topics, msg.Topic)
+		return nil
+	default:
+		return fmt.Errorf("%w: type must be one of %s, %s", model.ErrInvalidInput, TypeSubscribe, TypeUnsubscribe)
+	}
+}
+
+// deliver queues an event when c is subscribed to topic. The caller must
+// hold c.hub.mu.
+func (c *Conn) deliver(topic, event string, data interface{}) {
+	if _, ok := c.topics[topic]; !ok {
+		return
+	}
+	c.enqueue(ServerMessage{Type: TypeEvent, Topic: topic, Event: event, Data: data})
+}
+
+// enqueue queues msg without blocking. A client whose buffer is full is
+// dropped rather than slowing down publishers or buffering without bound.
+// The caller must hold c.hub.mu.
+func (c *Conn) enqueue(msg ServerMessage) {
+	if _, ok := c.hub.conns[c]; !ok {
+		return
+	}
+	select {
+	case c.send <- msg:
+	default:
+		c.hub.remove(c, true)
+	}
+}
+
+func validateTopic(topic string) error {
+	switch topic {
+	case TopicMyListings, TopicMyProfile:
+		return nil
+	}
+	if t, ok := strings.CutPrefix(topic, TopicListingTypePrefix); ok && model.ListingType(t).IsValid() {
+		return nil
+	}
+	return fmt.Errorf("%w: unknown topic %q", model.ErrInvalidInput, topic)
+}
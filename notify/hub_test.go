@@ -0,0 +1,134 @@
+package notify_test
+
+import (
+	"public-api/model"
+	"public-api/notify"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// drain returns the messages queued on c without blocking
+func drain(c *notify.Conn) []notify.ServerMessage {
+	var out []notify.ServerMessage
+	for {
+		select {
+		case msg, ok := <-c.Send():
+			if !ok {
+				return out
+			}
+			out = append(out, msg)
+		default:
+			return out
+		}
+	}
+}
+
+func register(t *testing.T, hub *notify.Hub, userID int64, topics ...string) *notify.Conn {
+	c, err := hub.Register(userID)
+	require.NoError(t, err)
+	t.Cleanup(c.Close)
+	for _, topic := range topics {
+		c.Handle(notify.ClientMessage{Type: notify.TypeSubscribe, Topic: topic})
+	}
+	drain(c)
+	return c
+}
+
+func TestHubRoutesEventsToTopics(t *testing.T) {
+	hub := notify.NewHub(notify.Limits{SendBuffer: 10})
+	mine := register(t, hub, 7, notify.TopicMyListings, notify.TopicMyProfile)
+	rent := register(t, hub, 8, "listings.type.rent")
+	nothing := register(t, hub, 9)
+
+	own := model.Listing{ID: 1, UserID: 7, ListingType: "sale"}
+	hub.Publish(own)
+	hub.Publish(model.Listing{ID: 2, UserID: 9, ListingType: "rent"})
+	hub.PublishUserUpdate(model.User{ID: 7, Name: "Jane"})
+	hub.PublishUserUpdate(model.User{ID: 8, Name: "John"})
+
+	assert.Equal(t, []notify.ServerMessage{
+		{Type: notify.TypeEvent, Topic: notify.TopicMyListings, Event: notify.EventListingCreated, Data: own},
+		{Type: notify.TypeEvent, Topic: notify.TopicMyProfile, Event: notify.EventUserUpdated, Data: model.User{ID: 7, Name: "Jane"}},
+	}, drain(mine))
+
+	got := drain(rent)
+	require.Len(t, got, 1)
+	assert.Equal(t, "listings.type.rent", got[0].Topic)
+	assert.Equal(t, int64(2), got[0].Data.(model.Listing).ID)
+
+	assert.Empty(t, drain(nothing))
+}
+
+func TestConnAcknowledgesCommands(t *testing.T) {
+	hub := notify.NewHub(notify.Limits{MaxSubscriptions: 1, SendBuffer: 10})
+	c := register(t, hub, 7)
+
+	c.Handle(notify.ClientMessage{Type: notify.TypeSubscribe, ID: "1", Topic: "listings.type.rent"})
+	c.Handle(notify.ClientMessage{Type: notify.TypeSubscribe, ID: "2", Topic: "listings.type.rent"})
+	c.Handle(notify.ClientMessage{Type: notify.TypeSubscribe, ID: "3", Topic: notify.TopicMyListings})
+	c.Handle(notify.ClientMessage{Type: notify.TypeSubscribe, ID: "4", Topic: "listings.type.castle"})
+	c.Handle(notify.ClientMessage{Type: "publish", ID: "5"})
+	c.Handle(notify.ClientMessage{Type: notify.TypeUnsubscribe, ID: "6", Topic: "listings.type.rent"})
+	c.Handle(notify.ClientMessage{Type: notify.TypeSubscribe, ID: "7", Topic: notify.TopicMyListings})
+
+	got := drain(c)
+	require.Len(t, got, 7)
+	wantTypes := []string{
+		notify.TypeAck,   // subscribed
+		notify.TypeAck,   // already subscribed
+		notify.TypeError, // over the subscription limit
+		notify.TypeError, // unknown listing type
+		notify.TypeError, // unknown command
+		notify.TypeAck,   // unsubscribed
+		notify.TypeAck,   // room again
+	}
+	for i, msg := range got {
+		assert.Equal(t, wantTypes[i], msg.Type, "reply %d", i)
+		assert.Equal(t, string(rune('1'+i)), msg.ID)
+	}
+	assert.Contains(t, got[2].Error, "at most 1 subscriptions")
+	assert.Contains(t, got[3].Error, `unknown topic "listings.type.castle"`)
+}
+
+func TestHubConnectionLimits(t *testing.T) {
+	hub := notify.NewHub(notify.Limits{MaxConnections: 3, MaxConnectionsPerUser: 2})
+
+	first := register(t, hub, 1)
+	register(t, hub, 1)
+	_, err := hub.Register(1)
+	assert.ErrorIs(t, err, notify.ErrTooManyUserConnections)
+
+	register(t, hub, 2)
+	_, err = hub.Register(3)
+	assert.ErrorIs(t, err, notify.ErrTooManyConnections)
+
+	// Closing frees the slot, and closing twice does not free two
+	first.Close()
+	first.Close()
+	assert.Equal(t, 2, hub.Connections())
+	register(t, hub, 1)
+	_, err = hub.Register(1)
+	assert.ErrorIs(t, err, notify.ErrTooManyConnections)
+}
+
+func TestHubDropsSlowConnections(t *testing.T) {
+	hub := notify.NewHub(notify.Limits{SendBuffer: 2})
+	slow := register(t, hub, 7, "listings.type.sale")
+
+	for i := int64(1); i <= 3; i++ {
+		hub.Publish(model.Listing{ID: i, ListingType: "sale"})
+	}
+
+	// The buffered events are still readable, then the channel is closed
+	assert.Len(t, drain(slow), 2)
+	_, open := <-slow.Send()
+	assert.False(t, open)
+	assert.True(t, slow.Dropped())
+	assert.Equal(t, 0, hub.Connections())
+
+	// Commands on a dropped connection are ignored
+	slow.Handle(notify.ClientMessage{Type: notify.TypeSubscribe, Topic: notify.TopicMyListings})
+	slow.Close()
+}
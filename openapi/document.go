@@ -0,0 +1,190 @@
+package openapi
+
+// Version is the OpenAPI specification version documents are written in
+const Version = "3.0.3"
+
+// Document is the root of an OpenAPI 3 document
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+// Info holds API metadata
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// Components holds reusable schemas referenced from operations
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas,omitempty"`
+}
+
+// PathItem holds the operations available on a single path
+type PathItem struct {
+	Get    *Operation `json:"get,omitempty"`
+	Post   *Operation `json:"post,omitempty"`
+	Put    *Operation `json:"put,omitempty"`
+	Patch  *Operation `json:"patch,omitempty"`
+	Delete *Operation `json:"delete,omitempty"`
+}
+
+// Operation describes a single API operation on a path
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	Summary     string               `json:"summary,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+	Deprecated  bool                 `json:"deprecated,omitempty"`
+}
+
+// Parameter describes a path, query or header parameter
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody describes an operation's request payload
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+// Response describes a single response status of an operation
+type Response struct {
+	Description string                `json:"description"`
+	Headers     map[string]*Header    `json:"headers,omitempty"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// Header describes a response header
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// MediaType pairs a content type with the schema of its payload
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Schema is the subset of JSON Schema used by OpenAPI 3.0
+type Schema struct {
+	Ref              string             `json:"$ref,omitempty"`
+	Type             string             `json:"type,omitempty"`
+	Format           string             `json:"format,omitempty"`
+	Description      string             `json:"description,omitempty"`
+	Properties       map[string]*Schema `json:"properties,omitempty"`
+	Required         []string           `json:"required,omitempty"`
+	Items            *Schema            `json:"items,omitempty"`
+	MinItems         *int               `json:"minItems,omitempty"`
+	MaxItems         *int               `json:"maxItems,omitempty"`
+	Enum             []interface{}      `json:"enum,omitempty"`
+	Minimum          *float64           `json:"minimum,omitempty"`
+	Maximum          *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum bool               `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum bool               `json:"exclusiveMaximum,omitempty"`
+	MinLength        *int               `json:"minLength,omitempty"`
+	MaxLength        *int               `json:"maxLength,omitempty"`
+	Pattern          string             `json:"pattern,omitempty"`
+	MultipleOf       *float64           `json:"multipleOf,omitempty"`
+	Nullable         bool               `json:"nullable,omitempty"`
+	OneOf            []*Schema          `json:"oneOf,omitempty"`
+	// ErrorCodes overrides the code reported when a keyword fails, so that
+	// schemas derived from binding rules report the rule like handlers do
+	ErrorCodes map[string]string `json:"x-error-codes,omitempty"`
+}
+
+// Parameter locations
+const (
+	InPath   = "path"
+	InQuery  = "query"
+	InHeader = "header"
+)
+
+// Media types of documented payloads
+const (
+	ContentJSON        = "application/json"
+	ContentEventStream = "text/event-stream"
+	ContentMultipart   = "multipart/form-data"
+	ContentCSV         = "text/csv"
+	ContentNDJSON      = "application/x-ndjson"
+)
+
+// New returns an empty document with the given metadata
+func New(info Info) *Document {
+	return &Document{
+		OpenAPI:    Version,
+		Info:       info,
+		Paths:      make(map[string]*PathItem),
+		Components: Components{Schemas: make(map[string]*Schema)},
+	}
+}
+
+// AddOperation registers op under method and path. Path parameters use the
+// OpenAPI {name} form.
+func (d *Document) AddOperation(method, path string, op *Operation) {
+	item, ok := d.Paths[path]
+	if !ok {
+		item = &PathItem{}
+		d.Paths[path] = item
+	}
+	slot := item.slot(method)
+	if slot == nil {
+		panic("openapi: unsupported method " + method)
+	}
+	*slot = op
+}
+
+// Operation returns the operation registered for method and path, if any
+func (d *Document) Operation(method, path string) *Operation {
+	item, ok := d.Paths[path]
+	if !ok {
+		return nil
+	}
+	if slot := item.slot(method); slot != nil {
+		return *slot
+	}
+	return nil
+}
+
+func (p *PathItem) slot(method string) **Operation {
+	switch method {
+	case "GET":
+		return &p.Get
+	case "POST":
+		return &p.Post
+	case "PUT":
+		return &p.Put
+	case "PATCH":
+		return &p.Patch
+	case "DELETE":
+		return &p.Delete
+	default:
+		return nil
+	}
+}
+
+// JSONContent wraps schema as an application/json content map
+func JSONContent(schema *Schema) map[string]*MediaType {
+	return map[string]*MediaType{ContentJSON: {Schema: schema}}
+}
+
+// Float returns a pointer to f, for schema bounds
+func Float(f float64) *float64 {
+	return &f
+}
+
+// Int returns a pointer to i, for schema lengths
+func Int(i int) *int {
+	return &i
+}
@@ -0,0 +1,203 @@
+package openapi
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// TagFunc applies a custom binding rule with its parameter to a schema
+type TagFunc func(s *Schema, param string)
+
+// Generator derives schemas from Go types, registering named structs as
+// reusable components of a document
+type Generator struct {
+	doc  *Document
+	tags map[string]TagFunc
+}
+
+// NewGenerator returns a generator that stores components in doc
+func NewGenerator(doc *Document) *Generator {
+	return &Generator{doc: doc, tags: make(map[string]TagFunc)}
+}
+
+// RegisterTag teaches the generator how a custom binding tag constrains a field
+func (g *Generator) RegisterTag(tag string, fn TagFunc) {
+	g.tags[tag] = fn
+}
+
+// Schema returns the schema for v's type. Named structs are added to the
+// document's components and referenced.
+func (g *Generator) Schema(v interface{}) *Schema {
+	return g.schemaFor(reflect.TypeOf(v))
+}
+
+func (g *Generator) schemaFor(t reflect.Type) *Schema {
+	switch t.Kind() {
+	case reflect.Ptr:
+		s := g.schemaFor(t.Elem())
+		if s.Ref == "" {
+			s.Nullable = true
+		}
+		return s
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		if _, ok := g.doc.Components.Schemas[t.Name()]; !ok {
+			// Reserve the name first so recursive types terminate
+			g.doc.Components.Schemas[t.Name()] = &Schema{}
+			*g.doc.Components.Schemas[t.Name()] = *g.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + t.Name()}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: g.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	default:
+		return &Schema{}
+	}
+}
+
+func (g *Generator) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name, opts := parseJSONTag(f)
+		if name == "-" {
+			continue
+		}
+
+		prop := g.schemaFor(f.Type)
+		required := g.applyBinding(prop, f.Tag.Get("binding"))
+		// Fields without omitempty are always encoded, so response models
+		// without binding rules list them as required
+		if required || (!strings.Contains(opts, "omitempty") && f.Tag.Get("binding") == "") {
+			s.Required = append(s.Required, name)
+		}
+		s.Properties[name] = prop
+	}
+	return s
+}
+
+// applyBinding maps Gin binding rules onto schema constraints and reports
+// whether the field is required. Rules after dive constrain array items.
+// Keywords set by a rule fail with the rule's name as their code.
+func (g *Generator) applyBinding(s *Schema, tag string) bool {
+	required, dived := false, false
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		target, before := s, s.keywords()
+		switch name {
+		case "":
+		case "required":
+			required = required || !dived
+		case "dive":
+			if s.Items != nil {
+				s, dived = s.Items, true
+			}
+		case "gt":
+			s.Minimum, s.ExclusiveMinimum = parseBound(param), true
+		case "gte", "min":
+			g.applyMin(s, param)
+		case "lt":
+			s.Maximum, s.ExclusiveMaximum = parseBound(param), true
+		case "lte", "max":
+			g.applyMax(s, param)
+		case "oneof":
+			for _, v := range strings.Fields(param) {
+				s.Enum = append(s.Enum, v)
+			}
+		default:
+			if fn, ok := g.tags[name]; ok {
+				fn(s, param)
+			}
+		}
+		for keyword := range target.keywords() {
+			if _, ok := before[keyword]; ok {
+				continue
+			}
+			if target.ErrorCodes == nil {
+				target.ErrorCodes = make(map[string]string)
+			}
+			target.ErrorCodes[keyword] = name
+		}
+	}
+	return required
+}
+
+// keywords returns the validation keywords s constrains values with
+func (s *Schema) keywords() map[string]struct{} {
+	set := map[string]bool{
+		CodeEnum:       len(s.Enum) > 0,
+		CodeMinimum:    s.Minimum != nil,
+		CodeMaximum:    s.Maximum != nil,
+		CodeMinLength:  s.MinLength != nil,
+		CodeMaxLength:  s.MaxLength != nil,
+		CodeMinItems:   s.MinItems != nil,
+		CodeMaxItems:   s.MaxItems != nil,
+		CodePattern:    s.Pattern != "",
+		CodeMultipleOf: s.MultipleOf != nil,
+	}
+	out := make(map[string]struct{})
+	for keyword, ok := range set {
+		if ok {
+			out[keyword] = struct{}{}
+		}
+	}
+	return out
+}
+
+func (g *Generator) applyMin(s *Schema, param string) {
+	n, _ := strconv.Atoi(param)
+	switch s.Type {
+	case "string":
+		s.MinLength = Int(n)
+	case "array":
+		s.MinItems = Int(n)
+	default:
+		s.Minimum = parseBound(param)
+	}
+}
+
+func (g *Generator) applyMax(s *Schema, param string) {
+	n, _ := strconv.Atoi(param)
+	switch s.Type {
+	case "string":
+		s.MaxLength = Int(n)
+	case "array":
+		s.MaxItems = Int(n)
+	default:
+		s.Maximum = parseBound(param)
+	}
+}
+
+func parseBound(param string) *float64 {
+	f, _ := strconv.ParseFloat(param, 64)
+	return Float(f)
+}
+
+func parseJSONTag(f reflect.StructField) (name, opts string) {
+	tag := f.Tag.Get("json")
+	name, opts, _ = strings.Cut(tag, ",")
+	if name == "" {
+		name = f.Name
+	}
+	return name, opts
+}
@@ -0,0 +1,74 @@
+package openapi_test
+
+import (
+	"public-api/openapi"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type owner struct {
+	ID int64 `json:"id"`
+}
+
+type sample struct {
+	Name     string   `json:"name" binding:"required,min=2,max=10"`
+	Kind     string   `json:"kind" binding:"required,oneof=a b"`
+	Amount   float64  `json:"amount" binding:"required,gt=0,lte=100"`
+	Code     string   `json:"code" binding:"custom"`
+	Note     string   `json:"note,omitempty"`
+	Owner    *owner   `json:"owner,omitempty"`
+	Tags     []string `json:"tags"`
+	Kinds    []string `json:"kinds,omitempty" binding:"omitempty,min=1,max=3,dive,required,oneof=x y"`
+	internal string
+}
+
+func TestGeneratorSchema(t *testing.T) {
+	doc := openapi.New(openapi.Info{Title: "test", Version: "1"})
+	g := openapi.NewGenerator(doc)
+	g.RegisterTag("custom", func(s *openapi.Schema, _ string) {
+		s.Pattern = "^[A-Z]+$"
+	})
+
+	ref := g.Schema(sample{})
+	assert.Equal(t, "#/components/schemas/sample", ref.Ref)
+
+	s := doc.Components.Schemas["sample"]
+	require.NotNil(t, s)
+	assert.Equal(t, "object", s.Type)
+	assert.ElementsMatch(t, []string{"name", "kind", "amount", "tags"}, s.Required)
+	assert.NotContains(t, s.Properties, "internal")
+
+	assert.Equal(t, 2, *s.Properties["name"].MinLength)
+	assert.Equal(t, 10, *s.Properties["name"].MaxLength)
+	assert.Equal(t, []interface{}{"a", "b"}, s.Properties["kind"].Enum)
+	assert.Equal(t, 0.0, *s.Properties["amount"].Minimum)
+	assert.True(t, s.Properties["amount"].ExclusiveMinimum)
+	assert.Equal(t, 100.0, *s.Properties["amount"].Maximum)
+	assert.Equal(t, "^[A-Z]+$", s.Properties["code"].Pattern)
+	assert.Equal(t, "#/components/schemas/owner", s.Properties["owner"].Ref)
+	assert.Equal(t, "array", s.Properties["tags"].Type)
+	assert.Equal(t, 1, *s.Properties["kinds"].MinItems)
+	assert.Equal(t, 3, *s.Properties["kinds"].MaxItems)
+	assert.Equal(t, []interface{}{"x", "y"}, s.Properties["kinds"].Items.Enum)
+	assert.Contains(t, doc.Components.Schemas, "owner")
+
+	// Failed keywords report the binding rule that set them
+	assert.Equal(t, map[string]string{"minLength": "min", "maxLength": "max"}, s.Properties["name"].ErrorCodes)
+	assert.Equal(t, map[string]string{"enum": "oneof"}, s.Properties["kind"].ErrorCodes)
+	assert.Equal(t, map[string]string{"minimum": "gt", "maximum": "lte"}, s.Properties["amount"].ErrorCodes)
+	assert.Equal(t, map[string]string{"pattern": "custom"}, s.Properties["code"].ErrorCodes)
+	assert.Equal(t, map[string]string{"minItems": "min", "maxItems": "max"}, s.Properties["kinds"].ErrorCodes)
+	assert.Equal(t, map[string]string{"enum": "oneof"}, s.Properties["kinds"].Items.ErrorCodes)
+}
+
+func TestDocumentOperation(t *testing.T) {
+	doc := openapi.New(openapi.Info{Title: "test", Version: "1"})
+	op := &openapi.Operation{OperationID: "getThing"}
+	doc.AddOperation("GET", "/things/{id}", op)
+
+	assert.Same(t, op, doc.Operation("GET", "/things/{id}"))
+	assert.Nil(t, doc.Operation("POST", "/things/{id}"))
+	assert.Nil(t, doc.Operation("GET", "/missing"))
+}
@@ -0,0 +1,256 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Validation error codes
+const (
+	CodeRequired   = "required"
+	CodeType       = "type"
+	CodeEnum       = "enum"
+	CodeMinimum    = "minimum"
+	CodeMaximum    = "maximum"
+	CodeMinLength  = "minLength"
+	CodeMaxLength  = "maxLength"
+	CodeMinItems   = "minItems"
+	CodeMaxItems   = "maxItems"
+	CodePattern    = "pattern"
+	CodeMultipleOf = "multipleOf"
+	CodeOneOf      = "oneOf"
+)
+
+// ValidationError describes a value that does not satisfy its schema
+type ValidationError struct {
+	Path    string
+	Code    string
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// Resolve follows s's $ref, if any, to the component it points at
+func (d *Document) Resolve(s *Schema) *Schema {
+	for s != nil && s.Ref != "" {
+		s = d.Components.Schemas[strings.TrimPrefix(s.Ref, "#/components/schemas/")]
+	}
+	return s
+}
+
+// ValidateValue checks a value decoded with json.Decoder.UseNumber against s.
+// path names the value in returned errors.
+func (d *Document) ValidateValue(s *Schema, v interface{}, path string) []ValidationError {
+	s = d.Resolve(s)
+	if s == nil {
+		return nil
+	}
+
+	if v == nil {
+		if s.Nullable || s.Type == "" {
+			return nil
+		}
+		return []ValidationError{{path, CodeType, "must not be null"}}
+	}
+
+	if len(s.OneOf) > 0 {
+		matches := 0
+		for _, alt := range s.OneOf {
+			if len(d.ValidateValue(alt, v, path)) == 0 {
+				matches++
+			}
+		}
+		if matches != 1 {
+			return []ValidationError{{path, CodeOneOf, fmt.Sprintf("must match exactly one schema, matched %d", matches)}}
+		}
+		return nil
+	}
+
+	var errs []ValidationError
+	switch s.Type {
+	case "object":
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return []ValidationError{typeError(path, s.Type)}
+		}
+		for _, name := range s.Required {
+			if _, ok := obj[name]; !ok {
+				errs = append(errs, ValidationError{join(path, name), CodeRequired, "is required"})
+			}
+		}
+		for name, prop := range s.Properties {
+			if val, ok := obj[name]; ok {
+				errs = append(errs, d.ValidateValue(prop, val, join(path, name))...)
+			}
+		}
+		return errs
+	case "array":
+		arr, ok := v.([]interface{})
+		if !ok {
+			return []ValidationError{typeError(path, s.Type)}
+		}
+		if s.MinItems != nil && len(arr) < *s.MinItems {
+			errs = append(errs, ValidationError{path, s.errorCode(CodeMinItems), fmt.Sprintf("must have at least %d items", *s.MinItems)})
+		}
+		if s.MaxItems != nil && len(arr) > *s.MaxItems {
+			errs = append(errs, ValidationError{path, s.errorCode(CodeMaxItems), fmt.Sprintf("must have at most %d items", *s.MaxItems)})
+		}
+		for i, item := range arr {
+			errs = append(errs, d.ValidateValue(s.Items, item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+		return errs
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			return []ValidationError{typeError(path, s.Type)}
+		}
+		return validateString(s, str, path)
+	case "integer", "number":
+		n, ok := v.(json.Number)
+		if !ok {
+			return []ValidationError{typeError(path, s.Type)}
+		}
+		f, err := n.Float64()
+		if err != nil || (s.Type == "integer" && f != math.Trunc(f)) {
+			return []ValidationError{typeError(path, s.Type)}
+		}
+		return validateNumber(s, f, path)
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return []ValidationError{typeError(path, s.Type)}
+		}
+	}
+	return nil
+}
+
+// ParseParam converts a raw path, query or header value into the type its
+// schema declares so it can be passed to ValidateValue
+func ParseParam(s *Schema, raw string) (interface{}, *ValidationError) {
+	switch s.Type {
+	case "integer":
+		if _, err := strconv.ParseInt(raw, 10, 64); err != nil {
+			e := typeError("", s.Type)
+			return nil, &e
+		}
+		return json.Number(raw), nil
+	case "number":
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			e := typeError("", s.Type)
+			return nil, &e
+		}
+		return json.Number(raw), nil
+	case "boolean":
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			e := typeError("", s.Type)
+			return nil, &e
+		}
+		return b, nil
+	default:
+		return raw, nil
+	}
+}
+
+func validateString(s *Schema, str, path string) []ValidationError {
+	var errs []ValidationError
+	if len(s.Enum) > 0 && !inEnum(s.Enum, str) {
+		errs = append(errs, ValidationError{path, s.errorCode(CodeEnum), "must be one of: " + enumList(s.Enum)})
+	}
+	n := utf8.RuneCountInString(str)
+	if s.MinLength != nil && n < *s.MinLength {
+		errs = append(errs, ValidationError{path, s.errorCode(CodeMinLength), fmt.Sprintf("must be at least %d characters", *s.MinLength)})
+	}
+	if s.MaxLength != nil && n > *s.MaxLength {
+		errs = append(errs, ValidationError{path, s.errorCode(CodeMaxLength), fmt.Sprintf("must be at most %d characters", *s.MaxLength)})
+	}
+	if s.Pattern != "" {
+		if re, err := regexp.Compile(s.Pattern); err == nil && !re.MatchString(str) {
+			errs = append(errs, ValidationError{path, s.errorCode(CodePattern), "must match " + s.Pattern})
+		}
+	}
+	return errs
+}
+
+func validateNumber(s *Schema, f float64, path string) []ValidationError {
+	var errs []ValidationError
+	if len(s.Enum) > 0 && !inEnum(s.Enum, strconv.FormatFloat(f, 'f', -1, 64)) {
+		errs = append(errs, ValidationError{path, s.errorCode(CodeEnum), "must be one of: " + enumList(s.Enum)})
+	}
+	if s.Minimum != nil {
+		if s.ExclusiveMinimum && f <= *s.Minimum {
+			errs = append(errs, ValidationError{path, s.errorCode(CodeMinimum), "must be greater than " + formatBound(*s.Minimum)})
+		} else if f < *s.Minimum {
+			errs = append(errs, ValidationError{path, s.errorCode(CodeMinimum), "must be at least " + formatBound(*s.Minimum)})
+		}
+	}
+	if s.Maximum != nil {
+		if s.ExclusiveMaximum && f >= *s.Maximum {
+			errs = append(errs, ValidationError{path, s.errorCode(CodeMaximum), "must be less than " + formatBound(*s.Maximum)})
+		} else if f > *s.Maximum {
+			errs = append(errs, ValidationError{path, s.errorCode(CodeMaximum), "must be at most " + formatBound(*s.Maximum)})
+		}
+	}
+	if s.MultipleOf != nil && *s.MultipleOf > 0 {
+		// Compare with a tolerance since values like 0.01 are not exact in binary
+		q := f / *s.MultipleOf
+		if math.Abs(q-math.Round(q)) > 1e-9*math.Max(1, math.Abs(q)) {
+			errs = append(errs, ValidationError{path, s.errorCode(CodeMultipleOf), "must be a multiple of " + formatBound(*s.MultipleOf)})
+		}
+	}
+	return errs
+}
+
+// errorCode returns the code reported when keyword fails
+func (s *Schema) errorCode(keyword string) string {
+	if code, ok := s.ErrorCodes[keyword]; ok {
+		return code
+	}
+	return keyword
+}
+
+func typeError(path, typ string) ValidationError {
+	article := "a"
+	if typ == "integer" || typ == "object" || typ == "array" {
+		article = "an"
+	}
+	return ValidationError{path, CodeType, fmt.Sprintf("must be %s %s", article, typ)}
+}
+
+func inEnum(enum []interface{}, v string) bool {
+	for _, e := range enum {
+		if fmt.Sprint(e) == v {
+			return true
+		}
+	}
+	return false
+}
+
+func enumList(enum []interface{}) string {
+	parts := make([]string, len(enum))
+	for i, e := range enum {
+		parts[i] = fmt.Sprint(e)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func formatBound(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
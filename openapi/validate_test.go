@@ -0,0 +1,122 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"public-api/openapi"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateValue(t *testing.T) {
+	doc := openapi.New(openapi.Info{Title: "test", Version: "1"})
+	doc.Components.Schemas["Item"] = &openapi.Schema{
+		Type:     "object",
+		Required: []string{"id", "kind"},
+		Properties: map[string]*openapi.Schema{
+			"id":    {Type: "integer", Minimum: openapi.Float(1)},
+			"kind":  {Type: "string", Enum: []interface{}{"a", "b"}},
+			"price": {Type: "number", Minimum: openapi.Float(0), ExclusiveMinimum: true, Maximum: openapi.Float(100), MultipleOf: openapi.Float(0.01)},
+			"name":  {Type: "string", MinLength: openapi.Int(2), MaxLength: openapi.Int(4)},
+			"tags":  {Type: "array", Items: &openapi.Schema{Type: "string"}, MaxItems: openapi.Int(2)},
+			"codes": {Type: "array", Items: &openapi.Schema{Type: "string"}, MinItems: openapi.Int(1)},
+		},
+	}
+	ref := &openapi.Schema{Ref: "#/components/schemas/Item"}
+
+	tests := []struct {
+		name      string
+		body      string
+		wantCodes map[string]string
+	}{
+		{
+			name: "valid",
+			body: `{"id": 1, "kind": "a", "price": 10.25, "name": "abc", "tags": ["x"]}`,
+		},
+		{
+			name:      "missing required",
+			body:      `{"id": 1}`,
+			wantCodes: map[string]string{"kind": openapi.CodeRequired},
+		},
+		{
+			name: "wrong types",
+			body: `{"id": 1.5, "kind": 3, "tags": "x"}`,
+			wantCodes: map[string]string{
+				"id":   openapi.CodeType,
+				"kind": openapi.CodeType,
+				"tags": openapi.CodeType,
+			},
+		},
+		{
+			name: "constraint violations",
+			body: `{"id": 0, "kind": "c", "price": 0, "name": "abcde", "tags": [1]}`,
+			wantCodes: map[string]string{
+				"id":      openapi.CodeMinimum,
+				"kind":    openapi.CodeEnum,
+				"price":   openapi.CodeMinimum,
+				"name":    openapi.CodeMaxLength,
+				"tags[0]": openapi.CodeType,
+			},
+		},
+		{
+			name: "above maximum",
+			body: `{"id": 1, "kind": "a", "price": 100.5}`,
+			wantCodes: map[string]string{
+				"price": openapi.CodeMaximum,
+			},
+		},
+		{
+			name: "item counts",
+			body: `{"id": 1, "kind": "a", "tags": ["x", "y", "z"], "codes": []}`,
+			wantCodes: map[string]string{
+				"tags":  openapi.CodeMaxItems,
+				"codes": openapi.CodeMinItems,
+			},
+		},
+		{
+			name:      "not a multiple",
+			body:      `{"id": 1, "kind": "a", "price": 1.005}`,
+			wantCodes: map[string]string{"price": openapi.CodeMultipleOf},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v interface{}
+			dec := json.NewDecoder(strings.NewReader(tt.body))
+			dec.UseNumber()
+			assert.NoError(t, dec.Decode(&v))
+
+			errs := doc.ValidateValue(ref, v, "")
+			got := make(map[string]string, len(errs))
+			for _, e := range errs {
+				got[e.Path] = e.Code
+			}
+			if len(tt.wantCodes) == 0 {
+				assert.Empty(t, errs)
+				return
+			}
+			for path, code := range tt.wantCodes {
+				assert.Equalf(t, code, got[path], "path %s", path)
+			}
+		})
+	}
+}
+
+func TestParseParam(t *testing.T) {
+	v, err := openapi.ParseParam(&openapi.Schema{Type: "integer"}, "42")
+	assert.Nil(t, err)
+	assert.Equal(t, json.Number("42"), v)
+
+	_, err = openapi.ParseParam(&openapi.Schema{Type: "integer"}, "4.2")
+	assert.NotNil(t, err)
+	assert.Equal(t, openapi.CodeType, err.Code)
+
+	_, err = openapi.ParseParam(&openapi.Schema{Type: "number"}, "abc")
+	assert.NotNil(t, err)
+
+	v, err = openapi.ParseParam(&openapi.Schema{Type: "string"}, "rent")
+	assert.Nil(t, err)
+	assert.Equal(t, "rent", v)
+}
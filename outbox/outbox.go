@@ -0,0 +1,122 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"public-api/event"
+	"time"
+)
+
+// Outbox makes event publishing survive crashes. Publish writes the event to
+// the store before returning, and Run relays stored events to the target,
+// removing each only after it was published. An event relayed just before a
+// crash is relayed again on restart, so consumers see events at least once
+// and should drop duplicates by event ID.
+type Outbox struct {
+	store        Store
+	target       event.Publisher
+	pollInterval time.Duration
+	batchSize    int
+	wake         chan struct{}
+}
+
+// Option configures an Outbox
+type Option func(*Outbox)
+
+// WithPollInterval sets how often the relay checks the store when it has
+// not been woken by Publish
+func WithPollInterval(d time.Duration) Option {
+	return func(o *Outbox) {
+		o.pollInterval = d
+	}
+}
+
+// WithBatchSize sets how many records the relay reads at a time
+func WithBatchSize(n int) Option {
+	return func(o *Outbox) {
+		o.batchSize = n
+	}
+}
+
+// New returns an outbox relaying events from store to target
+func New(store Store, target event.Publisher, opts ...Option) *Outbox {
+	o := &Outbox{
+		store:        store,
+		target:       target,
+		pollInterval: time.Second,
+		batchSize:    100,
+		wake:         make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.batchSize < 1 {
+		o.batchSize = 1
+	}
+	return o
+}
+
+// Publish stores e for the relay. If the store fails the event is published
+// to the target directly, so it is delivered unless the process crashes.
+func (o *Outbox) Publish(ctx context.Context, e event.Event) {
+	payload, err := json.Marshal(e)
+	if err == nil {
+		_, err = o.store.Append(e.EventName(), payload)
+	}
+	if err != nil {
+		log.Printf("outbox: failed to store %s %s, publishing directly: %v", e.EventName(), e.EventID(), err)
+		o.target.Publish(ctx, e)
+		return
+	}
+
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run relays stored events until ctx is canceled, starting with those left
+// over from a previous run. Events are relayed in the order they were
+// stored; only one Run may be active per store.
+func (o *Outbox) Run(ctx context.Context) {
+	ticker := time.NewTicker(o.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		o.relay(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-o.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// relay publishes pending records until the store is empty or fails
+func (o *Outbox) relay(ctx context.Context) {
+	for ctx.Err() == nil {
+		records, err := o.store.Pending(o.batchSize)
+		if err != nil {
+			log.Printf("outbox: %v", err)
+			return
+		}
+		for _, r := range records {
+			e, err := event.Decode(r.Name, r.Payload)
+			if err != nil {
+				// Retrying cannot help a record that does not decode
+				log.Printf("outbox: dropping record %d: %v", r.Seq, err)
+			} else {
+				o.target.Publish(ctx, e)
+			}
+			if err := o.store.Delete(r.Seq); err != nil {
+				log.Printf("outbox: %v", err)
+				return
+			}
+		}
+		if len(records) < o.batchSize {
+			return
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package outbox_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"public-api/event"
+	"public-api/model"
+	"public-api/outbox"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingPublisher struct {
+	mu     sync.Mutex
+	events []event.Event
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, e event.Event) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, e)
+}
+
+func (p *recordingPublisher) published() []event.Event {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]event.Event(nil), p.events...)
+}
+
+type failingStore struct{ outbox.Store }
+
+func (failingStore) Append(string, []byte) (uint64, error) { return 0, errors.New("disk full") }
+
+func openStore(t *testing.T, path string) *outbox.BoltStore {
+	store, err := outbox.OpenBolt(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func runRelay(t *testing.T, o *outbox.Outbox) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		o.Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+func TestBoltStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.db")
+	store := openStore(t, path)
+
+	for _, name := range []string{"a", "b", "c"} {
+		_, err := store.Append(name, []byte(`{}`))
+		require.NoError(t, err)
+	}
+	records, err := store.Pending(2)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "a", records[0].Name)
+	assert.Equal(t, "b", records[1].Name)
+
+	require.NoError(t, store.Delete(records[0].Seq))
+	require.NoError(t, store.Close())
+
+	// Records survive reopening the file
+	store = openStore(t, path)
+	records, err = store.Pending(10)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "b", records[0].Name)
+	assert.Equal(t, "c", records[1].Name)
+	assert.Equal(t, []byte(`{}`), records[1].Payload)
+}
+
+func TestOutboxRelaysStoredEvents(t *testing.T) {
+	store := openStore(t, filepath.Join(t.TempDir(), "outbox.db"))
+	target := &recordingPublisher{}
+	o := outbox.New(store, target, outbox.WithPollInterval(time.Hour), outbox.WithBatchSize(1))
+	runRelay(t, o)
+
+	first := event.UserCreated{Meta: event.NewMeta(), User: model.User{ID: 1, Name: "Jane"}}
+	second := event.ListingCreated{Meta: event.NewMeta(), Listing: model.Listing{ID: 2, UserID: 1}}
+	o.Publish(context.Background(), first)
+	o.Publish(context.Background(), second)
+
+	require.Eventually(t, func() bool { return len(target.published()) == 2 }, time.Second, time.Millisecond)
+	events := target.published()
+	assert.Equal(t, first.EventID(), events[0].EventID())
+	assert.Equal(t, first.User, events[0].(event.UserCreated).User)
+	assert.Equal(t, second.Listing, events[1].(event.ListingCreated).Listing)
+
+	require.Eventually(t, func() bool {
+		pending, _ := store.Pending(10)
+		return len(pending) == 0
+	}, time.Second, time.Millisecond)
+}
+
+func TestOutboxRelaysEventsLeftByPreviousRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.db")
+	store := openStore(t, path)
+
+	// Stored but never relayed, as if the process crashed
+	published := event.UserUpdated{Meta: event.NewMeta(), User: model.User{ID: 3}}
+	outbox.New(store, &recordingPublisher{}).Publish(context.Background(), published)
+	_, err := store.Append("listing.deleted", []byte(`{}`))
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+
+	target := &recordingPublisher{}
+	runRelay(t, outbox.New(openStore(t, path), target, outbox.WithPollInterval(time.Hour)))
+
+	require.Eventually(t, func() bool { return len(target.published()) == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, published.EventID(), target.published()[0].EventID())
+}
+
+func TestOutboxPublishesDirectlyWhenStoreFails(t *testing.T) {
+	target := &recordingPublisher{}
+	o := outbox.New(failingStore{}, target)
+
+	e := event.UserCreated{Meta: event.NewMeta()}
+	o.Publish(context.Background(), e)
+
+	assert.Equal(t, []event.Event{e}, target.published())
+}
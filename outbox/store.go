@@ -0,0 +1,118 @@
+package outbox
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var bucket = []byte("outbox")
+
+// Record is an event waiting to be relayed
+type Record struct {
+	Seq       uint64    `json:"-"`
+	Name      string    `json:"name"`
+	Payload   []byte    `json:"payload"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Store persists outbox records. Records come back from Pending in the
+// order they were appended.
+type Store interface {
+	Append(name string, payload []byte) (uint64, error)
+	Pending(limit int) ([]Record, error)
+	Delete(seq uint64) error
+	Close() error
+}
+
+// BoltStore is a Store backed by a BoltDB file. Every append is synced to
+// disk before it returns.
+type BoltStore struct {
+	db  *bolt.DB
+	now func() time.Time
+}
+
+// OpenBolt opens or creates the outbox file at path
+func OpenBolt(path string) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open outbox %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(bucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to init outbox %s: %w", path, err)
+	}
+	return &BoltStore{db: db, now: time.Now}, nil
+}
+
+// Append stores an event and returns its sequence number
+func (s *BoltStore) Append(name string, payload []byte) (uint64, error) {
+	var seq uint64
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucket)
+		var err error
+		if seq, err = b.NextSequence(); err != nil {
+			return err
+		}
+		value, err := json.Marshal(Record{Name: name, Payload: payload, CreatedAt: s.now()})
+		if err != nil {
+			return err
+		}
+		return b.Put(key(seq), value)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to append to outbox: %w", err)
+	}
+	return seq, nil
+}
+
+// Pending returns up to limit of the oldest records
+func (s *BoltStore) Pending(limit int) ([]Record, error) {
+	var records []Record
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(bucket).Cursor()
+		for k, v := c.First(); k != nil && len(records) < limit; k, v = c.Next() {
+			var r Record
+			if err := json.Unmarshal(v, &r); err != nil {
+				return fmt.Errorf("record %d: %w", binary.BigEndian.Uint64(k), err)
+			}
+			r.Seq = binary.BigEndian.Uint64(k)
+			records = append(records, r)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read outbox: %w", err)
+	}
+	return records, nil
+}
+
+// Delete removes a relayed record
+func (s *BoltStore) Delete(seq uint64) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucket).Delete(key(seq))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete outbox record %d: %w", seq, err)
+	}
+	return nil
+}
+
+// Close closes the file
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
+
+// key encodes seq big-endian so keys sort in append order
+func key(seq uint64) []byte {
+	k := make([]byte, 8)
+	binary.BigEndian.PutUint64(k, seq)
+	return k
+}
@@ -0,0 +1,5 @@
+// Package pb holds the protobuf messages and gRPC services of the public API.
+// Regenerate after editing public_api.proto with go generate.
+package pb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative public_api.proto
@@ -0,0 +1,5 @@
+// Package listingservice holds the gRPC client stubs of the listing-service.
+// Regenerate after editing listing_service.proto with go generate.
+package listingservice
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative listing_service.proto
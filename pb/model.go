@@ -0,0 +1,42 @@
+package pb
+
+import "public-api/model"
+
+// FromUser converts a user to its message, keeping nil as nil
+func FromUser(u *model.User) *User {
+	if u == nil {
+		return nil
+	}
+	return &User{
+		Id:        u.ID,
+		Name:      u.Name,
+		CreatedAt: u.CreatedAt,
+		UpdatedAt: u.UpdatedAt,
+	}
+}
+
+// FromListing converts a listing and its owner to its message, keeping nil
+// as nil
+func FromListing(l *model.Listing) *Listing {
+	if l == nil {
+		return nil
+	}
+	return &Listing{
+		Id:          l.ID,
+		UserId:      l.UserID,
+		ListingType: l.ListingType,
+		Price:       l.Price,
+		CreatedAt:   l.CreatedAt,
+		UpdatedAt:   l.UpdatedAt,
+		User:        FromUser(l.User),
+	}
+}
+
+// FromListings converts a page of listings to a GetListingsResponse
+func FromListings(ls []model.Listing) *GetListingsResponse {
+	out := &GetListingsResponse{Listings: make([]*Listing, len(ls))}
+	for i := range ls {
+		out.Listings[i] = FromListing(&ls[i])
+	}
+	return out
+}
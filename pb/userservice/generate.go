@@ -0,0 +1,5 @@
+// Package userservice holds the gRPC client stubs of the user-service.
+// Regenerate after editing user_service.proto with go generate.
+package userservice
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative user_service.proto
@@ -0,0 +1,632 @@
+package router
+
+import (
+	_ "embed"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"public-api/apiversion"
+	"public-api/content"
+	"public-api/graph"
+	"public-api/model"
+	"public-api/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed swagger.html
+var swaggerHTML []byte
+
+// APISpec describes every route registered by SetupRouter
+func APISpec() *openapi.Document {
+	doc := openapi.New(openapi.Info{
+		Title:       "Public API",
+		Description: "Public API layer aggregating the user-service and listing-service.",
+		Version:     "1.0.0",
+	})
+
+	g := openapi.NewGenerator(doc)
+	g.RegisterTag("listing_type", func(s *openapi.Schema, _ string) {
+		for _, t := range model.ListingTypes {
+			s.Enum = append(s.Enum, string(t))
+		}
+	})
+	g.RegisterTag("listing_price", func(s *openapi.Schema, _ string) {
+		s.Minimum, s.ExclusiveMinimum = openapi.Float(0), true
+		s.Maximum = openapi.Float(model.MaxListingPrice)
+		s.MultipleOf = openapi.Float(0.01)
+	})
+	g.RegisterTag("webhook_url", func(s *openapi.Schema, _ string) {
+		s.Format = "uri"
+		s.Description = "Absolute http or https URL"
+	})
+	g.RegisterTag("webhook_event", func(s *openapi.Schema, _ string) {
+		for _, t := range model.WebhookEventTypes {
+			s.Enum = append(s.Enum, string(t))
+		}
+	})
+	g.RegisterTag("user_name", func(s *openapi.Schema, _ string) {
+		s.MinLength = openapi.Int(model.MinUserNameLength)
+		s.MaxLength = openapi.Int(model.MaxUserNameLength)
+		s.Description = "Letters, spaces, apostrophes, hyphens and periods"
+	})
+
+	errSchema := g.Schema(model.ErrorResponse{})
+
+	doc.AddOperation(http.MethodGet, "/ping", &openapi.Operation{
+		OperationID: "ping",
+		Summary:     "Health check",
+		Tags:        []string{"system"},
+		Responses: map[string]*openapi.Response{
+			"200": jsonResponse("Service is up", envelope("message", &openapi.Schema{Type: "string"})),
+		},
+	})
+
+	doc.AddOperation(http.MethodGet, "/openapi.json", &openapi.Operation{
+		OperationID: "getOpenAPISpec",
+		Summary:     "OpenAPI specification of this API",
+		Tags:        []string{"system"},
+		Responses: map[string]*openapi.Response{
+			"200": jsonResponse("OpenAPI 3 document", &openapi.Schema{Type: "object"}),
+		},
+	})
+
+	doc.AddOperation(http.MethodGet, "/docs", &openapi.Operation{
+		OperationID: "getDocs",
+		Summary:     "Swagger UI for this API",
+		Tags:        []string{"system"},
+		Responses: map[string]*openapi.Response{
+			"200": {
+				Description: "HTML page",
+				Content:     map[string]*openapi.MediaType{"text/html": {Schema: &openapi.Schema{Type: "string"}}},
+			},
+		},
+	})
+
+	doc.AddOperation(http.MethodPost, "/graphql", &openapi.Operation{
+		OperationID: "graphql",
+		Summary:     "Query users and listings with GraphQL",
+		Description: "Errors in the query itself, including exceeded depth or complexity limits, are reported in the errors array with a 200.",
+		Tags:        []string{"graphql"},
+		RequestBody: jsonBody(g.Schema(graph.QueryRequest{})),
+		Responses: map[string]*openapi.Response{
+			"200": jsonResponse("Query result", g.Schema(graph.QueryResponse{})),
+			"400": jsonResponse("Invalid request", errSchema),
+		},
+	})
+
+	doc.AddOperation(http.MethodGet, "/ws", &openapi.Operation{
+		OperationID: "notifications",
+		Summary:     "WebSocket notifications channel",
+		Description: "Send {\"type\":\"subscribe\"|\"unsubscribe\",\"id\":...,\"topic\":...} to manage subscriptions; each command is answered with an \"ack\" or \"error\" echoing its id. " +
+			"Topics are \"listings.mine\" and \"users.me\" for the authenticated user, and \"listings.type.sale\" or \"listings.type.rent\". " +
+			"Events arrive as {\"type\":\"event\",\"topic\":...,\"event\":\"listing.created\"|\"user.updated\",\"data\":...}. " +
+			"The server pings periodically and closes connections that stop answering, or that fall behind with close code 1013.",
+		Tags: []string{"notifications"},
+		Parameters: []*openapi.Parameter{
+			{Name: "Authorization", In: openapi.InHeader, Description: "Bearer access token", Schema: &openapi.Schema{Type: "string"}},
+			{Name: "access_token", In: openapi.InQuery, Description: "Access token, for clients that cannot set headers", Schema: &openapi.Schema{Type: "string"}},
+		},
+		Responses: map[string]*openapi.Response{
+			"101": {Description: "Switched to the WebSocket protocol"},
+			"401": jsonResponse("Missing or invalid access token", errSchema),
+			"429": jsonResponse("Too many connections for this user", errSchema),
+			"503": jsonResponse("Too many connections", errSchema),
+		},
+	})
+
+	addAdminOperations(doc, g, errSchema)
+
+	listings := sparseListings(doc, g)
+	listingPage := g.Schema(model.SparseListingPage{})
+	doc.Resolve(listingPage).Properties["data"] = listings
+
+	v1 := bodies{
+		user:       envelope("user", g.Schema(model.User{})),
+		listing:    envelope("listing", g.Schema(model.Listing{})),
+		listings:   envelope("listings", listings),
+		results:    envelope("results", &openapi.Schema{Type: "array", Items: g.Schema(model.ListingResult{})}),
+		onboarding: g.Schema(model.Onboarding{}),
+		importJob:  envelope("import", g.Schema(model.ImportJob{})),
+	}
+	v2 := bodies{
+		user:       g.Schema(model.UserEnvelope{}),
+		listing:    g.Schema(model.ListingEnvelope{}),
+		listings:   listingPage,
+		results:    g.Schema(model.ListingResultsEnvelope{}),
+		onboarding: g.Schema(model.OnboardingEnvelope{}),
+		importJob:  g.Schema(model.ImportJobEnvelope{}),
+	}
+	negotiated := bodies{
+		user:       &openapi.Schema{OneOf: []*openapi.Schema{v1.user, v2.user}},
+		listing:    &openapi.Schema{OneOf: []*openapi.Schema{v1.listing, v2.listing}},
+		listings:   &openapi.Schema{OneOf: []*openapi.Schema{v1.listings, v2.listings}},
+		results:    &openapi.Schema{OneOf: []*openapi.Schema{v1.results, v2.results}},
+		onboarding: &openapi.Schema{OneOf: []*openapi.Schema{v1.onboarding, v2.onboarding}},
+		importJob:  &openapi.Schema{OneOf: []*openapi.Schema{v1.importJob, v2.importJob}},
+	}
+
+	addAPIOperations(doc, g, errSchema, "/api/v1", "V1", v1, true)
+	addAPIOperations(doc, g, errSchema, "/api/v2", "V2", v2, false)
+	addAPIOperations(doc, g, errSchema, "/api", "", negotiated, false)
+
+	addConditionalGETs(doc)
+
+	return doc
+}
+
+// addConditionalGETs documents the ETag middleware on every GET that
+// answers with a body it can hash. Streams are flushed and never tagged.
+func addConditionalGETs(doc *openapi.Document) {
+	etag := &openapi.Header{Description: "Strong validator of the response body, per format and content coding", Schema: &openapi.Schema{Type: "string"}}
+	for _, item := range doc.Paths {
+		op := item.Get
+		if op == nil || op.Responses["200"] == nil || op.Responses["200"].Content[openapi.ContentJSON] == nil {
+			continue
+		}
+		op.Parameters = append(op.Parameters, &openapi.Parameter{
+			Name: "If-None-Match", In: openapi.InHeader, Description: "ETag from a previous response", Schema: &openapi.Schema{Type: "string"},
+		})
+		ok := op.Responses["200"]
+		if ok.Headers == nil {
+			ok.Headers = make(map[string]*openapi.Header)
+		}
+		ok.Headers["ETag"] = etag
+		op.Responses["304"] = &openapi.Response{Description: "Unchanged since the ETag given", Headers: map[string]*openapi.Header{"ETag": etag}}
+	}
+}
+
+// bodies holds the success response schemas of one API version
+type bodies struct {
+	user       *openapi.Schema
+	listing    *openapi.Schema
+	listings   *openapi.Schema
+	results    *openapi.Schema
+	onboarding *openapi.Schema
+	importJob  *openapi.Schema
+}
+
+// addAPIOperations documents the user and listing routes mounted at prefix.
+// An empty suffix marks the Accept-negotiated routes.
+func addAPIOperations(doc *openapi.Document, g *openapi.Generator, errSchema *openapi.Schema, prefix, suffix string, b bodies, deprecated bool) {
+	add := func(method, path string, op *openapi.Operation) {
+		op.OperationID += suffix
+		op.Deprecated = deprecated
+		if suffix == "" {
+			op.Parameters = append(op.Parameters, acceptParam())
+			op.Responses["406"] = jsonResponse("Unsupported API version", errSchema)
+		}
+		doc.AddOperation(method, prefix+path, op)
+	}
+	// addEncoded adds an operation that also exchanges MessagePack and the
+	// Protobuf messages of package pb, named by request and response
+	addEncoded := func(method, path, request, response string, op *openapi.Operation) {
+		addEncodings(op, request, response)
+		op.OperationID += suffix
+		op.Deprecated = deprecated
+		op.Parameters = append(op.Parameters, formatParam(suffix == ""))
+		op.Responses["406"] = jsonResponse("Unsupported media type", errSchema)
+		if suffix == "" {
+			op.Responses["406"].Description = "Unsupported API version or media type"
+		}
+		doc.AddOperation(method, prefix+path, op)
+	}
+
+	addEncoded(http.MethodPost, "/users", "CreateUserRequest", "User", &openapi.Operation{
+		OperationID: "createUser",
+		Summary:     "Create a user",
+		Tags:        []string{"users"},
+		RequestBody: jsonBody(g.Schema(model.CreateUserRequest{})),
+		Responses: map[string]*openapi.Response{
+			"201": jsonResponse("User created", b.user),
+			"400": jsonResponse("Invalid request", errSchema),
+			"500": jsonResponse("Downstream failure", errSchema),
+		},
+	})
+
+	addEncoded(http.MethodPatch, "/users/{id}", "UpdateUserRequest", "User", &openapi.Operation{
+		OperationID: "updateUser",
+		Summary:     "Rename a user",
+		Tags:        []string{"users"},
+		Parameters:  []*openapi.Parameter{idParam("User ID")},
+		RequestBody: jsonBody(g.Schema(model.UpdateUserRequest{})),
+		Responses: map[string]*openapi.Response{
+			"200": jsonResponse("User updated", b.user),
+			"400": jsonResponse("Invalid request", errSchema),
+			"404": jsonResponse("User not found", errSchema),
+			"500": jsonResponse("Downstream failure", errSchema),
+		},
+	})
+
+	addEncoded(http.MethodPost, "/listings", "CreateListingRequest", "Listing", &openapi.Operation{
+		OperationID: "createListing",
+		Summary:     "Create a listing",
+		Tags:        []string{"listings"},
+		RequestBody: jsonBody(g.Schema(model.CreateListingRequest{})),
+		Responses: map[string]*openapi.Response{
+			"201": jsonResponse("Listing created", b.listing),
+			"400": jsonResponse("Invalid request", errSchema),
+			"422": jsonResponse("Owner does not exist", errSchema),
+			"500": jsonResponse("Downstream failure", errSchema),
+		},
+	})
+
+	batch := &openapi.Schema{Type: "array", Items: g.Schema(model.CreateListingRequest{}),
+		MinItems: openapi.Int(1), MaxItems: openapi.Int(model.MaxListingBatchSize),
+		ErrorCodes: map[string]string{openapi.CodeMinItems: "min", openapi.CodeMaxItems: "max"}}
+	add(http.MethodPost, "/listings/batch", &openapi.Operation{
+		OperationID: "createListings",
+		Summary:     "Create several listings",
+		Description: "Every listing is validated before any is created; field errors are reported with the item index, as in \"[2].price\". " +
+			"The response has one result per listing, in request order, with the status the listing would have had on its own.",
+		Tags:        []string{"listings"},
+		RequestBody: jsonBody(batch),
+		Responses: map[string]*openapi.Response{
+			"201": jsonResponse("All listings created", b.results),
+			"207": jsonResponse("Some listings failed", b.results),
+			"400": jsonResponse("Invalid request", errSchema),
+		},
+	})
+
+	addEncoded(http.MethodGet, "/listings", "", "GetListingsResponse", &openapi.Operation{
+		OperationID: "getListings",
+		Summary:     "List listings with their owners",
+		Description: "Listings embed their owner unless fields or include are given. Then owners are only fetched when included or when one of their fields is selected, " +
+			"and with fields each listing only has its id and the fields selected.",
+		Tags: []string{"listings"},
+		Parameters: append(listingQueryParams(),
+			&openapi.Parameter{Name: "fields", In: openapi.InQuery, Description: "Comma-separated fields to return, e.g. id,price,user.name; user selects the whole owner", Schema: &openapi.Schema{Type: "string"}},
+			&openapi.Parameter{Name: "include", In: openapi.InQuery, Description: "Comma-separated relations to embed; only user is supported, and an empty value embeds none", Schema: &openapi.Schema{Type: "string"}},
+		),
+		Responses: map[string]*openapi.Response{
+			"200": cachedResponse(jsonResponse("Listings page", b.listings)),
+			"400": jsonResponse("Invalid query", errSchema),
+			"500": jsonResponse("Downstream failure", errSchema),
+		},
+	})
+
+	add(http.MethodPost, "/listings/import", &openapi.Operation{
+		OperationID: "importListings",
+		Summary:     "Import listings from a CSV file",
+		Description: "The file needs a header row with user_id, listing_type and price columns; other columns are ignored. " +
+			"Rows are validated like createListing and invalid ones are reported by line number without failing the file. " +
+			"Valid rows are created by a background job whose progress is available at the Location returned. A dry run only validates.",
+		Tags: []string{"listings"},
+		Parameters: []*openapi.Parameter{
+			{Name: "dry_run", In: openapi.InQuery, Description: "Validate without creating listings", Schema: &openapi.Schema{Type: "boolean"}},
+		},
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content: map[string]*openapi.MediaType{openapi.ContentMultipart: {Schema: &openapi.Schema{
+				Type:       "object",
+				Required:   []string{"file"},
+				Properties: map[string]*openapi.Schema{"file": {Type: "string", Format: "binary"}},
+			}}},
+		},
+		Responses: map[string]*openapi.Response{
+			"200": jsonResponse("Dry run result", b.importJob),
+			"202": {
+				Description: "Import started",
+				Headers:     map[string]*openapi.Header{"Location": {Description: "Import status URL", Schema: &openapi.Schema{Type: "string"}}},
+				Content:     openapi.JSONContent(b.importJob),
+			},
+			"400": jsonResponse("Missing or malformed file", errSchema),
+			"413": jsonResponse("File too large", errSchema),
+			"503": jsonResponse("Too many imports in progress", errSchema),
+		},
+	})
+
+	add(http.MethodGet, "/listings/import/{id}", &openapi.Operation{
+		OperationID: "getImport",
+		Summary:     "Get the progress of a listing import",
+		Tags:        []string{"listings"},
+		Parameters:  []*openapi.Parameter{idParam("Import ID")},
+		Responses: map[string]*openapi.Response{
+			"200": jsonResponse("Import progress and row errors", b.importJob),
+			"400": jsonResponse("Invalid import id", errSchema),
+			"404": jsonResponse("Import not found", errSchema),
+		},
+	})
+
+	add(http.MethodGet, "/listings/stream", &openapi.Operation{
+		OperationID: "streamListings",
+		Summary:     "Stream newly created listings",
+		Description: "Server-Sent Events stream. Each created listing is sent as a \"listing\" event whose data is shaped like the listing detail response, " +
+			"and a \"heartbeat\" event is sent while idle. Reconnect with Last-Event-ID to replay recent events that were missed.",
+		Tags: []string{"listings"},
+		Parameters: []*openapi.Parameter{
+			{Name: "user_id", In: openapi.InQuery, Description: "Only listings owned by this user", Schema: &openapi.Schema{Type: "integer", Format: "int64"}},
+			{Name: "listing_type", In: openapi.InQuery, Description: "Only listings of this type", Schema: listingTypeSchema()},
+			{Name: "Last-Event-ID", In: openapi.InHeader, Description: "ID of the last event received", Schema: &openapi.Schema{Type: "integer", Format: "int64", Minimum: openapi.Float(0)}},
+		},
+		Responses: map[string]*openapi.Response{
+			"200": {
+				Description: "Event stream",
+				Content:     map[string]*openapi.MediaType{openapi.ContentEventStream: {Schema: &openapi.Schema{Type: "string"}}},
+			},
+			"400": jsonResponse("Invalid filter", errSchema),
+		},
+	})
+
+	add(http.MethodGet, "/listings/export", &openapi.Operation{
+		OperationID: "exportListings",
+		Summary:     "Export all matching listings with their owners",
+		Description: "Streams every listing matching the filters as a file download, page by page. " +
+			"Should the listing-service fail after the download started, the export ends early with the reason in the X-Export-Error trailer, " +
+			"and an NDJSON export also ends with an {\"error\": ...} line. A CSV export has no other sign of being incomplete. " +
+			"CSV text cells starting with =, +, -, @, tab or carriage return are prefixed with ' so spreadsheets do not run them as formulas.",
+		Tags: []string{"listings"},
+		Parameters: append([]*openapi.Parameter{
+			{Name: "format", In: openapi.InQuery, Description: "File format, csv by default", Schema: &openapi.Schema{Type: "string", Enum: []interface{}{"csv", "ndjson"}}},
+		}, listingQueryParams()[2:]...),
+		Responses: map[string]*openapi.Response{
+			"200": {
+				Description: "Listings file",
+				Headers: map[string]*openapi.Header{
+					"Content-Disposition": {Description: "Attachment with a timestamped file name", Schema: &openapi.Schema{Type: "string"}},
+					"Trailer":             {Description: "Announces the X-Export-Error trailer, sent when the export was interrupted", Schema: &openapi.Schema{Type: "string"}},
+				},
+				Content: map[string]*openapi.MediaType{
+					openapi.ContentCSV:    {Schema: &openapi.Schema{Type: "string"}},
+					openapi.ContentNDJSON: {Schema: &openapi.Schema{Type: "string"}},
+				},
+			},
+			"400": jsonResponse("Invalid format or filter", errSchema),
+			"500": jsonResponse("Downstream failure before the download started", errSchema),
+		},
+	})
+
+	addEncoded(http.MethodGet, "/listings/{id}", "", "Listing", &openapi.Operation{
+		OperationID: "getListing",
+		Summary:     "Get a listing with its owner",
+		Tags:        []string{"listings"},
+		Parameters:  []*openapi.Parameter{idParam("Listing ID")},
+		Responses: map[string]*openapi.Response{
+			"200": cachedResponse(jsonResponse("Listing with its owner", b.listing)),
+			"400": jsonResponse("Invalid listing id", errSchema),
+			"404": jsonResponse("Listing not found", errSchema),
+			"500": jsonResponse("Downstream failure", errSchema),
+		},
+	})
+
+	add(http.MethodPost, "/onboarding", &openapi.Operation{
+		OperationID: "onboard",
+		Summary:     "Create a user with their first listing",
+		Description: "Creates the user, then the listing owned by them. Both are validated first. " +
+			"If the listing cannot be created the user is kept and returned with failed_step \"listing\", so the listing can be created on its own.",
+		Tags:        []string{"onboarding"},
+		RequestBody: jsonBody(g.Schema(model.OnboardingRequest{})),
+		Responses: map[string]*openapi.Response{
+			"201": jsonResponse("User and listing created", b.onboarding),
+			"400": jsonResponse("Invalid request", errSchema),
+			"422": jsonResponse("User created, listing rejected", g.Schema(model.OnboardingErrorResponse{})),
+			"500": jsonResponse("Downstream failure; failed_step and user are included when the user was created", errSchema),
+		},
+	})
+}
+
+// addAdminOperations documents the webhook admin routes
+func addAdminOperations(doc *openapi.Document, g *openapi.Generator, errSchema *openapi.Schema) {
+	add := func(method, path string, op *openapi.Operation) {
+		op.Tags = []string{"admin"}
+		op.Parameters = append([]*openapi.Parameter{{
+			Name:        "Authorization",
+			In:          openapi.InHeader,
+			Description: "Bearer admin token",
+			Schema:      &openapi.Schema{Type: "string"},
+		}}, op.Parameters...)
+		op.Responses["401"] = jsonResponse("Missing or invalid admin token", errSchema)
+		doc.AddOperation(method, "/admin"+path, op)
+	}
+	webhook := envelope("webhook", g.Schema(model.Webhook{}))
+
+	add(http.MethodPost, "/webhooks", &openapi.Operation{
+		OperationID: "createWebhook",
+		Summary:     "Subscribe a webhook to events",
+		Description: "The response is the only one to include the signing secret, which is generated when omitted.",
+		RequestBody: jsonBody(g.Schema(model.CreateWebhookRequest{})),
+		Responses: map[string]*openapi.Response{
+			"201": jsonResponse("Created webhook", webhook),
+			"400": jsonResponse("Invalid request", errSchema),
+		},
+	})
+	add(http.MethodGet, "/webhooks", &openapi.Operation{
+		OperationID: "listWebhooks",
+		Summary:     "List webhooks",
+		Responses: map[string]*openapi.Response{
+			"200": jsonResponse("Webhooks", envelope("webhooks", &openapi.Schema{Type: "array", Items: g.Schema(model.Webhook{})})),
+		},
+	})
+	add(http.MethodGet, "/webhooks/{id}", &openapi.Operation{
+		OperationID: "getWebhook",
+		Summary:     "Get a webhook",
+		Parameters:  []*openapi.Parameter{idParam("Webhook ID")},
+		Responses: map[string]*openapi.Response{
+			"200": jsonResponse("Webhook", webhook),
+			"400": jsonResponse("Invalid webhook id", errSchema),
+			"404": jsonResponse("Webhook not found", errSchema),
+		},
+	})
+	add(http.MethodDelete, "/webhooks/{id}", &openapi.Operation{
+		OperationID: "deleteWebhook",
+		Summary:     "Delete a webhook",
+		Parameters:  []*openapi.Parameter{idParam("Webhook ID")},
+		Responses: map[string]*openapi.Response{
+			"204": {Description: "Webhook deleted"},
+			"400": jsonResponse("Invalid webhook id", errSchema),
+			"404": jsonResponse("Webhook not found", errSchema),
+		},
+	})
+	add(http.MethodGet, "/webhooks/{id}/deliveries", &openapi.Operation{
+		OperationID: "listWebhookDeliveries",
+		Summary:     "Recent delivery attempts to a webhook, newest first",
+		Parameters:  []*openapi.Parameter{idParam("Webhook ID")},
+		Responses: map[string]*openapi.Response{
+			"200": jsonResponse("Delivery attempts", envelope("deliveries", &openapi.Schema{Type: "array", Items: g.Schema(model.WebhookDelivery{})})),
+			"400": jsonResponse("Invalid webhook id", errSchema),
+			"404": jsonResponse("Webhook not found", errSchema),
+		},
+	})
+	add(http.MethodGet, "/dead-letters", &openapi.Operation{
+		OperationID: "listDeadLetters",
+		Summary:     "Events that could not be delivered after every retry",
+		Responses: map[string]*openapi.Response{
+			"200": jsonResponse("Dead letters", envelope("dead_letters", &openapi.Schema{Type: "array", Items: g.Schema(model.WebhookDeadLetter{})})),
+		},
+	})
+	add(http.MethodPost, "/dead-letters/{id}/redeliver", &openapi.Operation{
+		OperationID: "redeliverDeadLetter",
+		Summary:     "Retry delivering a dead letter",
+		Parameters:  []*openapi.Parameter{idParam("Dead letter ID")},
+		Responses: map[string]*openapi.Response{
+			"202": {Description: "Event queued for delivery"},
+			"400": jsonResponse("Invalid dead letter id", errSchema),
+			"404": jsonResponse("Dead letter not found", errSchema),
+			"422": jsonResponse("Webhook no longer exists", errSchema),
+		},
+	})
+}
+
+// registerDocs serves the OpenAPI document and the Swagger UI page
+func registerDocs(r *gin.Engine, doc *openapi.Document) {
+	spec, err := json.Marshal(doc)
+	if err != nil {
+		panic("router: failed to marshal OpenAPI document: " + err.Error())
+	}
+
+	r.GET("/openapi.json", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json; charset=utf-8", spec)
+	})
+	r.GET("/docs", func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", swaggerHTML)
+	})
+}
+
+func listingQueryParams() []*openapi.Parameter {
+	integer := func(min float64) *openapi.Schema {
+		return &openapi.Schema{Type: "integer", Format: "int64", Minimum: openapi.Float(min)}
+	}
+	number := &openapi.Schema{Type: "number", Format: "double", Minimum: openapi.Float(0)}
+
+	return []*openapi.Parameter{
+		{Name: "page_num", In: openapi.InQuery, Description: "Page number, starting at 1", Schema: integer(1)},
+		{Name: "page_size", In: openapi.InQuery, Description: "Items per page", Schema: &openapi.Schema{
+			Type: "integer", Format: "int64", Minimum: openapi.Float(1), Maximum: openapi.Float(model.MaxListingPageSize),
+		}},
+		{Name: "user_id", In: openapi.InQuery, Description: "Only listings owned by this user", Schema: &openapi.Schema{Type: "integer", Format: "int64"}},
+		{Name: "listing_type", In: openapi.InQuery, Description: "Only listings of this type", Schema: listingTypeSchema()},
+		{Name: "min_price", In: openapi.InQuery, Description: "Inclusive lower price bound", Schema: number},
+		{Name: "max_price", In: openapi.InQuery, Description: "Inclusive upper price bound", Schema: number},
+		{Name: "created_from", In: openapi.InQuery, Description: "Inclusive lower created_at bound", Schema: &openapi.Schema{Type: "integer", Format: "int64"}},
+		{Name: "created_to", In: openapi.InQuery, Description: "Inclusive upper created_at bound", Schema: &openapi.Schema{Type: "integer", Format: "int64"}},
+		{Name: "sort_by", In: openapi.InQuery, Description: "Sort field", Schema: &openapi.Schema{Type: "string", Enum: []interface{}{model.ListingSortPrice, model.ListingSortCreatedAt}}},
+		{Name: "sort_order", In: openapi.InQuery, Description: "Sort direction", Schema: &openapi.Schema{Type: "string", Enum: []interface{}{model.SortAsc, model.SortDesc}}},
+	}
+}
+
+// sparseListings documents the listings of getListings. Sparse fieldsets
+// may leave out every field but the ids.
+func sparseListings(doc *openapi.Document, g *openapi.Generator) *openapi.Schema {
+	sparse := func(name string, v interface{}, props map[string]*openapi.Schema) *openapi.Schema {
+		s := *doc.Resolve(g.Schema(v))
+		s.Properties = maps.Clone(s.Properties)
+		maps.Copy(s.Properties, props)
+		s.Required = []string{"id"}
+		doc.Components.Schemas[name] = &s
+		return &openapi.Schema{Ref: "#/components/schemas/" + name}
+	}
+	user := sparse("SparseUser", model.User{}, nil)
+	return &openapi.Schema{Type: "array", Items: sparse("SparseListing", model.Listing{}, map[string]*openapi.Schema{"user": user})}
+}
+
+func listingTypeSchema() *openapi.Schema {
+	types := make([]interface{}, len(model.ListingTypes))
+	for i, t := range model.ListingTypes {
+		types[i] = string(t)
+	}
+	return &openapi.Schema{Type: "string", Enum: types}
+}
+
+func acceptParam() *openapi.Parameter {
+	return &openapi.Parameter{
+		Name:        "Accept",
+		In:          openapi.InHeader,
+		Description: "Selects the API version, e.g. " + apiversion.MediaTypePrefix + "v1+json. Defaults to the latest version.",
+		Schema:      &openapi.Schema{Type: "string"},
+	}
+}
+
+// formatParam documents the Accept header of operations served in several
+// formats. On negotiated routes it selects the API version as well.
+func formatParam(versioned bool) *openapi.Parameter {
+	p := &openapi.Parameter{
+		Name:        "Accept",
+		In:          openapi.InHeader,
+		Description: "Selects the format: " + content.JSON + " (default), " + content.MsgPack + " or " + content.Protobuf + ". Error responses are always JSON.",
+		Schema:      &openapi.Schema{Type: "string"},
+	}
+	if versioned {
+		p.Description = acceptParam().Description + " " + p.Description + " Formats combine with versions, e.g. " + apiversion.MediaTypePrefix + "v1+msgpack."
+	}
+	return p
+}
+
+// addEncodings documents the MessagePack and Protobuf forms of the request
+// body and successful responses of op. MessagePack has the shape of the
+// JSON; Protobuf bodies are the named messages of package pb, or none when
+// the name is empty.
+func addEncodings(op *openapi.Operation, request, response string) {
+	message := func(name string) *openapi.MediaType {
+		return &openapi.MediaType{Schema: &openapi.Schema{Type: "string", Format: "binary", Description: "publicapi.v1." + name + " message"}}
+	}
+	if op.RequestBody != nil && request != "" {
+		op.RequestBody.Content[content.MsgPack] = op.RequestBody.Content[openapi.ContentJSON]
+		op.RequestBody.Content[content.Protobuf] = message(request)
+	}
+	for code, r := range op.Responses {
+		media, ok := r.Content[openapi.ContentJSON]
+		if !ok || code[0] != '2' {
+			continue
+		}
+		r.Content[content.MsgPack] = media
+		r.Content[content.Protobuf] = message(response)
+	}
+}
+
+func idParam(desc string) *openapi.Parameter {
+	return &openapi.Parameter{
+		Name:        "id",
+		In:          openapi.InPath,
+		Description: desc,
+		Required:    true,
+		Schema:      &openapi.Schema{Type: "integer", Format: "int64", Minimum: openapi.Float(1)},
+	}
+}
+
+func envelope(key string, schema *openapi.Schema) *openapi.Schema {
+	return &openapi.Schema{
+		Type:       "object",
+		Properties: map[string]*openapi.Schema{key: schema},
+		Required:   []string{key},
+	}
+}
+
+func jsonBody(schema *openapi.Schema) *openapi.RequestBody {
+	return &openapi.RequestBody{Required: true, Content: openapi.JSONContent(schema)}
+}
+
+// cachedResponse documents the headers of responses the response cache may
+// serve
+func cachedResponse(r *openapi.Response) *openapi.Response {
+	str := &openapi.Schema{Type: "string"}
+	r.Headers = map[string]*openapi.Header{
+		"Cache-Control": {Description: "Freshness for clients and CDNs, when response caching is enabled", Schema: str},
+		"Age":           {Description: "Seconds since a cached response was built", Schema: &openapi.Schema{Type: "integer"}},
+		"X-Cache":       {Description: "HIT, STALE or MISS", Schema: str},
+	}
+	return r
+}
+
+func jsonResponse(desc string, schema *openapi.Schema) *openapi.Response {
+	return &openapi.Response{Description: desc, Content: openapi.JSONContent(schema)}
+}
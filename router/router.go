@@ -2,32 +2,180 @@ package router
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"public-api/apiversion"
+	"public-api/content"
 	"public-api/handler"
+	"public-api/middleware"
 )
 
+// options holds optional router behaviour
+type options struct {
+	validateResponses bool
+	v1DeprecatedAt    time.Time
+	v1SunsetAt        time.Time
+	adminToken        string
+	compressMinSize   int
+	cache             *middleware.ResponseCache
+	cachePolicies     map[string]middleware.CachePolicy
+}
+
+// Option customizes SetupRouter
+type Option func(*options)
+
+// WithResponseValidation checks every JSON response against the OpenAPI
+// contract and turns violations into 500s. Meant for debugging and tests.
+func WithResponseValidation(enabled bool) Option {
+	return func(o *options) {
+		o.validateResponses = enabled
+	}
+}
+
+// WithV1Sunset adds Deprecation and Sunset headers to responses served as v1.
+// Zero times leave the matching header out; v1 is not marked when both are.
+func WithV1Sunset(deprecatedAt, sunsetAt time.Time) Option {
+	return func(o *options) {
+		o.v1DeprecatedAt = deprecatedAt
+		o.v1SunsetAt = sunsetAt
+	}
+}
+
+// WithAdminToken sets the bearer token required by the /admin routes. They
+// reject every request without one.
+func WithAdminToken(token string) Option {
+	return func(o *options) {
+		o.adminToken = token
+	}
+}
+
+// WithCompression compresses responses of at least minSize bytes with the
+// best coding the client accepts. Zero leaves responses uncompressed.
+func WithCompression(minSize int) Option {
+	return func(o *options) {
+		o.compressMinSize = minSize
+	}
+}
+
+// WithResponseCache caches the GET routes that have a policy in rc. Policies
+// are keyed by route path below the API prefix, like "/listings/:id", and
+// apply to every version. Policies without a TTL are ignored.
+func WithResponseCache(rc *middleware.ResponseCache, policies map[string]middleware.CachePolicy) Option {
+	return func(o *options) {
+		o.cache = rc
+		o.cachePolicies = policies
+	}
+}
+
+// cached returns the cache middleware of the route at path, if any
+func (o options) cached(path string) []gin.HandlerFunc {
+	p, ok := o.cachePolicies[path]
+	if o.cache == nil || !ok || p.TTL <= 0 {
+		return nil
+	}
+	return []gin.HandlerFunc{o.cache.Cache(p)}
+}
+
+// Handlers are the handlers SetupRouter mounts. Every route is registered
+// whether or not its handler is set, so tests may leave out the ones they
+// do not call.
+type Handlers struct {
+	User         *handler.UserHandler
+	Listing      *handler.ListingHandler
+	GraphQL      *handler.GraphQLHandler
+	Stream       *handler.StreamHandler
+	Notification *handler.NotificationHandler
+	Webhook      *handler.WebhookHandler
+	Onboarding   *handler.OnboardingHandler
+	Import       *handler.ImportHandler
+}
+
 // SetupRouter initializes all routes and handlers
-func SetupRouter(
-	userHandler *handler.UserHandler,
-	listingHandler *handler.ListingHandler,
-) *gin.Engine {
-	r := gin.Default()
+func SetupRouter(h Handlers, opts ...Option) *gin.Engine {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	spec := APISpec()
+
+	// Like gin.Default, but access tokens are kept out of the logs
+	r := gin.New()
+	r.Use(middleware.Logger(), gin.Recovery())
+	if o.compressMinSize > 0 {
+		r.Use(middleware.Compress(o.compressMinSize))
+	}
+	r.Use(middleware.ETag())
+	r.Use(middleware.OpenAPIValidator(spec, o.validateResponses))
 
 	// Health check
 	r.GET("/ping", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"message": "pong"})
 	})
 
-	api := r.Group("/api/v1")
-	{
-		// User routes
-		api.POST("/users", userHandler.CreateUser)
-
-		// Listing routes
-		api.POST("/listings", listingHandler.CreateListing)
-		api.GET("/listings", listingHandler.GetListings)
+	var sunset []gin.HandlerFunc
+	if !o.v1DeprecatedAt.IsZero() || !o.v1SunsetAt.IsZero() {
+		sunset = append(sunset, apiversion.Deprecate(apiversion.V1, o.v1DeprecatedAt, o.v1SunsetAt, "/api/v2"))
 	}
 
+	// Versioned by path
+	v1 := r.Group("/api/v1", append([]gin.HandlerFunc{apiversion.Fixed(apiversion.V1)}, sunset...)...)
+	registerAPI(v1, o, h)
+
+	v2 := r.Group("/api/v2", apiversion.Fixed(apiversion.V2))
+	registerAPI(v2, o, h)
+
+	// Versioned by Accept header, defaulting to the latest version
+	negotiated := r.Group("/api", append([]gin.HandlerFunc{apiversion.Negotiate()}, sunset...)...)
+	registerAPI(negotiated, o, h)
+
+	// GraphQL
+	r.POST("/graphql", h.GraphQL.Query)
+
+	// WebSocket notifications
+	r.GET("/ws", h.Notification.Connect)
+
+	// Admin
+	admin := r.Group("/admin", middleware.RequireAdminToken(o.adminToken))
+	admin.POST("/webhooks", h.Webhook.CreateWebhook)
+	admin.GET("/webhooks", h.Webhook.ListWebhooks)
+	admin.GET("/webhooks/:id", h.Webhook.GetWebhook)
+	admin.DELETE("/webhooks/:id", h.Webhook.DeleteWebhook)
+	admin.GET("/webhooks/:id/deliveries", h.Webhook.ListDeliveries)
+	admin.GET("/dead-letters", h.Webhook.ListDeadLetters)
+	admin.POST("/dead-letters/:id/redeliver", h.Webhook.RedeliverDeadLetter)
+
+	// API documentation
+	registerDocs(r, spec)
+
+	if o.cache != nil {
+		o.cache.RevalidateWith(r)
+	}
 	return r
 }
+
+// registerAPI mounts the user and listing routes on a versioned group. Every
+// version shares the same handlers; response shapes differ per version.
+// Cached routes are served from the cache before formats are negotiated.
+func registerAPI(api *gin.RouterGroup, o options, h Handlers) {
+	// Users and listings can also be exchanged as MessagePack or Protobuf
+	formats := content.Negotiate(content.JSON, content.MsgPack, content.Protobuf)
+
+	// User routes
+	api.POST("/users", formats, h.User.CreateUser)
+	api.PATCH("/users/:id", formats, h.User.UpdateUser)
+
+	// Listing routes
+	api.POST("/listings", formats, h.Listing.CreateListing)
+	api.POST("/listings/batch", h.Listing.CreateListings)
+	api.GET("/listings", append(o.cached("/listings"), formats, h.Listing.GetListings)...)
+	api.GET("/listings/stream", h.Stream.StreamListings)
+	api.GET("/listings/export", h.Listing.ExportListings)
+	api.POST("/listings/import", h.Import.ImportListings)
+	api.GET("/listings/import/:id", h.Import.GetImport)
+	api.GET("/listings/:id", append(o.cached("/listings/:id"), formats, h.Listing.GetListingByID)...)
+
+	// Onboarding
+	api.POST("/onboarding", h.Onboarding.Onboard)
+}
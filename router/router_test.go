@@ -0,0 +1,799 @@
+package router_test
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"public-api/apiversion"
+	"public-api/auth"
+	"public-api/graph"
+	"public-api/handler"
+	"public-api/importer"
+	"public-api/middleware"
+	"public-api/mocks"
+	"public-api/model"
+	"public-api/notify"
+	"public-api/router"
+	"public-api/service"
+	"public-api/stream"
+	"public-api/webhook"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ugorji/go/codec"
+)
+
+var ginParam = regexp.MustCompile(`:([^/]+)`)
+
+func TestOpenAPISpecCoversAllRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := router.SetupRouter(router.Handlers{})
+
+	req := httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+	require.Equal(t, http.StatusOK, resp.Code)
+
+	var spec struct {
+		OpenAPI string                                `json:"openapi"`
+		Paths   map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &spec))
+	assert.True(t, strings.HasPrefix(spec.OpenAPI, "3."))
+
+	for _, route := range r.Routes() {
+		path := ginParam.ReplaceAllString(route.Path, "{$1}")
+		ops, ok := spec.Paths[path]
+		if !assert.Truef(t, ok, "path %s missing from OpenAPI spec", path) {
+			continue
+		}
+		_, ok = ops[strings.ToLower(route.Method)]
+		assert.Truef(t, ok, "%s %s missing from OpenAPI spec", route.Method, path)
+	}
+}
+
+func TestSwaggerUI(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := router.SetupRouter(router.Handlers{})
+
+	req := httptest.NewRequest(http.MethodGet, "/docs", nil)
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Header().Get("Content-Type"), "text/html")
+	assert.Contains(t, resp.Body.String(), "/openapi.json")
+	// Assets are pinned to an exact, immutable release
+	assert.NotRegexp(t, `swagger-ui-dist@\d+/`, resp.Body.String())
+}
+
+// TestResponsesMatchContract runs every route with response validation on so
+// handler output that drifts from the OpenAPI spec fails here
+func TestResponsesMatchContract(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	listing := &model.Listing{ID: 1, UserID: 2, ListingType: "rent", Price: 100, CreatedAt: 1, UpdatedAt: 1,
+		User: &model.User{ID: 2, Name: "John", CreatedAt: 1, UpdatedAt: 1}}
+
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		body         string
+		mock         func(us *mocks.MockUserService, ls *mocks.MockListingService)
+		expectedCode int
+	}{
+		{
+			name:         "ping",
+			method:       http.MethodGet,
+			path:         "/ping",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:   "create user",
+			method: http.MethodPost,
+			path:   "/api/v1/users",
+			body:   `{"name":"John"}`,
+			mock: func(us *mocks.MockUserService, ls *mocks.MockListingService) {
+				us.EXPECT().CreateUser(gomock.Any(), "John").Return(&model.User{ID: 2, Name: "John"}, nil)
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "create user with invalid body",
+			method:       http.MethodPost,
+			path:         "/api/v1/users",
+			body:         `{"name":""}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:   "update user not found",
+			method: http.MethodPatch,
+			path:   "/api/v1/users/2",
+			body:   `{"name":"Jane"}`,
+			mock: func(us *mocks.MockUserService, ls *mocks.MockListingService) {
+				us.EXPECT().UpdateUser(gomock.Any(), int64(2), "Jane").Return(nil, model.ErrNotFound)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:   "create listing",
+			method: http.MethodPost,
+			path:   "/api/v1/listings",
+			body:   `{"user_id":2,"listing_type":"rent","price":100}`,
+			mock: func(us *mocks.MockUserService, ls *mocks.MockListingService) {
+				ls.EXPECT().CreateListing(gomock.Any(), gomock.Any()).Return(listing, nil)
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:   "create listing for unknown owner",
+			method: http.MethodPost,
+			path:   "/api/v1/listings",
+			body:   `{"user_id":3,"listing_type":"rent","price":100}`,
+			mock: func(us *mocks.MockUserService, ls *mocks.MockListingService) {
+				ls.EXPECT().CreateListing(gomock.Any(), gomock.Any()).Return(nil, model.ErrUnprocessable)
+			},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "create listings",
+			method: http.MethodPost,
+			path:   "/api/v1/listings/batch",
+			body:   `[{"user_id":2,"listing_type":"rent","price":100},{"user_id":3,"listing_type":"sale","price":100}]`,
+			mock: func(us *mocks.MockUserService, ls *mocks.MockListingService) {
+				ls.EXPECT().CreateListings(gomock.Any(), gomock.Any()).
+					Return([]*model.Listing{listing, nil}, []error{nil, model.ErrUnprocessable}, nil)
+			},
+			expectedCode: http.StatusMultiStatus,
+		},
+		{
+			name:         "create listings with invalid item",
+			method:       http.MethodPost,
+			path:         "/api/v1/listings/batch",
+			body:         `[{"user_id":2,"listing_type":"rent","price":-1}]`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:   "get listings",
+			method: http.MethodGet,
+			path:   "/api/v1/listings?page_num=1&page_size=10&listing_type=rent",
+			mock: func(us *mocks.MockUserService, ls *mocks.MockListingService) {
+				ls.EXPECT().GetListings(gomock.Any(), gomock.Any()).Return([]model.Listing{*listing}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:   "get sparse listings",
+			method: http.MethodGet,
+			path:   "/api/v1/listings?fields=price,user.name",
+			mock: func(us *mocks.MockUserService, ls *mocks.MockListingService) {
+				ls.EXPECT().GetListings(gomock.Any(), gomock.Any()).Return([]model.Listing{*listing}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:   "get listings without owners",
+			method: http.MethodGet,
+			path:   "/api/v1/listings?include=",
+			mock: func(us *mocks.MockUserService, ls *mocks.MockListingService) {
+				ls.EXPECT().FindListings(gomock.Any(), gomock.Any()).Return([]model.Listing{{ID: 1, UserID: 2, ListingType: "rent", Price: 100}}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "get listings with unknown field",
+			method:       http.MethodGet,
+			path:         "/api/v1/listings?fields=colour",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "get listings with invalid query",
+			method:       http.MethodGet,
+			path:         "/api/v1/listings?page_num=0",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "stream listings with invalid filter",
+			method:       http.MethodGet,
+			path:         "/api/v1/listings/stream?listing_type=castle",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:   "get listing",
+			method: http.MethodGet,
+			path:   "/api/v1/listings/1",
+			mock: func(us *mocks.MockUserService, ls *mocks.MockListingService) {
+				ls.EXPECT().GetListingByID(gomock.Any(), int64(1)).Return(listing, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:   "get listing downstream failure",
+			method: http.MethodGet,
+			path:   "/api/v1/listings/1",
+			mock: func(us *mocks.MockUserService, ls *mocks.MockListingService) {
+				ls.EXPECT().GetListingByID(gomock.Any(), int64(1)).Return(nil, errors.New("boom"))
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+		{
+			name:   "graphql query",
+			method: http.MethodPost,
+			path:   "/graphql",
+			body:   `{"query":"{ listings(pageSize: 5) { id price user { name } } }"}`,
+			mock: func(us *mocks.MockUserService, ls *mocks.MockListingService) {
+				ls.EXPECT().FindListings(gomock.Any(), gomock.Any()).Return([]model.Listing{{ID: 1, UserID: 2}}, nil)
+				us.EXPECT().GetUsersByIDs(gomock.Any(), []int64{2}).Return(map[int64]*model.User{2: listing.User}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "graphql query error",
+			method:       http.MethodPost,
+			path:         "/graphql",
+			body:         `{"query":"{ nope }"}`,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "graphql without query",
+			method:       http.MethodPost,
+			path:         "/graphql",
+			body:         `{}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:   "onboarding",
+			method: http.MethodPost,
+			path:   "/api/v1/onboarding",
+			body:   `{"name":"John","listing":{"listing_type":"rent","price":100}}`,
+			mock: func(us *mocks.MockUserService, ls *mocks.MockListingService) {
+				us.EXPECT().CreateUser(gomock.Any(), "John").Return(listing.User, nil)
+				ls.EXPECT().CreateListing(gomock.Any(), gomock.Any()).Return(listing, nil)
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:   "onboarding with listing rejected",
+			method: http.MethodPost,
+			path:   "/api/v1/onboarding",
+			body:   `{"name":"John","listing":{"listing_type":"rent","price":100}}`,
+			mock: func(us *mocks.MockUserService, ls *mocks.MockListingService) {
+				us.EXPECT().CreateUser(gomock.Any(), "John").Return(listing.User, nil)
+				ls.EXPECT().CreateListing(gomock.Any(), gomock.Any()).Return(nil, model.ErrUnprocessable)
+			},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "onboarding downstream failure",
+			method: http.MethodPost,
+			path:   "/api/v1/onboarding",
+			body:   `{"name":"John","listing":{"listing_type":"rent","price":100}}`,
+			mock: func(us *mocks.MockUserService, ls *mocks.MockListingService) {
+				us.EXPECT().CreateUser(gomock.Any(), "John").Return(listing.User, nil)
+				ls.EXPECT().CreateListing(gomock.Any(), gomock.Any()).Return(nil, errors.New("boom"))
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+		{
+			name:         "onboarding without listing",
+			method:       http.MethodPost,
+			path:         "/api/v1/onboarding",
+			body:         `{"name":"John"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "notifications without token",
+			method:       http.MethodGet,
+			path:         "/ws",
+			expectedCode: http.StatusUnauthorized,
+		},
+	}
+
+	// Each case runs against every way of selecting a version
+	variants := []struct {
+		name   string
+		prefix string
+		accept string
+	}{
+		{name: "v1 path", prefix: "/api/v1"},
+		{name: "v2 path", prefix: "/api/v2"},
+		{name: "v1 accept", prefix: "/api", accept: "application/vnd.public-api.v1+json"},
+		{name: "default accept", prefix: "/api"},
+	}
+
+	for _, v := range variants {
+		for _, tt := range tests {
+			t.Run(v.name+"/"+tt.name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+				us := mocks.NewMockUserService(ctrl)
+				ls := mocks.NewMockListingService(ctrl)
+				if tt.mock != nil {
+					tt.mock(us, ls)
+				}
+
+				executor, err := graph.NewExecutor(us, ls)
+				require.NoError(t, err)
+
+				r := router.SetupRouter(router.Handlers{
+					User:         handler.NewUserHandler(us),
+					Listing:      handler.NewListingHandler(ls),
+					GraphQL:      handler.NewGraphQLHandler(executor),
+					Stream:       handler.NewStreamHandler(stream.NewHub(10, 10), time.Minute),
+					Notification: handler.NewNotificationHandler(notify.NewHub(notify.Limits{}), auth.NewTokenAuthenticator("secret"), time.Minute),
+					Webhook:      handler.NewWebhookHandler(webhook.NewDispatcher(webhook.DefaultConfig())),
+					Onboarding:   handler.NewOnboardingHandler(service.NewOnboardingService(us, ls)),
+					Import:       handler.NewImportHandler(importer.New(ls), 1<<20),
+				}, router.WithResponseValidation(true))
+
+				path := tt.path
+				if strings.HasPrefix(path, "/api/v1") {
+					path = v.prefix + strings.TrimPrefix(path, "/api/v1")
+				}
+				req := httptest.NewRequest(tt.method, path, bytes.NewBufferString(tt.body))
+				req.Header.Set("Content-Type", "application/json")
+				if v.accept != "" {
+					req.Header.Set("Accept", v.accept)
+				}
+				resp := httptest.NewRecorder()
+				r.ServeHTTP(resp, req)
+
+				assert.Equal(t, tt.expectedCode, resp.Code)
+				assert.NotContains(t, resp.Body.String(), "violates API contract")
+			})
+		}
+	}
+}
+
+func TestEncodingsMatchContract(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	us := mocks.NewMockUserService(ctrl)
+	ls := mocks.NewMockListingService(ctrl)
+	r := router.SetupRouter(router.Handlers{
+		User:    handler.NewUserHandler(us),
+		Listing: handler.NewListingHandler(ls),
+	}, router.WithResponseValidation(true))
+
+	var msgpackBody []byte
+	require.NoError(t, codec.NewEncoderBytes(&msgpackBody, new(codec.MsgpackHandle)).Encode(map[string]string{"name": "John"}))
+	us.EXPECT().CreateUser(gomock.Any(), "John").Return(&model.User{ID: 2, Name: "John"}, nil)
+	ls.EXPECT().GetListingByID(gomock.Any(), int64(1)).Return(&model.Listing{ID: 1, ListingType: "rent"}, nil)
+
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		body         []byte
+		contentType  string
+		accept       string
+		expectedCode int
+		expectedType string
+	}{
+		{name: "msgpack", method: http.MethodPost, path: "/api/v1/users", body: msgpackBody, contentType: "application/msgpack", accept: "application/msgpack",
+			expectedCode: http.StatusCreated, expectedType: "application/msgpack"},
+		{name: "versioned protobuf", method: http.MethodGet, path: "/api/listings/1", accept: apiversion.MediaTypePrefix + "v1+protobuf",
+			expectedCode: http.StatusOK, expectedType: "application/x-protobuf"},
+		{name: "invalid msgpack body", method: http.MethodPost, path: "/api/v2/users", body: []byte{0x80}, contentType: "application/msgpack",
+			expectedCode: http.StatusBadRequest, expectedType: "application/json; charset=utf-8"},
+		{name: "not acceptable", method: http.MethodGet, path: "/api/v2/listings", accept: "application/xml",
+			expectedCode: http.StatusNotAcceptable, expectedType: "application/json; charset=utf-8"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, bytes.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			req.Header.Set("Accept", tt.accept)
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedCode, resp.Code, resp.Body.String())
+			assert.Equal(t, tt.expectedType, resp.Header().Get("Content-Type"))
+		})
+	}
+}
+
+func TestCompressedConditionalGET(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ls := mocks.NewMockListingService(ctrl)
+	r := router.SetupRouter(router.Handlers{
+		Listing: handler.NewListingHandler(ls),
+	}, router.WithResponseValidation(true), router.WithCompression(1))
+
+	listings := []model.Listing{{ID: 1, UserID: 2, ListingType: "rent", Price: 100, User: &model.User{ID: 2, Name: "John"}}}
+	ls.EXPECT().GetListings(gomock.Any(), gomock.Any()).Return(listings, nil).Times(2)
+
+	get := func(match string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v2/listings", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		req.Header.Set("If-None-Match", match)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+		return resp
+	}
+
+	resp := get("")
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "gzip", resp.Header().Get("Content-Encoding"))
+	gz, err := gzip.NewReader(resp.Body)
+	require.NoError(t, err)
+	var page model.ListingPage
+	require.NoError(t, json.NewDecoder(gz).Decode(&page))
+	assert.Equal(t, listings, page.Data)
+
+	etag := resp.Header().Get("ETag")
+	assert.Regexp(t, `-gzip"$`, etag)
+	resp = get(etag)
+	assert.Equal(t, http.StatusNotModified, resp.Code)
+	assert.Equal(t, etag, resp.Header().Get("ETag"))
+}
+
+func TestCachedListingRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ls := mocks.NewMockListingService(ctrl)
+	rc := middleware.NewResponseCache(10)
+	r := router.SetupRouter(router.Handlers{
+		Listing: handler.NewListingHandler(ls),
+	}, router.WithResponseValidation(true), router.WithCompression(1), router.WithResponseCache(rc, map[string]middleware.CachePolicy{
+		"/listings/:id": {TTL: time.Minute, Tags: []string{"listings"}},
+	}))
+
+	listing := &model.Listing{ID: 1, UserID: 2, ListingType: "rent", Price: 100}
+	ls.EXPECT().GetListingByID(gomock.Any(), int64(1)).Return(listing, nil).Times(2)
+
+	get := func(accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/listings/1", nil)
+		req.Header.Set("Accept", accept)
+		req.Header.Set("Accept-Encoding", "gzip")
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+		return resp
+	}
+
+	first := get(apiversion.MediaTypePrefix + "v1+json")
+	require.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, "MISS", first.Header().Get("X-Cache"))
+	assert.Equal(t, "public, max-age=60", first.Header().Get("Cache-Control"))
+
+	hit := get(apiversion.MediaTypePrefix + "v1+json")
+	require.Equal(t, http.StatusOK, hit.Code)
+	assert.Equal(t, "HIT", hit.Header().Get("X-Cache"))
+	assert.Equal(t, "gzip", hit.Header().Get("Content-Encoding"))
+	assert.Equal(t, first.Header().Get("ETag"), hit.Header().Get("ETag"))
+	assert.Equal(t, first.Header().Values("Vary"), hit.Header().Values("Vary"))
+
+	rc.Invalidate("listings")
+	assert.Equal(t, "MISS", get(apiversion.MediaTypePrefix+"v1+json").Header().Get("X-Cache"))
+}
+
+func TestVersioning(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name            string
+		path            string
+		accept          string
+		expectedCode    int
+		expectedBody    string
+		wantDeprecation bool
+	}{
+		{name: "v1 path", path: "/api/v1/listings", expectedCode: http.StatusOK, expectedBody: `{"listings":[`, wantDeprecation: true},
+		{name: "v2 path", path: "/api/v2/listings", expectedCode: http.StatusOK, expectedBody: `{"data":[`},
+		{name: "accept v1", path: "/api/listings", accept: "application/vnd.public-api.v1+json", expectedCode: http.StatusOK, expectedBody: `{"listings":[`, wantDeprecation: true},
+		{name: "accept default", path: "/api/listings", expectedCode: http.StatusOK, expectedBody: `{"data":[`},
+		{name: "accept unsupported", path: "/api/listings", accept: "application/vnd.public-api.v7+json", expectedCode: http.StatusNotAcceptable, expectedBody: `Unsupported API version`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			ls := mocks.NewMockListingService(ctrl)
+			ls.EXPECT().GetListings(gomock.Any(), gomock.Any()).Return([]model.Listing{{ID: 1}}, nil).AnyTimes()
+
+			r := router.SetupRouter(router.Handlers{
+				Listing: handler.NewListingHandler(ls),
+			}, router.WithV1Sunset(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)))
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedCode, resp.Code)
+			assert.Contains(t, resp.Body.String(), tt.expectedBody)
+			if tt.wantDeprecation {
+				assert.NotEmpty(t, resp.Header().Get("Deprecation"))
+				assert.Equal(t, "Wed, 01 Jul 2026 00:00:00 GMT", resp.Header().Get("Sunset"))
+			} else {
+				assert.Empty(t, resp.Header().Get("Deprecation"))
+			}
+		})
+	}
+}
+
+func TestVersioningWithoutSunset(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ls := mocks.NewMockListingService(ctrl)
+	ls.EXPECT().GetListings(gomock.Any(), gomock.Any()).Return([]model.Listing{{ID: 1}}, nil)
+
+	r := router.SetupRouter(router.Handlers{Listing: handler.NewListingHandler(ls)}, router.WithV1Sunset(time.Time{}, time.Time{}))
+
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/api/v1/listings", nil))
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Empty(t, resp.Header().Values("Deprecation"))
+	assert.Empty(t, resp.Header().Values("Sunset"))
+	assert.Empty(t, resp.Header().Values("Link"))
+}
+
+// TestValidationCodesMatchHandlers checks bodies rejected against the OpenAPI
+// contract report the same codes as the handler binding rules
+func TestValidationCodesMatchHandlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name         string
+		path         string
+		body         string
+		expectedBody string
+	}{
+		{name: "listing type", path: "/api/v1/listings", body: `{"user_id":1,"listing_type":"foo","price":10}`, expectedBody: `{"field":"listing_type","code":"listing_type","message":"must be one of: sale, rent"}`},
+		{name: "listing price", path: "/api/v1/listings", body: `{"user_id":1,"listing_type":"rent","price":10.123}`, expectedBody: `{"field":"price","code":"listing_price"`},
+		{name: "user id", path: "/api/v1/listings", body: `{"user_id":0,"listing_type":"rent","price":10}`, expectedBody: `{"field":"user_id","code":"gt"`},
+		{name: "empty batch", path: "/api/v1/listings/batch", body: `[]`, expectedBody: `"code":"min"`},
+		{name: "user name", path: "/api/v1/users", body: `{"name":""}`, expectedBody: `{"field":"name","code":"user_name"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			r := router.SetupRouter(router.Handlers{
+				User:    handler.NewUserHandler(mocks.NewMockUserService(ctrl)),
+				Listing: handler.NewListingHandler(mocks.NewMockListingService(ctrl)),
+			})
+
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+
+			assert.Equal(t, http.StatusBadRequest, resp.Code)
+			assert.Contains(t, resp.Body.String(), tt.expectedBody)
+		})
+	}
+}
+
+// TestListingStreamIsNotBuffered checks events reach the client while the
+// stream is open even with response validation buffering other routes
+func TestListingStreamIsNotBuffered(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	hub := stream.NewHub(10, 10)
+	r := router.SetupRouter(router.Handlers{
+		Stream: handler.NewStreamHandler(hub, time.Minute),
+	}, router.WithResponseValidation(true))
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/api/v2/listings/stream")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	require.Eventually(t, func() bool { return hub.Subscribers() == 1 }, time.Second, 5*time.Millisecond)
+	hub.Publish(model.Listing{ID: 1, ListingType: "rent"})
+
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "id: 1\n", line)
+}
+
+func TestListingExportIsNotBuffered(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ls := mocks.NewMockListingService(ctrl)
+	r := router.SetupRouter(router.Handlers{
+		Listing: handler.NewListingHandler(ls),
+	}, router.WithResponseValidation(true))
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	read := make(chan struct{})
+	ls.EXPECT().ExportListings(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ model.ListingQuery, emit func([]model.Listing) error) error {
+			if err := emit([]model.Listing{{ID: 1, ListingType: "rent"}}); err != nil {
+				return err
+			}
+			<-read // the first page reached the client before the export finished
+			if err := emit([]model.Listing{{ID: 2, ListingType: "sale"}}); err != nil {
+				return err
+			}
+			return errors.New("listing-service down")
+		})
+
+	resp, err := http.Get(srv.URL + "/api/v2/listings/export?format=ndjson")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+
+	body := bufio.NewReader(resp.Body)
+	line, err := body.ReadString('\n')
+	require.NoError(t, err)
+	assert.Contains(t, line, `"id":1`)
+	close(read)
+	line, err = body.ReadString('\n')
+	require.NoError(t, err)
+	assert.Contains(t, line, `"id":2`)
+
+	// The failure after the download started is reported in the trailer
+	_, err = io.ReadAll(body)
+	require.NoError(t, err)
+	assert.Equal(t, "export interrupted: listing-service down", resp.Trailer.Get("X-Export-Error"))
+}
+
+// TestNotificationsUpgradeUnderValidation checks the WebSocket handshake is
+// not buffered by response validation
+func TestNotificationsUpgradeUnderValidation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	hub := notify.NewHub(notify.Limits{SendBuffer: 10})
+	authenticator := auth.NewTokenAuthenticator("secret")
+	r := router.SetupRouter(router.Handlers{
+		Notification: handler.NewNotificationHandler(hub, authenticator, time.Minute),
+	}, router.WithResponseValidation(true))
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws?access_token=" + authenticator.Issue(7, time.Hour)
+	ws, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer ws.Close()
+	assert.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+
+	require.NoError(t, ws.WriteJSON(notify.ClientMessage{Type: notify.TypeSubscribe, ID: "1", Topic: notify.TopicMyProfile}))
+	var ack notify.ServerMessage
+	require.NoError(t, ws.ReadJSON(&ack))
+	assert.Equal(t, notify.TypeAck, ack.Type)
+}
+
+func TestListingImportMatchesContract(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	r := router.SetupRouter(router.Handlers{
+		Import: handler.NewImportHandler(importer.New(mocks.NewMockListingService(ctrl)), 1<<20),
+	}, router.WithResponseValidation(true))
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreateFormFile("file", "listings.csv")
+	require.NoError(t, err)
+	_, err = part.Write([]byte("user_id,listing_type,price\n1,sale,100\n2,castle,100\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	for _, prefix := range []string{"/api/v1", "/api/v2"} {
+		req := httptest.NewRequest(http.MethodPost, prefix+"/listings/import?dry_run=true", bytes.NewReader(body.Bytes()))
+		req.Header.Set("Content-Type", w.FormDataContentType())
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+		assert.Contains(t, resp.Body.String(), `"errors":[{"line":3,"field":"listing_type"`)
+	}
+
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/api/v1/listings/import/1", nil))
+	assert.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+
+	resp = httptest.NewRecorder()
+	r.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/api/v1/listings/import/99", nil))
+	assert.Equal(t, http.StatusNotFound, resp.Code, resp.Body.String())
+}
+
+func TestAdminWebhooksMatchContract(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := router.SetupRouter(router.Handlers{
+		Webhook: handler.NewWebhookHandler(webhook.NewDispatcher(webhook.DefaultConfig())),
+	}, router.WithResponseValidation(true), router.WithAdminToken("admin"))
+
+	// Steps run in order against the same dispatcher
+	steps := []struct {
+		name         string
+		method       string
+		path         string
+		body         string
+		token        string
+		expectedCode int
+		expectedBody string
+	}{
+		{name: "no token", method: http.MethodGet, path: "/admin/webhooks", expectedCode: http.StatusUnauthorized},
+		{name: "wrong token", method: http.MethodGet, path: "/admin/webhooks", token: "guess", expectedCode: http.StatusUnauthorized},
+		{
+			name:         "create",
+			method:       http.MethodPost,
+			path:         "/admin/webhooks",
+			body:         `{"url":"https://partner.example.com/hooks","events":["listing.created"],"secret":"0123456789abcdef"}`,
+			expectedCode: http.StatusCreated,
+			expectedBody: `"secret":"0123456789abcdef"`,
+		},
+		{
+			name:         "create with unknown event",
+			method:       http.MethodPost,
+			path:         "/admin/webhooks",
+			body:         `{"url":"https://partner.example.com/hooks","events":["listing.deleted"]}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `"field":"events[0]"`,
+		},
+		{
+			name:         "create without events",
+			method:       http.MethodPost,
+			path:         "/admin/webhooks",
+			body:         `{"url":"https://partner.example.com/hooks","events":[]}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "create with invalid url",
+			method:       http.MethodPost,
+			path:         "/admin/webhooks",
+			body:         `{"url":"partner.example.com","events":["user.created"]}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{name: "list", method: http.MethodGet, path: "/admin/webhooks", expectedCode: http.StatusOK, expectedBody: `"url":"https://partner.example.com/hooks"`},
+		{name: "get", method: http.MethodGet, path: "/admin/webhooks/1", expectedCode: http.StatusOK},
+		{name: "deliveries", method: http.MethodGet, path: "/admin/webhooks/1/deliveries", expectedCode: http.StatusOK, expectedBody: `{"deliveries":[]}`},
+		{name: "dead letters", method: http.MethodGet, path: "/admin/dead-letters", expectedCode: http.StatusOK, expectedBody: `{"dead_letters":[]}`},
+		{name: "redeliver unknown", method: http.MethodPost, path: "/admin/dead-letters/1/redeliver", expectedCode: http.StatusNotFound},
+		{name: "delete", method: http.MethodDelete, path: "/admin/webhooks/1", expectedCode: http.StatusNoContent},
+		{name: "get deleted", method: http.MethodGet, path: "/admin/webhooks/1", expectedCode: http.StatusNotFound},
+		{name: "invalid id", method: http.MethodGet, path: "/admin/webhooks/abc", expectedCode: http.StatusBadRequest},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			req := httptest.NewRequest(step.method, step.path, bytes.NewBufferString(step.body))
+			req.Header.Set("Content-Type", "application/json")
+			token := step.token
+			if token == "" && step.expectedCode != http.StatusUnauthorized {
+				token = "admin"
+			}
+			if token != "" {
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+
+			assert.Equal(t, step.expectedCode, resp.Code, resp.Body.String())
+			assert.NotContains(t, resp.Body.String(), "violates API contract")
+			assert.Contains(t, resp.Body.String(), step.expectedBody)
+		})
+	}
+}
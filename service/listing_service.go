@@ -2,49 +2,254 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"public-api/client"
+	"public-api/event"
 	"public-api/model"
+	"sync"
 )
 
 //go:generate mockgen -destination=../mocks/mock_listing_service.go -package=mocks public-api/service ListingService
 type ListingService interface {
 	CreateListing(ctx context.Context, l model.Listing) (*model.Listing, error)
-	GetListings(ctx context.Context, page, size int, userID *int64) ([]model.Listing, error)
+	CreateListings(ctx context.Context, ls []model.Listing) ([]*model.Listing, []error, error)
+	GetListings(ctx context.Context, q model.ListingQuery) ([]model.Listing, error)
+	FindListings(ctx context.Context, q model.ListingQuery) ([]model.Listing, error)
+	GetListingByID(ctx context.Context, id int64) (*model.Listing, error)
+	ExportListings(ctx context.Context, q model.ListingQuery, emit func([]model.Listing) error) error
 }
 
+// maxExportPages bounds an export in case the listing-service ignores
+// paging and keeps returning full pages
+const maxExportPages = 10000
+
 // listingServiceImpl handles listing-related logic for public API
 type listingServiceImpl struct {
-	listingClient client.ListingClient
-	userClient    client.UserClient
+	listingClient  client.ListingClient
+	userClient     client.UserClient
+	verifyOwner    bool
+	events         event.Publisher
+	batchWorkers   int
+	exportPageSize int
+}
+
+// ListingServiceOption customizes a ListingService
+type ListingServiceOption func(*listingServiceImpl)
+
+// WithOwnerVerification toggles the check that a listing's owner exists
+// before it is created. It is enabled by default.
+func WithOwnerVerification(enabled bool) ListingServiceOption {
+	return func(ls *listingServiceImpl) {
+		ls.verifyOwner = enabled
+	}
+}
+
+// WithPublisher publishes a ListingCreated event, with the owner attached,
+// to p for each created listing
+func WithPublisher(p event.Publisher) ListingServiceOption {
+	return func(ls *listingServiceImpl) {
+		ls.events = p
+	}
+}
+
+// WithBatchConcurrency sets how many listings of a batch are created at
+// once. It defaults to 4.
+func WithBatchConcurrency(n int) ListingServiceOption {
+	return func(ls *listingServiceImpl) {
+		ls.batchWorkers = n
+	}
+}
+
+// WithExportPageSize sets how many listings an export fetches per request
+// to the listing-service. It defaults to 100.
+func WithExportPageSize(n int) ListingServiceOption {
+	return func(ls *listingServiceImpl) {
+		ls.exportPageSize = n
+	}
 }
 
 // NewListingService constructs a new ListingService
-func NewListingService(lc client.ListingClient, uc client.UserClient) ListingService {
-	return &listingServiceImpl{
-		listingClient: lc,
-		userClient:    uc,
+func NewListingService(lc client.ListingClient, uc client.UserClient, opts ...ListingServiceOption) ListingService {
+	ls := &listingServiceImpl{
+		listingClient:  lc,
+		userClient:     uc,
+		verifyOwner:    true,
+		batchWorkers:   4,
+		exportPageSize: 100,
+	}
+	for _, opt := range opts {
+		opt(ls)
 	}
+	if ls.batchWorkers < 1 {
+		ls.batchWorkers = 1
+	}
+	if ls.exportPageSize < 1 {
+		ls.exportPageSize = 1
+	}
+	return ls
 }
 
-// CreateListing creates a new listing via listing-service
+// CreateListing creates a new listing via listing-service, confirming the
+// owner exists first unless owner verification is disabled
 func (ls *listingServiceImpl) CreateListing(ctx context.Context, l model.Listing) (*model.Listing, error) {
-	if l.UserID == 0 || l.Price <= 0 || l.ListingType == "" {
-		return nil, fmt.Errorf("user_id, price, and listing_type are required")
+	if l.UserID <= 0 {
+		return nil, fmt.Errorf("%w: user_id is required", model.ErrInvalidInput)
 	}
-	return ls.listingClient.CreateListing(l)
+	if err := validateListing(l); err != nil {
+		return nil, err
+	}
+
+	var owner *model.User
+	if ls.verifyOwner {
+		var err error
+		owner, err = ls.userClient.FetchUserByID(l.UserID)
+		if err != nil {
+			if errors.Is(err, model.ErrNotFound) {
+				return nil, fmt.Errorf("%w: user %d does not exist", model.ErrUnprocessable, l.UserID)
+			}
+			return nil, fmt.Errorf("failed to fetch user: %w", err)
+		}
+		// A 200 without a user does not confirm the owner either
+		if owner == nil {
+			return nil, fmt.Errorf("%w: user %d does not exist", model.ErrUnprocessable, l.UserID)
+		}
+	}
+
+	created, err := ls.listingClient.CreateListing(l)
+	if err != nil {
+		return nil, err
+	}
+	if created != nil && owner != nil {
+		created.User = owner
+	}
+	if created != nil {
+		ls.publish(ctx, *created)
+	}
+	return created, nil
 }
 
-// GetListings fetches listings and attaches user info to each one
-func (ls *listingServiceImpl) GetListings(ctx context.Context, page, size int, userID *int64) ([]model.Listing, error) {
-	listings, err := ls.listingClient.FetchListings(page, size, userID)
+// CreateListings creates every listing of a batch like CreateListing, up to
+// the batch concurrency at a time. The whole batch is validated first and
+// rejected without creating anything if any listing is invalid. Otherwise
+// the created listing or the error of each item is returned at its index;
+// listings not yet started when ctx is canceled fail with its error.
+func (ls *listingServiceImpl) CreateListings(ctx context.Context, listings []model.Listing) ([]*model.Listing, []error, error) {
+	if len(listings) == 0 {
+		return nil, nil, fmt.Errorf("%w: at least one listing is required", model.ErrInvalidInput)
+	}
+	if len(listings) > model.MaxListingBatchSize {
+		return nil, nil, fmt.Errorf("%w: at most %d listings can be created at once", model.ErrInvalidInput, model.MaxListingBatchSize)
+	}
+	for i, l := range listings {
+		if l.UserID <= 0 {
+			return nil, nil, fmt.Errorf("%w: listing %d: user_id is required", model.ErrInvalidInput, i)
+		}
+		if err := validateListing(l); err != nil {
+			return nil, nil, fmt.Errorf("listing %d: %w", i, err)
+		}
+	}
+
+	created := make([]*model.Listing, len(listings))
+	errs := make([]error, len(listings))
+	sem := make(chan struct{}, ls.batchWorkers)
+	var wg sync.WaitGroup
+	for i, l := range listings {
+		sem <- struct{}{}
+		if err := ctx.Err(); err != nil {
+			<-sem
+			errs[i] = err
+			continue
+		}
+		wg.Add(1)
+		go func(i int, l model.Listing) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			created[i], errs[i] = ls.CreateListing(ctx, l)
+		}(i, l)
+	}
+	wg.Wait()
+	return created, errs, nil
+}
+
+// validateListing checks the listing fields supplied by the client other
+// than its owner
+func validateListing(l model.Listing) error {
+	if !model.ListingType(l.ListingType).IsValid() {
+		return fmt.Errorf("%w: listing_type must be one of %s, %s",
+			model.ErrInvalidInput, model.ListingTypeSale, model.ListingTypeRent)
+	}
+	return model.ValidatePrice(l.Price)
+}
+
+// publish announces a created listing. The owner is looked up when
+// verification was skipped; failing that the listing goes out without it.
+func (ls *listingServiceImpl) publish(ctx context.Context, l model.Listing) {
+	if ls.events == nil {
+		return
+	}
+	if l.User == nil {
+		owner, err := ls.userClient.FetchUserByID(l.UserID)
+		if err != nil {
+			log.Println("failed to fetch owner of created listing", l.ID, err)
+		} else {
+			l.User = owner
+		}
+	}
+	ls.events.Publish(ctx, event.ListingCreated{Meta: event.NewMeta(), Listing: l})
+}
+
+// GetListings fetches listings matching q and attaches user info to each one
+func (ls *listingServiceImpl) GetListings(ctx context.Context, q model.ListingQuery) ([]model.Listing, error) {
+	listings, err := ls.FindListings(ctx, q)
 	if err != nil {
 		return nil, err
 	}
+	if err := ls.attachOwners(listings); err != nil {
+		return nil, err
+	}
+	return listings, nil
+}
+
+// ExportListings walks every page of listings matching q, ignoring its page
+// options, and passes each page to emit with owners attached. Like
+// FindListings, it relies on the listing-service to filter and sort. It stops
+// at the first error from the services or emit, or when ctx is canceled.
+func (ls *listingServiceImpl) ExportListings(ctx context.Context, q model.ListingQuery, emit func([]model.Listing) error) error {
+	q.Size = ls.exportPageSize
+	for q.Page = 1; q.Page <= maxExportPages; q.Page++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		page, err := ls.listingClient.FetchListings(q)
+		if err != nil {
+			return err
+		}
+		last := len(page) < q.Size
+
+		if err := ls.attachOwners(page); err != nil {
+			return err
+		}
+		if len(page) > 0 {
+			if err := emit(page); err != nil {
+				return err
+			}
+		}
+		if last {
+			return nil
+		}
+	}
+	return fmt.Errorf("export stopped after %d pages", maxExportPages)
+}
 
+// attachOwners looks up the owners of listings in one batch and embeds them
+func (ls *listingServiceImpl) attachOwners(listings []model.Listing) error {
 	if len(listings) == 0 {
-		return listings, nil
+		return nil
 	}
 
 	// 1. Collect unique user IDs
@@ -61,7 +266,7 @@ func (ls *listingServiceImpl) GetListings(ctx context.Context, page, size int, u
 	// 2. Fetch all users in batch
 	usersMap, err := ls.userClient.FetchUsersByIDs(userIDs)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch users: %w", err)
+		return fmt.Errorf("failed to fetch users: %w", err)
 	}
 
 	// 3. Attach user info to listings
@@ -72,6 +277,32 @@ func (ls *listingServiceImpl) GetListings(ctx context.Context, page, size int, u
 			log.Println("user not found", l.UserID)
 		}
 	}
+	return nil
+}
 
-	return listings, nil
+// FindListings fetches listings matching q without their owners, for callers
+// that load users themselves. The listing-service filters, sorts and
+// paginates; its page is returned as is.
+func (ls *listingServiceImpl) FindListings(ctx context.Context, q model.ListingQuery) ([]model.Listing, error) {
+	return ls.listingClient.FetchListings(q)
+}
+
+// GetListingByID fetches a single listing and attaches its owner
+func (ls *listingServiceImpl) GetListingByID(ctx context.Context, id int64) (*model.Listing, error) {
+	listing, err := ls.listingClient.FetchListingByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := ls.userClient.FetchUserByID(listing.UserID)
+	if err != nil {
+		if !errors.Is(err, model.ErrNotFound) {
+			return nil, fmt.Errorf("failed to fetch user: %w", err)
+		}
+		log.Println("user not found", listing.UserID)
+		return listing, nil
+	}
+
+	listing.User = user
+	return listing, nil
 }
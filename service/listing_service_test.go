@@ -4,13 +4,17 @@ package service_test
 import (
 	"context"
 	"errors"
+	"public-api/event"
 	"public-api/mocks"
 	"public-api/model"
 	"public-api/service"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCreateListing(t *testing.T) {
@@ -42,22 +46,99 @@ func TestCreateListing(t *testing.T) {
 				assert.Error(t, err)
 			},
 		},
+		{
+			name:    "unknown listing type",
+			input:   model.Listing{UserID: 1, Price: 1000, ListingType: "house"},
+			mock:    func() {},
+			wantErr: true,
+			assertFunc: func(t *testing.T, res *model.Listing, err error) {
+				assert.Nil(t, res)
+				assert.ErrorIs(t, err, model.ErrInvalidInput)
+			},
+		},
+		{
+			name:    "price with too many decimals",
+			input:   model.Listing{UserID: 1, Price: 1000.125, ListingType: "rent"},
+			mock:    func() {},
+			wantErr: true,
+			assertFunc: func(t *testing.T, res *model.Listing, err error) {
+				assert.Nil(t, res)
+				assert.ErrorIs(t, err, model.ErrInvalidInput)
+			},
+		},
+		{
+			name:    "price above maximum",
+			input:   model.Listing{UserID: 1, Price: model.MaxListingPrice + 1, ListingType: "rent"},
+			mock:    func() {},
+			wantErr: true,
+			assertFunc: func(t *testing.T, res *model.Listing, err error) {
+				assert.Nil(t, res)
+				assert.ErrorIs(t, err, model.ErrInvalidInput)
+			},
+		},
 		{
 			name: "success create listing",
 			input: model.Listing{
 				UserID:      1,
 				Price:       1000,
-				ListingType: "house",
+				ListingType: "sale",
 			},
 			mock: func() {
+				userClient.EXPECT().
+					FetchUserByID(int64(1)).
+					Return(&model.User{ID: 1, Name: "John"}, nil)
 				listingClient.EXPECT().
 					CreateListing(gomock.Any()).
-					Return(&model.Listing{ID: 1, UserID: 1, Price: 1000, ListingType: "house"}, nil)
+					Return(&model.Listing{ID: 1, UserID: 1, Price: 1000, ListingType: "sale"}, nil)
 			},
 			wantErr: false,
 			assertFunc: func(t *testing.T, res *model.Listing, err error) {
 				assert.NoError(t, err)
 				assert.Equal(t, int64(1), res.ID)
+				assert.Equal(t, "John", res.User.Name)
+			},
+		},
+		{
+			name:  "owner does not exist",
+			input: model.Listing{UserID: 2, Price: 1000, ListingType: "sale"},
+			mock: func() {
+				userClient.EXPECT().
+					FetchUserByID(int64(2)).
+					Return(nil, model.ErrNotFound)
+			},
+			wantErr: true,
+			assertFunc: func(t *testing.T, res *model.Listing, err error) {
+				assert.Nil(t, res)
+				assert.ErrorIs(t, err, model.ErrUnprocessable)
+			},
+		},
+		{
+			name:  "owner missing from response",
+			input: model.Listing{UserID: 4, Price: 1000, ListingType: "sale"},
+			mock: func() {
+				userClient.EXPECT().
+					FetchUserByID(int64(4)).
+					Return(nil, nil)
+			},
+			wantErr: true,
+			assertFunc: func(t *testing.T, res *model.Listing, err error) {
+				assert.Nil(t, res)
+				assert.ErrorIs(t, err, model.ErrUnprocessable)
+			},
+		},
+		{
+			name:  "owner lookup fails",
+			input: model.Listing{UserID: 3, Price: 1000, ListingType: "sale"},
+			mock: func() {
+				userClient.EXPECT().
+					FetchUserByID(int64(3)).
+					Return(nil, errors.New("user error"))
+			},
+			wantErr: true,
+			assertFunc: func(t *testing.T, res *model.Listing, err error) {
+				assert.Nil(t, res)
+				assert.Error(t, err)
+				assert.NotErrorIs(t, err, model.ErrUnprocessable)
 			},
 		},
 	}
@@ -71,6 +152,196 @@ func TestCreateListing(t *testing.T) {
 	}
 }
 
+func TestCreateListingWithoutOwnerVerification(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	listingClient := mocks.NewMockListingClient(ctrl)
+	userClient := mocks.NewMockUserClient(ctrl)
+	svc := service.NewListingService(listingClient, userClient, service.WithOwnerVerification(false))
+
+	listingClient.EXPECT().
+		CreateListing(gomock.Any()).
+		Return(&model.Listing{ID: 1, UserID: 1, Price: 1000, ListingType: "sale"}, nil)
+
+	res, err := svc.CreateListing(context.Background(), model.Listing{UserID: 1, Price: 1000, ListingType: "sale"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), res.ID)
+	assert.Nil(t, res.User)
+}
+
+func TestCreateListings(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	listingClient := mocks.NewMockListingClient(ctrl)
+	userClient := mocks.NewMockUserClient(ctrl)
+	svc := service.NewListingService(listingClient, userClient,
+		service.WithOwnerVerification(false), service.WithBatchConcurrency(2))
+
+	var inFlight, peak int32
+	listingClient.EXPECT().
+		CreateListing(gomock.Any()).
+		DoAndReturn(func(l model.Listing) (*model.Listing, error) {
+			n := atomic.AddInt32(&inFlight, 1)
+			defer atomic.AddInt32(&inFlight, -1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			if l.Price == 13 {
+				return nil, errors.New("listing-service down")
+			}
+			return &model.Listing{ID: int64(l.Price), UserID: l.UserID}, nil
+		}).
+		Times(6)
+
+	batch := make([]model.Listing, 6)
+	for i := range batch {
+		batch[i] = model.Listing{UserID: 1, ListingType: "sale", Price: float64(10 + i)}
+	}
+	created, errs, err := svc.CreateListings(context.Background(), batch)
+	require.NoError(t, err)
+
+	for i := range batch {
+		if i == 3 {
+			assert.Nil(t, created[i])
+			assert.EqualError(t, errs[i], "listing-service down")
+			continue
+		}
+		assert.NoError(t, errs[i])
+		assert.Equal(t, int64(10+i), created[i].ID, "results keep the input order")
+	}
+	assert.LessOrEqual(t, atomic.LoadInt32(&peak), int32(2))
+}
+
+func TestCreateListingsValidatesWholeBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// No client calls are expected
+	svc := service.NewListingService(mocks.NewMockListingClient(ctrl), mocks.NewMockUserClient(ctrl))
+
+	valid := model.Listing{UserID: 1, ListingType: "sale", Price: 100}
+	tests := []struct {
+		name  string
+		batch []model.Listing
+		want  string
+	}{
+		{name: "empty", batch: nil, want: "at least one listing"},
+		{name: "too large", batch: make([]model.Listing, model.MaxListingBatchSize+1), want: "at most 100 listings"},
+		{name: "one invalid type", batch: []model.Listing{valid, {UserID: 1, ListingType: "castle", Price: 100}}, want: "listing 1: "},
+		{name: "one without owner", batch: []model.Listing{valid, valid, {ListingType: "sale", Price: 100}}, want: "listing 2: user_id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := svc.CreateListings(context.Background(), tt.batch)
+			assert.ErrorIs(t, err, model.ErrInvalidInput)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestCreateListingsStopsWhenCanceled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	listingClient := mocks.NewMockListingClient(ctrl)
+	svc := service.NewListingService(listingClient, mocks.NewMockUserClient(ctrl),
+		service.WithOwnerVerification(false), service.WithBatchConcurrency(1))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	listingClient.EXPECT().
+		CreateListing(gomock.Any()).
+		DoAndReturn(func(l model.Listing) (*model.Listing, error) {
+			cancel()
+			return &model.Listing{ID: 1}, nil
+		})
+
+	listing := model.Listing{UserID: 1, ListingType: "sale", Price: 100}
+	created, errs, err := svc.CreateListings(ctx, []model.Listing{listing, listing, listing})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), created[0].ID)
+	assert.ErrorIs(t, errs[1], context.Canceled)
+	assert.ErrorIs(t, errs[2], context.Canceled)
+}
+
+// recordingPublisher keeps the listing of every published ListingCreated
+type recordingPublisher struct {
+	published []model.Listing
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, e event.Event) {
+	if created, ok := e.(event.ListingCreated); ok {
+		p.published = append(p.published, created.Listing)
+	}
+}
+
+func TestCreateListingPublishes(t *testing.T) {
+	owner := &model.User{ID: 1, Name: "John"}
+
+	tests := []struct {
+		name          string
+		opts          []service.ListingServiceOption
+		mock          func(lc *mocks.MockListingClient, uc *mocks.MockUserClient)
+		wantPublished []model.Listing
+	}{
+		{
+			name: "with verified owner",
+			mock: func(lc *mocks.MockListingClient, uc *mocks.MockUserClient) {
+				uc.EXPECT().FetchUserByID(int64(1)).Return(owner, nil)
+				lc.EXPECT().CreateListing(gomock.Any()).Return(&model.Listing{ID: 5, UserID: 1, ListingType: "sale", Price: 10}, nil)
+			},
+			wantPublished: []model.Listing{{ID: 5, UserID: 1, ListingType: "sale", Price: 10, User: owner}},
+		},
+		{
+			name: "owner fetched when verification is off",
+			opts: []service.ListingServiceOption{service.WithOwnerVerification(false)},
+			mock: func(lc *mocks.MockListingClient, uc *mocks.MockUserClient) {
+				lc.EXPECT().CreateListing(gomock.Any()).Return(&model.Listing{ID: 5, UserID: 1, ListingType: "sale", Price: 10}, nil)
+				uc.EXPECT().FetchUserByID(int64(1)).Return(owner, nil)
+			},
+			wantPublished: []model.Listing{{ID: 5, UserID: 1, ListingType: "sale", Price: 10, User: owner}},
+		},
+		{
+			name: "published without owner when lookup fails",
+			opts: []service.ListingServiceOption{service.WithOwnerVerification(false)},
+			mock: func(lc *mocks.MockListingClient, uc *mocks.MockUserClient) {
+				lc.EXPECT().CreateListing(gomock.Any()).Return(&model.Listing{ID: 5, UserID: 1, ListingType: "sale", Price: 10}, nil)
+				uc.EXPECT().FetchUserByID(int64(1)).Return(nil, errors.New("boom"))
+			},
+			wantPublished: []model.Listing{{ID: 5, UserID: 1, ListingType: "sale", Price: 10}},
+		},
+		{
+			name: "nothing published on failure",
+			mock: func(lc *mocks.MockListingClient, uc *mocks.MockUserClient) {
+				uc.EXPECT().FetchUserByID(int64(1)).Return(owner, nil)
+				lc.EXPECT().CreateListing(gomock.Any()).Return(nil, errors.New("boom"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			listingClient := mocks.NewMockListingClient(ctrl)
+			userClient := mocks.NewMockUserClient(ctrl)
+			tt.mock(listingClient, userClient)
+
+			pub := &recordingPublisher{}
+			svc := service.NewListingService(listingClient, userClient, append(tt.opts, service.WithPublisher(pub))...)
+
+			_, _ = svc.CreateListing(context.Background(), model.Listing{UserID: 1, Price: 10, ListingType: "sale"})
+			assert.Equal(t, tt.wantPublished, pub.published)
+		})
+	}
+}
+
 func TestGetListings(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -81,20 +352,17 @@ func TestGetListings(t *testing.T) {
 
 	tests := []struct {
 		name       string
-		page, size int
-		userID     *int64
+		query      model.ListingQuery
 		mock       func()
 		wantErr    bool
 		assertFunc func(t *testing.T, res []model.Listing, err error)
 	}{
 		{
-			name:   "listing client error",
-			page:   1,
-			size:   10,
-			userID: nil,
+			name:  "listing client error",
+			query: model.ListingQuery{Page: 1, Size: 10},
 			mock: func() {
 				listingClient.EXPECT().
-					FetchListings(1, 10, nil).
+					FetchListings(model.ListingQuery{Page: 1, Size: 10}).
 					Return(nil, errors.New("listing error"))
 			},
 			wantErr: true,
@@ -104,13 +372,11 @@ func TestGetListings(t *testing.T) {
 			},
 		},
 		{
-			name:   "empty listings",
-			page:   1,
-			size:   10,
-			userID: nil,
+			name:  "empty listings",
+			query: model.ListingQuery{Page: 1, Size: 10},
 			mock: func() {
 				listingClient.EXPECT().
-					FetchListings(1, 10, nil).
+					FetchListings(model.ListingQuery{Page: 1, Size: 10}).
 					Return([]model.Listing{}, nil)
 			},
 			wantErr: false,
@@ -120,13 +386,11 @@ func TestGetListings(t *testing.T) {
 			},
 		},
 		{
-			name:   "user client error",
-			page:   1,
-			size:   10,
-			userID: nil,
+			name:  "user client error",
+			query: model.ListingQuery{Page: 1, Size: 10},
 			mock: func() {
 				listingClient.EXPECT().
-					FetchListings(1, 10, nil).
+					FetchListings(model.ListingQuery{Page: 1, Size: 10}).
 					Return([]model.Listing{
 						{ID: 1, UserID: 123, Price: 999},
 					}, nil)
@@ -142,13 +406,11 @@ func TestGetListings(t *testing.T) {
 			},
 		},
 		{
-			name:   "success with user mapping",
-			page:   1,
-			size:   10,
-			userID: nil,
+			name:  "success with user mapping",
+			query: model.ListingQuery{Page: 1, Size: 10},
 			mock: func() {
 				listingClient.EXPECT().
-					FetchListings(1, 10, nil).
+					FetchListings(model.ListingQuery{Page: 1, Size: 10}).
 					Return([]model.Listing{
 						{ID: 1, UserID: 123, Price: 999},
 					}, nil)
@@ -166,12 +428,229 @@ func TestGetListings(t *testing.T) {
 				assert.Equal(t, "John", res[0].User.Name)
 			},
 		},
+		{
+			name:  "filters and sorts in listing-service",
+			query: model.ListingQuery{Page: 1, Size: 10, ListingType: "rent", SortBy: model.ListingSortPrice, SortOrder: model.SortDesc},
+			mock: func() {
+				listingClient.EXPECT().
+					FetchListings(model.ListingQuery{Page: 1, Size: 10, ListingType: "rent", SortBy: model.ListingSortPrice, SortOrder: model.SortDesc}).
+					Return([]model.Listing{
+						{ID: 3, UserID: 123, Price: 200, ListingType: "rent"},
+						{ID: 1, UserID: 123, Price: 100, ListingType: "rent"},
+					}, nil)
+
+				userClient.EXPECT().
+					FetchUsersByIDs([]int64{123}).
+					Return(map[int64]*model.User{
+						123: {ID: 123, Name: "John"},
+					}, nil)
+			},
+			wantErr: false,
+			assertFunc: func(t *testing.T, res []model.Listing, err error) {
+				assert.NoError(t, err)
+				assert.Len(t, res, 2)
+				assert.Equal(t, int64(3), res[0].ID)
+				assert.Equal(t, int64(1), res[1].ID)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mock()
+			res, err := svc.GetListings(context.Background(), tt.query)
+			tt.assertFunc(t, res, err)
+		})
+	}
+}
+
+func TestFindListings(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	listingClient := mocks.NewMockListingClient(ctrl)
+	userClient := mocks.NewMockUserClient(ctrl)
+	svc := service.NewListingService(listingClient, userClient)
+
+	q := model.ListingQuery{Page: 1, Size: 10, ListingType: "rent", SortBy: model.ListingSortPrice, SortOrder: model.SortDesc}
+	listingClient.EXPECT().FetchListings(q).Return([]model.Listing{
+		{ID: 3, UserID: 1, ListingType: "rent", Price: 200},
+		{ID: 1, UserID: 1, ListingType: "rent", Price: 100},
+	}, nil)
+
+	// Owners are not fetched, so no userClient expectations, and the page
+	// is returned as the listing-service ordered it
+	res, err := svc.FindListings(context.Background(), q)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.Listing{
+		{ID: 3, UserID: 1, ListingType: "rent", Price: 200},
+		{ID: 1, UserID: 1, ListingType: "rent", Price: 100},
+	}, res)
+}
+
+func TestExportListings(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	listingClient := mocks.NewMockListingClient(ctrl)
+	userClient := mocks.NewMockUserClient(ctrl)
+	svc := service.NewListingService(listingClient, userClient, service.WithExportPageSize(2))
+
+	q := model.ListingQuery{ListingType: "rent"}
+	gomock.InOrder(
+		listingClient.EXPECT().FetchListings(model.ListingQuery{Page: 1, Size: 2, ListingType: "rent"}).Return([]model.Listing{
+			{ID: 1, UserID: 1, ListingType: "rent"},
+			{ID: 2, UserID: 2, ListingType: "rent"},
+		}, nil),
+		userClient.EXPECT().FetchUsersByIDs(gomock.Any()).Return(map[int64]*model.User{1: {ID: 1}, 2: {ID: 2}}, nil),
+		listingClient.EXPECT().FetchListings(model.ListingQuery{Page: 2, Size: 2, ListingType: "rent"}).Return([]model.Listing{
+			{ID: 3, UserID: 1, ListingType: "rent"},
+			{ID: 4, UserID: 1, ListingType: "rent"},
+		}, nil),
+		userClient.EXPECT().FetchUsersByIDs([]int64{1}).Return(map[int64]*model.User{1: {ID: 1}}, nil),
+		listingClient.EXPECT().FetchListings(model.ListingQuery{Page: 3, Size: 2, ListingType: "rent"}).Return([]model.Listing{
+			{ID: 5, UserID: 3, ListingType: "rent"},
+		}, nil),
+		userClient.EXPECT().FetchUsersByIDs([]int64{3}).Return(map[int64]*model.User{3: {ID: 3}}, nil),
+	)
+
+	var pages [][]int64
+	err := svc.ExportListings(context.Background(), q, func(page []model.Listing) error {
+		var ids []int64
+		for _, l := range page {
+			require.NotNil(t, l.User)
+			ids = append(ids, l.ID)
+		}
+		pages = append(pages, ids)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, [][]int64{{1, 2}, {3, 4}, {5}}, pages)
+}
+
+func TestExportListingsStops(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	listingClient := mocks.NewMockListingClient(ctrl)
+	userClient := mocks.NewMockUserClient(ctrl)
+	svc := service.NewListingService(listingClient, userClient, service.WithExportPageSize(1))
+	full := []model.Listing{{ID: 1, UserID: 1}}
+
+	t.Run("emit error", func(t *testing.T) {
+		listingClient.EXPECT().FetchListings(gomock.Any()).Return(full, nil)
+		userClient.EXPECT().FetchUsersByIDs(gomock.Any()).Return(map[int64]*model.User{1: {ID: 1}}, nil)
+
+		writeErr := errors.New("broken pipe")
+		err := svc.ExportListings(context.Background(), model.ListingQuery{}, func([]model.Listing) error { return writeErr })
+		assert.ErrorIs(t, err, writeErr)
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		listingClient.EXPECT().FetchListings(gomock.Any()).Return(full, nil)
+		userClient.EXPECT().FetchUsersByIDs(gomock.Any()).Return(map[int64]*model.User{1: {ID: 1}}, nil)
+
+		err := svc.ExportListings(ctx, model.ListingQuery{}, func([]model.Listing) error {
+			cancel() // the client disconnected; the next page is never fetched
+			return nil
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("downstream error", func(t *testing.T) {
+		listingClient.EXPECT().FetchListings(gomock.Any()).Return(nil, errors.New("listing error"))
+
+		err := svc.ExportListings(context.Background(), model.ListingQuery{}, func([]model.Listing) error {
+			t.Fatal("nothing to emit")
+			return nil
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestGetListingByID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	listingClient := mocks.NewMockListingClient(ctrl)
+	userClient := mocks.NewMockUserClient(ctrl)
+	svc := service.NewListingService(listingClient, userClient)
+
+	tests := []struct {
+		name       string
+		id         int64
+		mock       func()
+		assertFunc func(t *testing.T, res *model.Listing, err error)
+	}{
+		{
+			name: "listing not found",
+			id:   1,
+			mock: func() {
+				listingClient.EXPECT().
+					FetchListingByID(int64(1)).
+					Return(nil, model.ErrNotFound)
+			},
+			assertFunc: func(t *testing.T, res *model.Listing, err error) {
+				assert.Nil(t, res)
+				assert.ErrorIs(t, err, model.ErrNotFound)
+			},
+		},
+		{
+			name: "user client error",
+			id:   1,
+			mock: func() {
+				listingClient.EXPECT().
+					FetchListingByID(int64(1)).
+					Return(&model.Listing{ID: 1, UserID: 123}, nil)
+				userClient.EXPECT().
+					FetchUserByID(int64(123)).
+					Return(nil, errors.New("user error"))
+			},
+			assertFunc: func(t *testing.T, res *model.Listing, err error) {
+				assert.Nil(t, res)
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "owner missing",
+			id:   1,
+			mock: func() {
+				listingClient.EXPECT().
+					FetchListingByID(int64(1)).
+					Return(&model.Listing{ID: 1, UserID: 123}, nil)
+				userClient.EXPECT().
+					FetchUserByID(int64(123)).
+					Return(nil, model.ErrNotFound)
+			},
+			assertFunc: func(t *testing.T, res *model.Listing, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, int64(1), res.ID)
+				assert.Nil(t, res.User)
+			},
+		},
+		{
+			name: "success with user",
+			id:   1,
+			mock: func() {
+				listingClient.EXPECT().
+					FetchListingByID(int64(1)).
+					Return(&model.Listing{ID: 1, UserID: 123}, nil)
+				userClient.EXPECT().
+					FetchUserByID(int64(123)).
+					Return(&model.User{ID: 123, Name: "John"}, nil)
+			},
+			assertFunc: func(t *testing.T, res *model.Listing, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, "John", res.User.Name)
+			},
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mock()
-			res, err := svc.GetListings(context.Background(), tt.page, tt.size, tt.userID)
+			res, err := svc.GetListingByID(context.Background(), tt.id)
 			tt.assertFunc(t, res, err)
 		})
 	}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"public-api/model"
+)
+
+// StepListing names the onboarding step that creates the listing
+const StepListing = "listing"
+
+//go:generate mockgen -destination=../mocks/mock_onboarding_service.go -package=mocks public-api/service OnboardingService
+type OnboardingService interface {
+	Onboard(ctx context.Context, name string, l model.Listing) (*model.Onboarding, error)
+}
+
+// OnboardingError reports an onboarding that stopped after the user was
+// created. The user-service cannot delete users, so instead of compensating
+// the user is kept and returned so the client can retry the failed step.
+type OnboardingError struct {
+	Step string
+	User *model.User
+	Err  error
+}
+
+func (e *OnboardingError) Error() string {
+	return fmt.Sprintf("user %d was created but the %s step failed: %v", e.User.ID, e.Step, e.Err)
+}
+
+func (e *OnboardingError) Unwrap() error {
+	return e.Err
+}
+
+// onboardingServiceImpl creates a user and their first listing as one
+// operation on top of the user and listing services
+type onboardingServiceImpl struct {
+	users    UserService
+	listings ListingService
+}
+
+// NewOnboardingService constructs a new OnboardingService
+func NewOnboardingService(users UserService, listings ListingService) OnboardingService {
+	return &onboardingServiceImpl{users: users, listings: listings}
+}
+
+// Onboard creates a user named name, then l owned by that user. Both are
+// validated before anything is created, so a failure in the listing step
+// can only come from the listing-service; it is reported as an
+// *OnboardingError wrapping the cause.
+func (o *onboardingServiceImpl) Onboard(ctx context.Context, name string, l model.Listing) (*model.Onboarding, error) {
+	name, err := model.NormalizeUserName(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateListing(l); err != nil {
+		return nil, err
+	}
+
+	user, err := o.users.CreateUser(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errMissingUser
+	}
+
+	l.UserID = user.ID
+	listing, err := o.listings.CreateListing(ctx, l)
+	if err != nil {
+		log.Println("onboarding left user without a listing", user.ID, err)
+		return nil, &OnboardingError{Step: StepListing, User: user, Err: err}
+	}
+	return &model.Onboarding{User: user, Listing: listing}, nil
+}
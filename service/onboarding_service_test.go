@@ -0,0 +1,118 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"public-api/mocks"
+	"public-api/model"
+	"public-api/service"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOnboard(t *testing.T) {
+	user := &model.User{ID: 7, Name: "Jane Doe"}
+	listing := model.Listing{ListingType: "rent", Price: 1500}
+	created := &model.Listing{ID: 3, UserID: 7, ListingType: "rent", Price: 1500, User: user}
+
+	tests := []struct {
+		name       string
+		userName   string
+		listing    model.Listing
+		mock       func(us *mocks.MockUserService, ls *mocks.MockListingService)
+		assertFunc func(t *testing.T, res *model.Onboarding, err error)
+	}{
+		{
+			name:     "success",
+			userName: "  Jane   Doe ",
+			listing:  listing,
+			mock: func(us *mocks.MockUserService, ls *mocks.MockListingService) {
+				us.EXPECT().CreateUser(gomock.Any(), "Jane Doe").Return(user, nil)
+				ls.EXPECT().CreateListing(gomock.Any(), model.Listing{UserID: 7, ListingType: "rent", Price: 1500}).Return(created, nil)
+			},
+			assertFunc: func(t *testing.T, res *model.Onboarding, err error) {
+				require.NoError(t, err)
+				assert.Equal(t, &model.Onboarding{User: user, Listing: created}, res)
+			},
+		},
+		{
+			name:     "no user returned",
+			userName: "Jane Doe",
+			listing:  listing,
+			mock: func(us *mocks.MockUserService, ls *mocks.MockListingService) {
+				us.EXPECT().CreateUser(gomock.Any(), "Jane Doe").Return(nil, nil)
+			},
+			assertFunc: func(t *testing.T, res *model.Onboarding, err error) {
+				assert.Nil(t, res)
+				assert.Error(t, err)
+			},
+		},
+		{
+			name:     "invalid listing creates nothing",
+			userName: "Jane Doe",
+			listing:  model.Listing{ListingType: "castle", Price: 1500},
+			mock:     func(us *mocks.MockUserService, ls *mocks.MockListingService) {},
+			assertFunc: func(t *testing.T, res *model.Onboarding, err error) {
+				assert.Nil(t, res)
+				assert.ErrorIs(t, err, model.ErrInvalidInput)
+			},
+		},
+		{
+			name:     "invalid name creates nothing",
+			userName: "J4ne",
+			listing:  listing,
+			mock:     func(us *mocks.MockUserService, ls *mocks.MockListingService) {},
+			assertFunc: func(t *testing.T, res *model.Onboarding, err error) {
+				assert.Nil(t, res)
+				assert.ErrorIs(t, err, model.ErrInvalidInput)
+			},
+		},
+		{
+			name:     "user step fails",
+			userName: "Jane Doe",
+			listing:  listing,
+			mock: func(us *mocks.MockUserService, ls *mocks.MockListingService) {
+				us.EXPECT().CreateUser(gomock.Any(), "Jane Doe").Return(nil, errors.New("user-service down"))
+			},
+			assertFunc: func(t *testing.T, res *model.Onboarding, err error) {
+				assert.Nil(t, res)
+				var partial *service.OnboardingError
+				assert.False(t, errors.As(err, &partial))
+				assert.EqualError(t, err, "user-service down")
+			},
+		},
+		{
+			name:     "listing step fails after the user was created",
+			userName: "Jane Doe",
+			listing:  listing,
+			mock: func(us *mocks.MockUserService, ls *mocks.MockListingService) {
+				us.EXPECT().CreateUser(gomock.Any(), "Jane Doe").Return(user, nil)
+				ls.EXPECT().CreateListing(gomock.Any(), gomock.Any()).Return(nil, model.ErrUnprocessable)
+			},
+			assertFunc: func(t *testing.T, res *model.Onboarding, err error) {
+				assert.Nil(t, res)
+				var partial *service.OnboardingError
+				require.ErrorAs(t, err, &partial)
+				assert.Equal(t, service.StepListing, partial.Step)
+				assert.Equal(t, user, partial.User)
+				assert.ErrorIs(t, err, model.ErrUnprocessable)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			us := mocks.NewMockUserService(ctrl)
+			ls := mocks.NewMockListingService(ctrl)
+			tt.mock(us, ls)
+
+			res, err := service.NewOnboardingService(us, ls).Onboard(context.Background(), tt.userName, tt.listing)
+			tt.assertFunc(t, res, err)
+		})
+	}
+}
@@ -2,8 +2,10 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"public-api/client"
+	"public-api/event"
 	"public-api/model"
 )
 
@@ -11,27 +13,92 @@ import (
 type UserService interface {
 	CreateUser(ctx context.Context, name string) (*model.User, error)
 	GetUserByID(ctx context.Context, id int64) (*model.User, error)
+	GetUsersByIDs(ctx context.Context, ids []int64) (map[int64]*model.User, error)
+	UpdateUser(ctx context.Context, id int64, name string) (*model.User, error)
 }
 
 // UserService handles user-related operations for the public API
 type userServiceImpl struct {
 	client client.UserClient
+	events event.Publisher
 }
 
+// UserServiceOption customizes a UserService
+type UserServiceOption func(*userServiceImpl)
+
+// WithUserPublisher publishes UserCreated and UserUpdated events to p
+func WithUserPublisher(p event.Publisher) UserServiceOption {
+	return func(us *userServiceImpl) {
+		us.events = p
+	}
+}
+
+// errMissingUser is returned when the user-service confirms a write without
+// returning the user
+var errMissingUser = errors.New("user-service returned no user")
+
 // NewUserService constructs a new UserService
-func NewUserService(client client.UserClient) UserService {
-	return &userServiceImpl{client: client}
+func NewUserService(client client.UserClient, opts ...UserServiceOption) UserService {
+	us := &userServiceImpl{client: client}
+	for _, opt := range opts {
+		opt(us)
+	}
+	return us
 }
 
 // CreateUser creates a user by delegating to the user-service
 func (us *userServiceImpl) CreateUser(ctx context.Context, name string) (*model.User, error) {
-	if name == "" {
-		return nil, fmt.Errorf("name is required")
+	name, err := model.NormalizeUserName(name)
+	if err != nil {
+		return nil, err
+	}
+	created, err := us.client.CreateUser(name)
+	if err != nil {
+		return nil, err
+	}
+	if created == nil {
+		return nil, errMissingUser
 	}
-	return us.client.CreateUser(name)
+	us.publish(ctx, event.UserCreated{Meta: event.NewMeta(), User: *created})
+	return created, nil
 }
 
 // GetUserByID fetches a user by ID
 func (us *userServiceImpl) GetUserByID(ctx context.Context, id int64) (*model.User, error) {
 	return us.client.FetchUserByID(id)
 }
+
+// GetUsersByIDs fetches several users in one call, keyed by ID. Unknown IDs
+// are left out of the result.
+func (us *userServiceImpl) GetUsersByIDs(ctx context.Context, ids []int64) (map[int64]*model.User, error) {
+	if len(ids) == 0 {
+		return map[int64]*model.User{}, nil
+	}
+	return us.client.FetchUsersByIDs(ids)
+}
+
+// UpdateUser validates and renames a user via the user-service
+func (us *userServiceImpl) UpdateUser(ctx context.Context, id int64, name string) (*model.User, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("%w: id must be positive", model.ErrInvalidInput)
+	}
+	name, err := model.NormalizeUserName(name)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := us.client.UpdateUser(id, name)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, errMissingUser
+	}
+	us.publish(ctx, event.UserUpdated{Meta: event.NewMeta(), User: *updated})
+	return updated, nil
+}
+
+func (us *userServiceImpl) publish(ctx context.Context, e event.Event) {
+	if us.events != nil {
+		us.events.Publish(ctx, e)
+	}
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"errors"
+	"public-api/event"
 	"public-api/mocks"
 	"public-api/model"
 	"testing"
@@ -45,6 +46,17 @@ func TestUserService_CreateUser(t *testing.T) {
 			expectedUser: nil,
 			expectError:  true,
 		},
+		{
+			name:      "no user returned",
+			inputName: "Rifqi",
+			mockBehavior: func() {
+				mockClient.EXPECT().
+					CreateUser("Rifqi").
+					Return(nil, nil)
+			},
+			expectedUser: nil,
+			expectError:  true,
+		},
 		{
 			name:      "client error",
 			inputName: "ErrorCase",
@@ -129,3 +141,172 @@ func TestUserService_GetUserByID(t *testing.T) {
 		})
 	}
 }
+
+func TestUserService_GetUsersByIDs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mocks.NewMockUserClient(ctrl)
+	svc := NewUserService(mockClient)
+
+	t.Run("success", func(t *testing.T) {
+		users := map[int64]*model.User{1: {ID: 1, Name: "John"}}
+		mockClient.EXPECT().FetchUsersByIDs([]int64{1, 2}).Return(users, nil)
+
+		res, err := svc.GetUsersByIDs(context.Background(), []int64{1, 2})
+		assert.NoError(t, err)
+		assert.Equal(t, users, res)
+	})
+
+	t.Run("no ids skips the call", func(t *testing.T) {
+		res, err := svc.GetUsersByIDs(context.Background(), nil)
+		assert.NoError(t, err)
+		assert.Empty(t, res)
+	})
+}
+
+func TestUserService_UpdateUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mocks.NewMockUserClient(ctrl)
+	svc := NewUserService(mockClient)
+
+	tests := []struct {
+		name         string
+		inputID      int64
+		inputName    string
+		mockBehavior func()
+		expectedUser *model.User
+		expectError  error
+	}{
+		{
+			name:      "success trims whitespace",
+			inputID:   1,
+			inputName: "  Jane   Doe ",
+			mockBehavior: func() {
+				mockClient.EXPECT().
+					UpdateUser(int64(1), "Jane Doe").
+					Return(&model.User{ID: 1, Name: "Jane Doe"}, nil)
+			},
+			expectedUser: &model.User{ID: 1, Name: "Jane Doe"},
+		},
+		{
+			name:      "no user returned",
+			inputID:   1,
+			inputName: "Jane",
+			mockBehavior: func() {
+				mockClient.EXPECT().
+					UpdateUser(int64(1), "Jane").
+					Return(nil, nil)
+			},
+			expectError: errMissingUser,
+		},
+		{
+			name:         "invalid id",
+			inputID:      0,
+			inputName:    "Jane",
+			mockBehavior: func() {},
+			expectError:  model.ErrInvalidInput,
+		},
+		{
+			name:         "name too short",
+			inputID:      1,
+			inputName:    " J ",
+			mockBehavior: func() {},
+			expectError:  model.ErrInvalidInput,
+		},
+		{
+			name:         "invalid characters",
+			inputID:      1,
+			inputName:    "Jane<script>",
+			mockBehavior: func() {},
+			expectError:  model.ErrInvalidInput,
+		},
+		{
+			name:      "user not found",
+			inputID:   2,
+			inputName: "Jane",
+			mockBehavior: func() {
+				mockClient.EXPECT().
+					UpdateUser(int64(2), "Jane").
+					Return(nil, model.ErrNotFound)
+			},
+			expectError: model.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockBehavior()
+
+			user, err := svc.UpdateUser(context.Background(), tt.inputID, tt.inputName)
+
+			if tt.expectError != nil {
+				assert.ErrorIs(t, err, tt.expectError)
+				assert.Nil(t, user)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedUser, user)
+			}
+		})
+	}
+}
+
+// recordingUserPublisher keeps every published event
+type recordingUserPublisher struct {
+	published []event.Event
+}
+
+func (p *recordingUserPublisher) Publish(_ context.Context, e event.Event) {
+	p.published = append(p.published, e)
+}
+
+// users returns the user carried by each published event of type T
+func users[T event.Event](p *recordingUserPublisher, user func(T) model.User) []model.User {
+	var out []model.User
+	for _, e := range p.published {
+		if typed, ok := e.(T); ok {
+			out = append(out, user(typed))
+		}
+	}
+	return out
+}
+
+func TestUserService_UpdateUserPublishes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mocks.NewMockUserClient(ctrl)
+	pub := &recordingUserPublisher{}
+	svc := NewUserService(mockClient, WithUserPublisher(pub))
+
+	mockClient.EXPECT().UpdateUser(int64(1), "Jane").Return(&model.User{ID: 1, Name: "Jane"}, nil)
+	mockClient.EXPECT().UpdateUser(int64(2), "Jane").Return(nil, model.ErrNotFound)
+
+	_, _ = svc.UpdateUser(context.Background(), 1, "Jane")
+	_, _ = svc.UpdateUser(context.Background(), 2, "Jane")
+	_, _ = svc.UpdateUser(context.Background(), 3, "J")
+
+	assert.Len(t, pub.published, 1)
+	assert.Equal(t, []model.User{{ID: 1, Name: "Jane"}}, users(pub, func(e event.UserUpdated) model.User { return e.User }))
+}
+
+func TestUserService_CreateUserPublishes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := mocks.NewMockUserClient(ctrl)
+	pub := &recordingUserPublisher{}
+	svc := NewUserService(mockClient, WithUserPublisher(pub))
+
+	mockClient.EXPECT().CreateUser("Jane").Return(&model.User{ID: 1, Name: "Jane"}, nil)
+	mockClient.EXPECT().CreateUser("John").Return(nil, errors.New("boom"))
+
+	_, _ = svc.CreateUser(context.Background(), "Jane")
+	_, _ = svc.CreateUser(context.Background(), "John")
+	_, _ = svc.CreateUser(context.Background(), "")
+
+	assert.Len(t, pub.published, 1)
+	assert.Equal(t, []model.User{{ID: 1, Name: "Jane"}}, users(pub, func(e event.UserCreated) model.User { return e.User }))
+}
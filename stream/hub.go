@@ -0,0 +1,137 @@
+package stream
+
+import (
+	"public-api/model"
+	"sync"
+)
+
+// Event is a created listing as delivered to subscribers. IDs increase by
+// one per published listing and restart when the process does.
+type Event struct {
+	ID      uint64
+	Listing model.Listing
+}
+
+// Hub fans newly created listings out to subscribers and keeps a bounded
+// backlog so reconnecting clients can resume where they left off
+type Hub struct {
+	mu          sync.Mutex
+	lastID      uint64
+	backlog     []Event
+	backlogSize int
+	bufferSize  int
+	subs        map[*Subscription]struct{}
+}
+
+// NewHub returns a hub remembering the last backlogSize events and buffering
+// up to bufferSize undelivered events per subscriber
+func NewHub(backlogSize, bufferSize int) *Hub {
+	if bufferSize < 1 {
+		bufferSize = 1
+	}
+	return &Hub{
+		backlogSize: backlogSize,
+		bufferSize:  bufferSize,
+		subs:        make(map[*Subscription]struct{}),
+	}
+}
+
+// Publish delivers l to every matching subscriber. A subscriber whose buffer
+// is full is disconnected rather than slowing down the publisher; it can
+// reconnect with the last ID it saw and catch up from the backlog.
+func (h *Hub) Publish(l model.Listing) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.lastID++
+	ev := Event{ID: h.lastID, Listing: l}
+
+	if h.backlogSize > 0 {
+		if len(h.backlog) == h.backlogSize {
+			h.backlog = append(h.backlog[:0], h.backlog[1:]...)
+		}
+		h.backlog = append(h.backlog, ev)
+	}
+
+	for sub := range h.subs {
+		if !sub.matches(l) {
+			continue
+		}
+		select {
+		case sub.events <- ev:
+		default:
+			h.remove(sub)
+		}
+	}
+}
+
+// Subscribe registers a subscriber for listings accepted by filter, which may
+// be nil to receive everything. When lastEventID is non-zero the matching
+// backlog events after it are returned so nothing published in between is
+// missed; an ID from before a restart replays the whole backlog.
+func (h *Hub) Subscribe(lastEventID uint64, filter func(model.Listing) bool) (*Subscription, []Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sub := &Subscription{
+		hub:    h,
+		events: make(chan Event, h.bufferSize),
+		filter: filter,
+	}
+	h.subs[sub] = struct{}{}
+
+	if lastEventID == 0 {
+		return sub, nil
+	}
+	if lastEventID > h.lastID {
+		lastEventID = 0
+	}
+
+	var missed []Event
+	for _, ev := range h.backlog {
+		if ev.ID > lastEventID && sub.matches(ev.Listing) {
+			missed = append(missed, ev)
+		}
+	}
+	return sub, missed
+}
+
+// Subscribers returns the number of connected subscribers
+func (h *Hub) Subscribers() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subs)
+}
+
+// remove drops sub and closes its channel. The caller must hold h.mu.
+func (h *Hub) remove(sub *Subscription) {
+	if _, ok := h.subs[sub]; !ok {
+		return
+	}
+	delete(h.subs, sub)
+	close(sub.events)
+}
+
+// Subscription receives the events published after it was created
+type Subscription struct {
+	hub    *Hub
+	events chan Event
+	filter func(model.Listing) bool
+}
+
+// Events returns the channel of matching events. It is closed when the
+// subscription is closed or falls too far behind.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Close unsubscribes. It is safe to call more than once.
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.remove(s)
+}
+
+func (s *Subscription) matches(l model.Listing) bool {
+	return s.filter == nil || s.filter(l)
+}
@@ -0,0 +1,84 @@
+package stream_test
+
+import (
+	"public-api/model"
+	"public-api/stream"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ids(events []stream.Event) []uint64 {
+	out := make([]uint64, len(events))
+	for i, ev := range events {
+		out[i] = ev.ID
+	}
+	return out
+}
+
+func TestHubDeliversMatchingEvents(t *testing.T) {
+	hub := stream.NewHub(10, 10)
+
+	all, _ := hub.Subscribe(0, nil)
+	rentOnly, _ := hub.Subscribe(0, func(l model.Listing) bool { return l.ListingType == "rent" })
+	defer all.Close()
+	defer rentOnly.Close()
+
+	hub.Publish(model.Listing{ID: 1, ListingType: "sale"})
+	hub.Publish(model.Listing{ID: 2, ListingType: "rent"})
+
+	assert.Equal(t, stream.Event{ID: 1, Listing: model.Listing{ID: 1, ListingType: "sale"}}, <-all.Events())
+	assert.Equal(t, uint64(2), (<-all.Events()).ID)
+	assert.Equal(t, int64(2), (<-rentOnly.Events()).Listing.ID)
+	assert.Empty(t, rentOnly.Events())
+}
+
+func TestHubReplaysBacklog(t *testing.T) {
+	hub := stream.NewHub(3, 10)
+	for i := int64(1); i <= 5; i++ {
+		hub.Publish(model.Listing{ID: i, UserID: i % 2})
+	}
+
+	tests := []struct {
+		name        string
+		lastEventID uint64
+		filter      func(model.Listing) bool
+		wantIDs     []uint64
+	}{
+		{name: "fresh subscriber gets nothing", lastEventID: 0, wantIDs: []uint64{}},
+		{name: "resume after 3", lastEventID: 3, wantIDs: []uint64{4, 5}},
+		{name: "resume before backlog", lastEventID: 1, wantIDs: []uint64{3, 4, 5}},
+		{name: "up to date", lastEventID: 5, wantIDs: []uint64{}},
+		{name: "id from before a restart", lastEventID: 99, wantIDs: []uint64{3, 4, 5}},
+		{name: "filtered", lastEventID: 2, filter: func(l model.Listing) bool { return l.UserID == 1 }, wantIDs: []uint64{3, 5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, missed := hub.Subscribe(tt.lastEventID, tt.filter)
+			defer sub.Close()
+			assert.Equal(t, tt.wantIDs, ids(missed))
+		})
+	}
+}
+
+func TestHubDisconnectsSlowSubscribers(t *testing.T) {
+	hub := stream.NewHub(10, 2)
+	slow, _ := hub.Subscribe(0, nil)
+	require.Equal(t, 1, hub.Subscribers())
+
+	for i := int64(1); i <= 3; i++ {
+		hub.Publish(model.Listing{ID: i})
+	}
+
+	// The buffered events are still readable, then the channel is closed
+	assert.Equal(t, uint64(1), (<-slow.Events()).ID)
+	assert.Equal(t, uint64(2), (<-slow.Events()).ID)
+	_, open := <-slow.Events()
+	assert.False(t, open)
+	assert.Equal(t, 0, hub.Subscribers())
+
+	// Closing again is harmless
+	slow.Close()
+}
@@ -0,0 +1,442 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"public-api/model"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Headers sent with every delivery
+const (
+	HeaderEventID   = "X-Webhook-ID"
+	HeaderEventType = "X-Webhook-Event"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// Config tunes delivery. AllowPrivateTargets lets webhooks reach loopback,
+// link-local and private addresses, for local development.
+type Config struct {
+	Workers        int
+	QueueSize      int
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Timeout        time.Duration
+	LogSize        int
+	DeadLetterSize int
+
+	AllowPrivateTargets bool
+}
+
+// DefaultConfig returns the delivery settings used when none are configured
+func DefaultConfig() Config {
+	return Config{
+		Workers:        4,
+		QueueSize:      1000,
+		MaxAttempts:    5,
+		InitialBackoff: time.Second,
+		MaxBackoff:     5 * time.Minute,
+		Timeout:        10 * time.Second,
+		LogSize:        100,
+		DeadLetterSize: 1000,
+	}
+}
+
+// Dispatcher keeps webhook subscriptions and delivers events to them from
+// background workers, retrying failures with exponential backoff and moving
+// events that exhaust their attempts to a dead-letter list. State lives in
+// memory and is lost on restart.
+type Dispatcher struct {
+	mu     sync.Mutex
+	cfg    Config
+	client *http.Client
+	now    func() time.Time
+	queue  chan job
+
+	nextHookID int64
+	hooks      map[int64]*hook
+	nextDeadID int64
+	dead       []model.WebhookDeadLetter
+}
+
+// hook is a subscription with its signing secret and recent deliveries
+type hook struct {
+	model.Webhook
+	secret     string
+	deliveries []model.WebhookDelivery
+}
+
+// job is one delivery attempt of an event to a webhook
+type job struct {
+	hookID  int64
+	event   model.WebhookEvent
+	attempt int
+}
+
+// NewDispatcher creates a dispatcher. Call Start to begin delivering.
+func NewDispatcher(cfg Config) *Dispatcher {
+	if cfg.Workers < 1 {
+		cfg.Workers = 1
+	}
+	if cfg.MaxAttempts < 1 {
+		cfg.MaxAttempts = 1
+	}
+	return &Dispatcher{
+		cfg:    cfg,
+		client: newClient(cfg.Timeout, cfg.AllowPrivateTargets),
+		now:    time.Now,
+		queue:  make(chan job, cfg.QueueSize),
+		hooks:  make(map[int64]*hook),
+	}
+}
+
+// Start runs the delivery workers until ctx is done
+func (d *Dispatcher) Start(ctx context.Context) {
+	for i := 0; i < d.cfg.Workers; i++ {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case j := <-d.queue:
+					d.deliver(ctx, j)
+				}
+			}
+		}()
+	}
+}
+
+// Create subscribes url to events. When secret is empty a random one is
+// generated. The returned webhook is the only one to include the secret.
+func (d *Dispatcher) Create(url string, events []model.WebhookEventType, secret string) (*model.Webhook, error) {
+	if err := model.ValidateWebhookURL(url); err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		return nil, fmt.Errorf("%w: events must not be empty", model.ErrInvalidInput)
+	}
+	for _, e := range events {
+		if !e.IsValid() {
+			return nil, fmt.Errorf("%w: unknown event %q", model.ErrInvalidInput, e)
+		}
+	}
+	if secret == "" {
+		secret = randomHex(32)
+	} else if len(secret) < model.MinWebhookSecretLength {
+		return nil, fmt.Errorf("%w: secret must be at least %d characters", model.ErrInvalidInput, model.MinWebhookSecretLength)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.nextHookID++
+	h := &hook{
+		Webhook: model.Webhook{
+			ID:        d.nextHookID,
+			URL:       url,
+			Events:    dedupe(events),
+			CreatedAt: d.now().Unix(),
+		},
+		secret: secret,
+	}
+	d.hooks[h.ID] = h
+
+	created := h.view()
+	created.Secret = secret
+	return &created, nil
+}
+
+// List returns every webhook ordered by ID
+func (d *Dispatcher) List() []model.Webhook {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	out := make([]model.Webhook, 0, len(d.hooks))
+	for _, h := range d.hooks {
+		out = append(out, h.view())
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// Get returns a webhook by ID
+func (d *Dispatcher) Get(id int64) (*model.Webhook, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	h, ok := d.hooks[id]
+	if !ok {
+		return nil, fmt.Errorf("webhook %d: %w", id, model.ErrNotFound)
+	}
+	w := h.view()
+	return &w, nil
+}
+
+// Delete unsubscribes a webhook. Pending retries to it are dropped.
+func (d *Dispatcher) Delete(id int64) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.hooks[id]; !ok {
+		return fmt.Errorf("webhook %d: %w", id, model.ErrNotFound)
+	}
+	delete(d.hooks, id)
+	return nil
+}
+
+// Deliveries returns the most recent delivery attempts to a webhook, newest
+// first
+func (d *Dispatcher) Deliveries(id int64) ([]model.WebhookDelivery, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	h, ok := d.hooks[id]
+	if !ok {
+		return nil, fmt.Errorf("webhook %d: %w", id, model.ErrNotFound)
+	}
+	out := make([]model.WebhookDelivery, len(h.deliveries))
+	for i, del := range h.deliveries {
+		out[len(out)-1-i] = del
+	}
+	return out, nil
+}
+
+// DeadLetters returns the events that exhausted their attempts, oldest first
+func (d *Dispatcher) DeadLetters() []model.WebhookDeadLetter {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]model.WebhookDeadLetter{}, d.dead...)
+}
+
+// Redeliver removes a dead letter and queues its event for a fresh round of
+// attempts. It fails with model.ErrUnprocessable when the webhook has since
+// been deleted.
+func (d *Dispatcher) Redeliver(id int64) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for i, dl := range d.dead {
+		if dl.ID != id {
+			continue
+		}
+		if _, ok := d.hooks[dl.WebhookID]; !ok {
+			return fmt.Errorf("%w: webhook %d no longer exists", model.ErrUnprocessable, dl.WebhookID)
+		}
+		d.dead = append(d.dead[:i], d.dead[i+1:]...)
+		d.enqueue(job{hookID: dl.WebhookID, event: dl.Event, attempt: 1})
+		return nil
+	}
+	return fmt.Errorf("dead letter %d: %w", id, model.ErrNotFound)
+}
+
+// Publish delivers a listing.created event for l. eventID is sent to
+// receivers so they can drop an event delivered more than once.
+func (d *Dispatcher) Publish(eventID string, l model.Listing) {
+	d.emit(eventID, model.WebhookListingCreated, l)
+}
+
+// PublishUserCreation delivers a user.created event for u
+func (d *Dispatcher) PublishUserCreation(eventID string, u model.User) {
+	d.emit(eventID, model.WebhookUserCreated, u)
+}
+
+// emit queues an event for every webhook subscribed to its type
+func (d *Dispatcher) emit(id string, t model.WebhookEventType, data interface{}) {
+	ev := model.WebhookEvent{ID: id, Type: t, CreatedAt: d.now().Unix(), Data: data}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, h := range d.hooks {
+		if h.subscribed(t) {
+			d.enqueue(job{hookID: h.ID, event: ev, attempt: 1})
+		}
+	}
+}
+
+// enqueue queues j without blocking. When the queue is full the event is
+// dead-lettered rather than holding up the request that emitted it. The
+// caller must hold d.mu.
+func (d *Dispatcher) enqueue(j job) {
+	select {
+	case d.queue <- j:
+	default:
+		d.bury(j, "delivery queue full")
+	}
+}
+
+// deliver makes one attempt and schedules the next one on failure
+func (d *Dispatcher) deliver(ctx context.Context, j job) {
+	d.mu.Lock()
+	h, ok := d.hooks[j.hookID]
+	var url, secret string
+	if ok {
+		url, secret = h.URL, h.secret
+	}
+	d.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	start := d.now()
+	status, err := d.post(ctx, url, secret, j.event)
+	record := model.WebhookDelivery{
+		EventID:     j.event.ID,
+		EventType:   j.event.Type,
+		Attempt:     j.attempt,
+		Succeeded:   err == nil,
+		StatusCode:  status,
+		DurationMs:  d.now().Sub(start).Milliseconds(),
+		AttemptedAt: start.Unix(),
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if h, ok := d.hooks[j.hookID]; ok {
+		h.record(record, d.cfg.LogSize)
+	}
+	if err == nil {
+		return
+	}
+	if j.attempt >= d.cfg.MaxAttempts {
+		log.Printf("webhook %d: giving up on event %s after %d attempts: %v", j.hookID, j.event.ID, j.attempt, err)
+		d.bury(j, err.Error())
+		return
+	}
+
+	next := job{hookID: j.hookID, event: j.event, attempt: j.attempt + 1}
+	time.AfterFunc(d.backoff(j.attempt), func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		d.enqueue(next)
+	})
+}
+
+// post sends the signed event and reports the response status. Any non-2xx
+// status is an error.
+func (d *Dispatcher) post(ctx context.Context, url, secret string, ev model.WebhookEvent) (int, error) {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEventID, ev.ID)
+	req.Header.Set(HeaderEventType, string(ev.Type))
+	req.Header.Set(HeaderSignature, Sign(secret, d.now(), body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to call webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff returns the delay before the attempt after attempt
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	delay := d.cfg.InitialBackoff
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if d.cfg.MaxBackoff > 0 && delay >= d.cfg.MaxBackoff {
+			return d.cfg.MaxBackoff
+		}
+	}
+	return delay
+}
+
+// bury moves a job to the dead-letter list, dropping the oldest dead letters
+// beyond DeadLetterSize. The caller must hold d.mu.
+func (d *Dispatcher) bury(j job, reason string) {
+	d.nextDeadID++
+	d.dead = append(d.dead, model.WebhookDeadLetter{
+		ID:        d.nextDeadID,
+		WebhookID: j.hookID,
+		Event:     j.event,
+		Attempts:  j.attempt,
+		LastError: reason,
+		FailedAt:  d.now().Unix(),
+	})
+	if size := d.cfg.DeadLetterSize; size > 0 && len(d.dead) > size {
+		d.dead = append(d.dead[:0], d.dead[len(d.dead)-size:]...)
+	}
+}
+
+// Sign returns the X-Webhook-Signature header value for body sent at t:
+// "t=<unix seconds>,v1=<hex HMAC-SHA256 of "<unix seconds>.<body>">".
+// Receivers recompute it with their secret and should reject stale
+// timestamps to prevent replays.
+func Sign(secret string, t time.Time, body []byte) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts + "."))
+	mac.Write(body)
+	return "t=" + ts + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// view returns the webhook without its secret
+func (h *hook) view() model.Webhook {
+	w := h.Webhook
+	w.Events = append([]model.WebhookEventType{}, h.Events...)
+	return w
+}
+
+func (h *hook) subscribed(t model.WebhookEventType) bool {
+	for _, e := range h.Events {
+		if e == t {
+			return true
+		}
+	}
+	return false
+}
+
+// record appends a delivery, keeping only the last size entries
+func (h *hook) record(del model.WebhookDelivery, size int) {
+	h.deliveries = append(h.deliveries, del)
+	if size > 0 && len(h.deliveries) > size {
+		h.deliveries = append(h.deliveries[:0], h.deliveries[len(h.deliveries)-size:]...)
+	}
+}
+
+func dedupe(events []model.WebhookEventType) []model.WebhookEventType {
+	seen := make(map[model.WebhookEventType]bool, len(events))
+	out := make([]model.WebhookEventType, 0, len(events))
+	for _, e := range events {
+		if !seen[e] {
+			seen[e] = true
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic("webhook: failed to read random bytes: " + err.Error())
+	}
+	return hex.EncodeToString(b)
+}
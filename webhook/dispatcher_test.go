@@ -0,0 +1,294 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"public-api/model"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// receiver is a webhook endpoint failing the first failures requests
+type receiver struct {
+	mu       sync.Mutex
+	failures int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.requests = append(rc.requests, r)
+	rc.bodies = append(rc.bodies, body)
+	if len(rc.requests) <= rc.failures {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (rc *receiver) count() int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return len(rc.requests)
+}
+
+func startDispatcher(t *testing.T, maxAttempts int) *Dispatcher {
+	cfg := DefaultConfig()
+	cfg.MaxAttempts = maxAttempts
+	cfg.InitialBackoff = time.Millisecond
+	cfg.MaxBackoff = 5 * time.Millisecond
+	cfg.AllowPrivateTargets = true // receivers listen on loopback
+
+	d := NewDispatcher(cfg)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	d.Start(ctx)
+	return d
+}
+
+func startReceiver(t *testing.T, failures int) (*receiver, string) {
+	rc := &receiver{failures: failures}
+	srv := httptest.NewServer(rc)
+	t.Cleanup(srv.Close)
+	return rc, srv.URL
+}
+
+func TestDispatcherDeliversSignedEvents(t *testing.T) {
+	d := startDispatcher(t, 3)
+	rc, url := startReceiver(t, 0)
+	_, otherURL := startReceiver(t, 0)
+
+	w, err := d.Create(url, []model.WebhookEventType{model.WebhookListingCreated, model.WebhookListingCreated}, "0123456789abcdef")
+	require.NoError(t, err)
+	assert.Equal(t, "0123456789abcdef", w.Secret)
+	assert.Equal(t, []model.WebhookEventType{model.WebhookListingCreated}, w.Events)
+	_, err = d.Create(otherURL, []model.WebhookEventType{model.WebhookUserCreated}, "")
+	require.NoError(t, err)
+
+	d.PublishUserCreation("evt_1", model.User{ID: 1})
+	d.Publish("evt_2", model.Listing{ID: 5, UserID: 1, ListingType: "rent"})
+	require.Eventually(t, func() bool { return rc.count() == 1 }, time.Second, time.Millisecond)
+
+	rc.mu.Lock()
+	req, body := rc.requests[0], rc.bodies[0]
+	rc.mu.Unlock()
+
+	var ev model.WebhookEvent
+	require.NoError(t, json.Unmarshal(body, &ev))
+	assert.Equal(t, model.WebhookListingCreated, ev.Type)
+	assert.Equal(t, "evt_2", ev.ID)
+	assert.Equal(t, ev.ID, req.Header.Get(HeaderEventID))
+	assert.Equal(t, "listing.created", req.Header.Get(HeaderEventType))
+	assert.Equal(t, float64(5), ev.Data.(map[string]interface{})["id"])
+
+	signature := req.Header.Get(HeaderSignature)
+	rawTS, _, _ := strings.Cut(strings.TrimPrefix(signature, "t="), ",")
+	ts, err := strconv.ParseInt(rawTS, 10, 64)
+	require.NoError(t, err)
+	assert.Equal(t, Sign("0123456789abcdef", time.Unix(ts, 0), body), signature)
+
+	stored, err := d.Get(w.ID)
+	require.NoError(t, err)
+	assert.Empty(t, stored.Secret)
+
+	require.Eventually(t, func() bool {
+		log, _ := d.Deliveries(w.ID)
+		return len(log) == 1
+	}, time.Second, time.Millisecond)
+	log, _ := d.Deliveries(w.ID)
+	assert.True(t, log[0].Succeeded)
+	assert.Equal(t, http.StatusNoContent, log[0].StatusCode)
+	assert.Equal(t, 1, log[0].Attempt)
+}
+
+func TestDispatcherRetriesThenDeadLetters(t *testing.T) {
+	d := startDispatcher(t, 3)
+
+	flaky, flakyURL := startReceiver(t, 2)
+	recovered, err := d.Create(flakyURL, []model.WebhookEventType{model.WebhookUserCreated}, "")
+	require.NoError(t, err)
+
+	down, downURL := startReceiver(t, 100)
+	failed, err := d.Create(downURL, []model.WebhookEventType{model.WebhookUserCreated}, "")
+	require.NoError(t, err)
+
+	d.PublishUserCreation("evt_1", model.User{ID: 1})
+
+	require.Eventually(t, func() bool {
+		log, _ := d.Deliveries(recovered.ID)
+		return len(d.DeadLetters()) == 1 && len(log) == 3
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, 3, flaky.count())
+	assert.Equal(t, 3, down.count())
+
+	// The same event ID is used for every attempt
+	flaky.mu.Lock()
+	assert.Equal(t, flaky.requests[0].Header.Get(HeaderEventID), flaky.requests[2].Header.Get(HeaderEventID))
+	flaky.mu.Unlock()
+
+	log, err := d.Deliveries(recovered.ID)
+	require.NoError(t, err)
+	require.Len(t, log, 3)
+	assert.True(t, log[0].Succeeded)
+	assert.Equal(t, 3, log[0].Attempt)
+	assert.False(t, log[2].Succeeded)
+	assert.Equal(t, http.StatusServiceUnavailable, log[2].StatusCode)
+
+	dl := d.DeadLetters()[0]
+	assert.Equal(t, failed.ID, dl.WebhookID)
+	assert.Equal(t, 3, dl.Attempts)
+	assert.Contains(t, dl.LastError, "status 503")
+
+	// Redelivering starts a fresh round of attempts
+	require.NoError(t, d.Redeliver(dl.ID))
+	assert.Empty(t, d.DeadLetters())
+	require.Eventually(t, func() bool { return len(d.DeadLetters()) == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, 6, down.count())
+
+	assert.ErrorIs(t, d.Redeliver(dl.ID), model.ErrNotFound)
+	require.NoError(t, d.Delete(failed.ID))
+	assert.ErrorIs(t, d.Redeliver(d.DeadLetters()[0].ID), model.ErrUnprocessable)
+}
+
+func TestDispatcherRefusesPrivateTargets(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.MaxAttempts = 1
+	d := NewDispatcher(cfg)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	d.Start(ctx)
+
+	rc, url := startReceiver(t, 0)
+	w, err := d.Create(url, []model.WebhookEventType{model.WebhookUserCreated}, "")
+	require.NoError(t, err)
+
+	d.PublishUserCreation("evt_1", model.User{ID: 1})
+
+	require.Eventually(t, func() bool { return len(d.DeadLetters()) == 1 }, time.Second, time.Millisecond)
+	assert.Zero(t, rc.count())
+	log, err := d.Deliveries(w.ID)
+	require.NoError(t, err)
+	require.Len(t, log, 1)
+	assert.Contains(t, log[0].Error, errForbiddenTarget.Error())
+}
+
+func TestDispatcherDoesNotFollowRedirects(t *testing.T) {
+	d := startDispatcher(t, 1)
+	rc, target := startReceiver(t, 0)
+	redirect := httptest.NewServer(http.RedirectHandler(target, http.StatusTemporaryRedirect))
+	t.Cleanup(redirect.Close)
+
+	w, err := d.Create(redirect.URL, []model.WebhookEventType{model.WebhookUserCreated}, "")
+	require.NoError(t, err)
+
+	d.PublishUserCreation("evt_1", model.User{ID: 1})
+
+	require.Eventually(t, func() bool { return len(d.DeadLetters()) == 1 }, time.Second, time.Millisecond)
+	assert.Zero(t, rc.count())
+	log, err := d.Deliveries(w.ID)
+	require.NoError(t, err)
+	require.Len(t, log, 1)
+	assert.Equal(t, http.StatusTemporaryRedirect, log[0].StatusCode)
+}
+
+func TestIsPublic(t *testing.T) {
+	for _, addr := range []string{"127.0.0.1", "::1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254", "fe80::1", "fd00::1", "0.0.0.0",
+		"0.1.2.3", "100.64.0.1", "100.127.255.254", "198.18.0.1", "198.19.255.254", "64:ff9b::a00:1", "64:ff9b::7f00:1", "64:ff9b:1::1"} {
+		assert.Falsef(t, isPublic(net.ParseIP(addr)), "%s is not public", addr)
+	}
+	for _, addr := range []string{"93.184.216.34", "2606:2800:220:1::", "100.128.0.1", "198.20.0.1", "64:ff9b::5db8:d822"} {
+		assert.Truef(t, isPublic(net.ParseIP(addr)), "%s is public", addr)
+	}
+}
+
+func TestDispatcherManagesWebhooks(t *testing.T) {
+	d := NewDispatcher(DefaultConfig())
+
+	_, err := d.Create("ftp://example.com", []model.WebhookEventType{model.WebhookUserCreated}, "")
+	assert.ErrorIs(t, err, model.ErrInvalidInput)
+	_, err = d.Create("https://example.com", []model.WebhookEventType{"user.deleted"}, "")
+	assert.ErrorIs(t, err, model.ErrInvalidInput)
+	_, err = d.Create("https://example.com", nil, "")
+	assert.ErrorIs(t, err, model.ErrInvalidInput)
+	_, err = d.Create("https://example.com", []model.WebhookEventType{model.WebhookUserCreated}, "short")
+	assert.ErrorIs(t, err, model.ErrInvalidInput)
+
+	a, err := d.Create("https://a.example.com/hook", []model.WebhookEventType{model.WebhookUserCreated}, "")
+	require.NoError(t, err)
+	assert.Len(t, a.Secret, 64)
+	b, err := d.Create("https://b.example.com/hook", []model.WebhookEventType{model.WebhookListingCreated}, "")
+	require.NoError(t, err)
+
+	list := d.List()
+	require.Len(t, list, 2)
+	assert.Equal(t, []int64{a.ID, b.ID}, []int64{list[0].ID, list[1].ID})
+	assert.Empty(t, list[0].Secret)
+
+	require.NoError(t, d.Delete(a.ID))
+	assert.ErrorIs(t, d.Delete(a.ID), model.ErrNotFound)
+	_, err = d.Get(a.ID)
+	assert.ErrorIs(t, err, model.ErrNotFound)
+	_, err = d.Deliveries(a.ID)
+	assert.ErrorIs(t, err, model.ErrNotFound)
+	assert.Len(t, d.List(), 1)
+}
+
+func TestDispatcherDeadLettersWhenQueueIsFull(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.QueueSize = 1
+	d := NewDispatcher(cfg) // not started, so nothing drains the queue
+
+	_, err := d.Create("https://example.com", []model.WebhookEventType{model.WebhookUserCreated}, "")
+	require.NoError(t, err)
+
+	d.PublishUserCreation("evt_1", model.User{ID: 1})
+	d.PublishUserCreation("evt_2", model.User{ID: 2})
+
+	dead := d.DeadLetters()
+	require.Len(t, dead, 1)
+	assert.Equal(t, "delivery queue full", dead[0].LastError)
+	assert.Equal(t, model.User{ID: 2}, dead[0].Event.Data)
+}
+
+func TestDispatcherDropsOldestDeadLetters(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.QueueSize = 0
+	cfg.DeadLetterSize = 2
+	d := NewDispatcher(cfg) // no queue, so every event is dead-lettered
+
+	_, err := d.Create("https://example.com", []model.WebhookEventType{model.WebhookUserCreated}, "")
+	require.NoError(t, err)
+
+	for id := int64(1); id <= 3; id++ {
+		d.PublishUserCreation("evt_"+strconv.FormatInt(id, 10), model.User{ID: id})
+	}
+
+	dead := d.DeadLetters()
+	require.Len(t, dead, 2)
+	assert.Equal(t, model.User{ID: 2}, dead[0].Event.Data)
+	assert.Equal(t, model.User{ID: 3}, dead[1].Event.Data)
+}
+
+func TestBackoff(t *testing.T) {
+	d := NewDispatcher(Config{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second})
+
+	assert.Equal(t, time.Second, d.backoff(1))
+	assert.Equal(t, 2*time.Second, d.backoff(2))
+	assert.Equal(t, 4*time.Second, d.backoff(3))
+	assert.Equal(t, 5*time.Second, d.backoff(4))
+	assert.Equal(t, 5*time.Second, d.backoff(40))
+}
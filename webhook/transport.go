@@ -0,0 +1,89 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// errForbiddenTarget fails deliveries to addresses inside the network
+var errForbiddenTarget = errors.New("webhook target address is not public")
+
+// newClient returns the HTTP client deliveries are made with. Redirects are
+// not followed, so a 3xx fails the attempt. Unless allowPrivate is set,
+// connections to loopback, link-local, private, unspecified and other
+// reserved addresses are refused after DNS resolution, so webhook URLs cannot
+// reach internal services, whatever their host name resolves to.
+func newClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	if !allowPrivate {
+		dialer.Control = refusePrivate
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would be dialed instead of the target, defeating the check
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// refusePrivate is a net.Dialer Control function rejecting non-public
+// addresses
+func refusePrivate(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !isPublic(ip) {
+		return fmt.Errorf("%w: %s", errForbiddenTarget, host)
+	}
+	return nil
+}
+
+// reservedNets are the non-public ranges net.IP has no predicate for
+var reservedNets = []*net.IPNet{
+	mustParseCIDR("0.0.0.0/8"),      // "this network"
+	mustParseCIDR("100.64.0.0/10"),  // carrier-grade NAT
+	mustParseCIDR("198.18.0.0/15"),  // benchmarking
+	mustParseCIDR("64:ff9b:1::/48"), // local-use NAT64
+}
+
+// nat64 is the well-known NAT64 prefix, which embeds an IPv4 address in
+// its last four bytes
+var nat64 = mustParseCIDR("64:ff9b::/96")
+
+func isPublic(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, n := range reservedNets {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	if nat64.Contains(ip) {
+		return isPublic(net.IP(ip[net.IPv6len-net.IPv4len:]))
+	}
+	return true
+}
+
+func mustParseCIDR(s string) *net.IPNet {
+	_, n, err := net.ParseCIDR(s)
+	if err != nil {
+		panic(err)
+	}
+	return n
+}